@@ -45,6 +45,8 @@ type IndexParams struct {
 }
 
 func main() {
+	config.Load()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
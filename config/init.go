@@ -15,7 +15,9 @@ type Config struct {
 		CORS struct {
 			AllowedOrigins []string `yaml:"allowed_origins"`
 		} `yaml:"cors"`
-		LogLevel string `yaml:"log_level"`
+		// 可信的反向代理 IP 或网段，仅信任来自这些地址的 X-Forwarded-For，为空时不信任任何代理
+		TrustedProxies []string `yaml:"trusted_proxies"`
+		LogLevel       string   `yaml:"log_level"`
 	}
 	Client struct {
 		BaseURL string `yaml:"base_url"`
@@ -31,6 +33,12 @@ type Config struct {
 	} `yaml:"redis"`
 	JWT struct {
 		SecretKey string `yaml:"secret_key"`
+
+		// 访问令牌有效期(分钟)，未配置时为 15 分钟
+		AccessTokenMinutes int `yaml:"access_token_minutes"`
+
+		// 刷新令牌有效期(天)，未配置时为 30 天
+		RefreshTokenDays int `yaml:"refresh_token_days"`
 	} `yaml:"jwt"`
 	MCP struct {
 		Host string `yaml:"host"`
@@ -51,6 +59,16 @@ type Config struct {
 		BaseURL string `yaml:"base_url"`
 		APIKey  string `yaml:"api_key"`
 	} `yaml:"model"`
+	LLM struct {
+		// OpenAI 兼容的模型服务，未配置时使用 model 中的 base_url 和 api_key 作为默认服务
+		Providers []LLMProviderConfig `yaml:"providers"`
+
+		// 模型别名，任务配置中通过别名引用模型
+		Models map[string]LLMModelConfig `yaml:"models"`
+
+		// 各任务使用的模型别名，调用失败时按顺序回退，未配置的任务使用内置默认模型
+		Tasks map[string][]string `yaml:"tasks"`
+	} `yaml:"llm"`
 	Milvus struct {
 		Endpoint string `yaml:"endpoint"`
 		APIKey   string `yaml:"api_key"`
@@ -63,6 +81,14 @@ type Config struct {
 		Password  string `yaml:"password"`
 		FromEmail string `yaml:"from_email"`
 	} `yaml:"email"`
+	RateLimit struct {
+		// 按路由组配置的限流规则，未配置的路由组不限流
+		Groups map[string]RateLimitConfig `yaml:"groups"`
+	} `yaml:"rate_limit"`
+	Report struct {
+		// 生成 PDF 报告使用的中文 TTF 字体，未配置时仅生成 HTML 报告
+		PDFFontPath string `yaml:"pdf_font_path"`
+	} `yaml:"report"`
 }
 
 type DBConfig struct {
@@ -73,7 +99,38 @@ type DBConfig struct {
 	DBName   string `yaml:"db_name"`
 }
 
-func init() {
+type LLMProviderConfig struct {
+	Name    string `yaml:"name"`
+	BaseURL string `yaml:"base_url"`
+	APIKey  string `yaml:"api_key"`
+
+	// 请求超时时间(秒)，未配置时为 300s 以支持流式输出
+	Timeout int `yaml:"timeout"`
+}
+
+type LLMModelConfig struct {
+	Provider string `yaml:"provider"`
+	Model    string `yaml:"model"`
+
+	// 输入/输出单价(元/百万 tokens)，用于用量统计中的费用计算
+	InputPrice  float64 `yaml:"input_price"`
+	OutputPrice float64 `yaml:"output_price"`
+}
+
+// RateLimitConfig 单个用户在路由组内的令牌桶限流及每日配额
+type RateLimitConfig struct {
+	// 每秒补充的令牌数，与 burst 均大于 0 时启用令牌桶限流
+	Rate float64 `yaml:"rate"`
+
+	// 令牌桶容量，即允许的突发请求数
+	Burst int `yaml:"burst"`
+
+	// 每日请求次数上限，按 UTC 日期统计，0 表示不限制
+	DailyQuota int `yaml:"daily_quota"`
+}
+
+// Load 读取并解析 config.yaml，需在使用配置前调用
+func Load() {
 	data, err := os.ReadFile("config.yaml")
 	if err != nil {
 		panic(fmt.Sprintf("Failed to read config: %v", err))
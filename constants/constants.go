@@ -6,4 +6,33 @@ const (
 
 	// 邮箱验证码的 Redis key
 	KeyVerificationCode = "user:%s:verification_code"
+
+	// 血糖提醒规则冷却期的 Redis key，参数为用户邮箱、规则类型和规则 ID
+	KeyGlucoseAlertCooldown = "user:%s:glucose_alert:%s:%d:cooldown"
+
+	// 验证码错误次数的 Redis key
+	KeyVerificationCodeFailures = "user:%s:verification_code_failures"
+
+	// 登录失败次数及登录锁定的 Redis key，参数为用户邮箱
+	KeyLoginFailures = "user:%s:login_failures"
+	KeyLoginLock     = "user:%s:login_lock"
+
+	// 登录失败次数及登录锁定的 Redis key，参数为客户端 IP
+	KeyIPLoginFailures = "ip:%s:login_failures"
+	KeyIPLoginLock     = "ip:%s:login_lock"
+
+	// 已吊销访问令牌的 Redis key，参数为令牌 ID(jti)
+	KeyRevokedToken = "jwt:revoked:%s"
+
+	// 用户未过期访问令牌 ID 的 Redis key，按过期时间排序，用于退出所有设备
+	KeyUserTokens = "user:%s:tokens"
+
+	// 限流令牌桶的 Redis key，参数为用户邮箱和路由组
+	KeyRateLimitBucket = "user:%s:rate_limit:%s"
+
+	// 每日配额计数的 Redis key，参数为用户邮箱、路由组和日期
+	KeyRateLimitQuota = "user:%s:quota:%s:%s"
 )
+
+// 消息消费失败后的最大重试次数，超过后不再投递
+const MQMaxReconsumeTimes = 5
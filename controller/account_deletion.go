@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/request"
+	"diabetes-agent-server/response"
+	accountdeletion "diabetes-agent-server/service/account-deletion"
+	"diabetes-agent-server/service/auth"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAccountDeletion 申请注销账号，冷静期结束后删除账号及所有数据，冷静期内可撤销
+func CreateAccountDeletion(c *gin.Context) {
+	var req request.CreateAccountDeletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	email := c.GetString("email")
+	job, err := accountdeletion.RequestDeletion(c.Request.Context(), email, req.Password)
+	if err != nil {
+		if errors.Is(err, auth.ErrIncorrectPassword) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+				Msg: auth.ErrIncorrectPassword.Error(),
+			})
+			return
+		}
+
+		slog.Error(ErrCreateAccountDeletion.Error(),
+			"email", email,
+			"err", err,
+		)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrCreateAccountDeletion.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response.Response{
+		Data: convertAccountDeletionToResponse(*job),
+	})
+}
+
+// GetAccountDeletion 获取冷静期内的注销申请
+func GetAccountDeletion(c *gin.Context) {
+	email := c.GetString("email")
+	job, err := dao.GetPendingAccountDeletionJob(email)
+	if err != nil {
+		slog.Error(ErrGetAccountDeletion.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetAccountDeletion.Error(),
+		})
+		return
+	}
+	if job == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.Response{
+			Msg: ErrAccountDeletionNotFound.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: convertAccountDeletionToResponse(*job),
+	})
+}
+
+// CancelAccountDeletion 撤销冷静期内的注销申请，删除开始执行后不可撤销
+func CancelAccountDeletion(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 32)
+
+	email := c.GetString("email")
+	canceled, err := accountdeletion.CancelDeletion(c.Request.Context(), email, uint(id))
+	if err != nil {
+		slog.Error(ErrCancelAccountDeletion.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrCancelAccountDeletion.Error(),
+		})
+		return
+	}
+	if !canceled {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.Response{
+			Msg: ErrAccountDeletionNotFound.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{})
+}
+
+// GetAccountDeletionJobs 分页查询注销任务，可按状态过滤
+func GetAccountDeletionJobs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+
+	jobs, err := dao.GetAccountDeletionJobs(c.Query("status"), page)
+	if err != nil {
+		slog.Error(ErrGetAccountDeletion.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetAccountDeletion.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: jobs,
+	})
+}
+
+// GetAccountDeletionJob 获取注销任务及删除报告，报告中包含各类数据的删除数量和复查的残留数量
+func GetAccountDeletionJob(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 32)
+
+	job, err := dao.GetAccountDeletionJobByID(uint(id))
+	if err != nil {
+		slog.Error(ErrGetAccountDeletion.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetAccountDeletion.Error(),
+		})
+		return
+	}
+	if job == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.Response{
+			Msg: ErrAccountDeletionNotFound.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: job,
+	})
+}
+
+func convertAccountDeletionToResponse(job model.AccountDeletionJob) response.AccountDeletionResponse {
+	return response.AccountDeletionResponse{
+		ID:          job.ID,
+		CreatedAt:   job.CreatedAt,
+		Status:      job.Status,
+		ScheduledAt: job.ScheduledAt,
+	}
+}
@@ -0,0 +1,378 @@
+package controller
+
+import (
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/request"
+	"diabetes-agent-server/response"
+	"diabetes-agent-server/service/auth"
+	healthreport "diabetes-agent-server/service/health-weekly-report"
+	"diabetes-agent-server/service/knowledge-base/etl"
+	"diabetes-agent-server/service/mq"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetUsers 分页查询用户，可按邮箱关键字和角色过滤
+func GetUsers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+
+	users, err := dao.SearchUsers(c.Query("query"), c.Query("role"), page)
+	if err != nil {
+		slog.Error(ErrGetUsers.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetUsers.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: users,
+	})
+}
+
+// UpdateUserDisabled 禁用或启用用户，禁用后用户立即退出所有设备
+func UpdateUserDisabled(c *gin.Context) {
+	var req request.UpdateUserDisabledRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	email := c.Param("email")
+	if email == c.GetString("email") {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrModifySelf.Error(),
+		})
+		return
+	}
+	if !checkUserExists(c, email) {
+		return
+	}
+
+	if err := auth.SetUserDisabled(c.Request.Context(), email, req.Disabled); err != nil {
+		slog.Error(ErrUpdateUserDisabled.Error(),
+			"email", email,
+			"err", err,
+		)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrUpdateUserDisabled.Error(),
+		})
+		return
+	}
+
+	slog.Info("user disabled updated",
+		"admin_email", c.GetString("email"),
+		"email", email,
+		"disabled", req.Disabled,
+	)
+	c.JSON(http.StatusOK, response.Response{})
+}
+
+// UpdateUserRole 修改用户角色，医生和管理员角色只能由管理员设置
+func UpdateUserRole(c *gin.Context) {
+	var req request.UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	email := c.Param("email")
+	if email == c.GetString("email") {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrModifySelf.Error(),
+		})
+		return
+	}
+	if !checkUserExists(c, email) {
+		return
+	}
+
+	if err := auth.SetUserRole(c.Request.Context(), email, req.Role); err != nil {
+		slog.Error(ErrUpdateUserRole.Error(),
+			"email", email,
+			"err", err,
+		)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrUpdateUserRole.Error(),
+		})
+		return
+	}
+
+	slog.Info("user role updated",
+		"admin_email", c.GetString("email"),
+		"email", email,
+		"role", req.Role,
+	)
+	c.JSON(http.StatusOK, response.Response{})
+}
+
+// GetUserLLMUsage 按天或按月汇总指定用户的 token 用量
+func GetUserLLMUsage(c *gin.Context) {
+	getLLMUsage(c, c.Param("email"))
+}
+
+// GetFailedKnowledgeMetadata 获取向量化失败的知识文件，可按 email 过滤
+func GetFailedKnowledgeMetadata(c *gin.Context) {
+	metadata, err := dao.GetFailedKnowledgeMetadata(c.Query("email"))
+	if err != nil {
+		slog.Error(ErrGetKnowledgeMetadata.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetKnowledgeMetadata.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: metadata,
+	})
+}
+
+// ReprocessKnowledgeMetadata 重新向量化单个处理失败的知识文件
+func ReprocessKnowledgeMetadata(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 32)
+
+	metadata, err := dao.GetKnowledgeMetadataByID(uint(id))
+	if err != nil {
+		slog.Error(ErrReprocessKnowledgeMetadata.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrReprocessKnowledgeMetadata.Error(),
+		})
+		return
+	}
+	if metadata == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.Response{
+			Msg: ErrKnowledgeMetadataNotFound.Error(),
+		})
+		return
+	}
+	if metadata.Status != model.StatusProcessedFailed {
+		c.AbortWithStatusJSON(http.StatusConflict, response.Response{
+			Msg: ErrKnowledgeMetadataNotFailed.Error(),
+		})
+		return
+	}
+
+	if err := reprocessKnowledgeMetadata(c, metadata); err != nil {
+		slog.Error(ErrReprocessKnowledgeMetadata.Error(),
+			"object_name", metadata.ObjectName,
+			"err", err,
+		)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrReprocessKnowledgeMetadata.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response.Response{})
+}
+
+// ReprocessFailedKnowledgeMetadata 重新向量化所有处理失败的知识文件，可按 email 过滤
+func ReprocessFailedKnowledgeMetadata(c *gin.Context) {
+	failed, err := dao.GetFailedKnowledgeMetadata(c.Query("email"))
+	if err != nil {
+		slog.Error(ErrReprocessKnowledgeMetadata.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrReprocessKnowledgeMetadata.Error(),
+		})
+		return
+	}
+
+	count := 0
+	for _, metadata := range failed {
+		if err := reprocessKnowledgeMetadata(c, &metadata); err != nil {
+			slog.Error(ErrReprocessKnowledgeMetadata.Error(),
+				"object_name", metadata.ObjectName,
+				"err", err,
+			)
+			continue
+		}
+		count++
+	}
+
+	c.JSON(http.StatusAccepted, response.Response{
+		Data: response.ReprocessKnowledgeMetadataResponse{
+			Count: count,
+		},
+	})
+}
+
+// 向 MQ 发送向量化任务，发送成功后将文件重置为已上传状态，ETL 执行前会删除文件已写入的向量。
+// 发送失败时文件仍为 PROCESSED_FAILED，可再次重新处理；ETL 先于状态重置完成时不覆盖其结果
+func reprocessKnowledgeMetadata(c *gin.Context, metadata *model.KnowledgeMetadata) error {
+	err := mq.SendMessage(c.Request.Context(), &mq.Message{
+		Topic: mq.TopicKnowledgeBase,
+		Tag:   mq.TagETL,
+		Payload: etl.ETLMessage{
+			FileType:   metadata.FileType,
+			ObjectName: metadata.ObjectName,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return dao.ResetFailedKnowledgeMetadata(metadata.UserEmail, metadata.FileName)
+}
+
+// RegenerateHealthReport 重新生成用户指定周期的定时报告，生成后重新发送系统消息和邮件通知。
+// 任务不存在时创建任务，用于补发新用户或定时任务遗漏的报告
+func RegenerateHealthReport(c *gin.Context) {
+	var req request.RegenerateHealthReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	if !checkUserExists(c, req.Email) {
+		return
+	}
+
+	date, _ := time.Parse(time.DateOnly, req.Date)
+	start, end, err := healthreport.PeriodRange(req.PeriodType, date)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: err.Error(),
+		})
+		return
+	}
+	if end.After(time.Now()) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrReportPeriodNotEnded.Error(),
+		})
+		return
+	}
+
+	err = dao.CreateHealthReportJobs([]model.HealthReportJob{{
+		UserEmail:  req.Email,
+		PeriodType: req.PeriodType,
+		StartAt:    start,
+		EndAt:      end,
+		Status:     model.ReportJobStatusPending,
+	}})
+	if err != nil {
+		slog.Error(ErrRegenerateHealthReport.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrRegenerateHealthReport.Error(),
+		})
+		return
+	}
+
+	job, err := dao.GetHealthReportJobByPeriod(req.Email, req.PeriodType, start)
+	if err != nil || job == nil {
+		slog.Error(ErrRegenerateHealthReport.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrRegenerateHealthReport.Error(),
+		})
+		return
+	}
+
+	reset, err := dao.ResetHealthReportJob(req.Email, job.ID)
+	if err != nil {
+		slog.Error(ErrRegenerateHealthReport.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrRegenerateHealthReport.Error(),
+		})
+		return
+	}
+	if !reset {
+		c.AbortWithStatusJSON(http.StatusConflict, response.Response{
+			Msg: ErrHealthReportJobRunning.Error(),
+		})
+		return
+	}
+
+	// 投递失败时任务保持等待状态，由定时续跑重新投递
+	err = mq.SendMessage(c.Request.Context(), &mq.Message{
+		Topic: mq.TopicHealthReport,
+		Tag:   mq.TagReportJob,
+		Payload: healthreport.ReportJobMessage{
+			JobID: job.ID,
+		},
+	})
+	if err != nil {
+		slog.Error(ErrRegenerateHealthReport.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrRegenerateHealthReport.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response.Response{})
+}
+
+// BroadcastSystemMessage 向所有用户或指定角色、指定邮箱的用户发送系统消息，已禁用的用户不发送
+func BroadcastSystemMessage(c *gin.Context) {
+	var req request.BroadcastSystemMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	emails, err := dao.GetActiveUserEmails(req.Role, req.Emails)
+	if err != nil {
+		slog.Error(ErrBroadcastSystemMessage.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrBroadcastSystemMessage.Error(),
+		})
+		return
+	}
+
+	err = dao.BroadcastSystemMessage(c.Request.Context(), emails, req.Title, req.Content)
+	if err != nil {
+		slog.Error(ErrBroadcastSystemMessage.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrBroadcastSystemMessage.Error(),
+		})
+		return
+	}
+
+	slog.Info("system message broadcast",
+		"admin_email", c.GetString("email"),
+		"role", req.Role,
+		"count", len(emails),
+	)
+	c.JSON(http.StatusOK, response.Response{
+		Data: response.BroadcastSystemMessageResponse{
+			Count: len(emails),
+		},
+	})
+}
+
+func checkUserExists(c *gin.Context, email string) bool {
+	user, err := dao.GetUserByEmail(email)
+	if err != nil {
+		slog.Error(ErrGetUser.Error(),
+			"email", email,
+			"err", err,
+		)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetUser.Error(),
+		})
+		return false
+	}
+	if user == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.Response{
+			Msg: ErrUserNotFound.Error(),
+		})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/request"
+	"diabetes-agent-server/response"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetAlertRules(c *gin.Context) {
+	email := c.GetString("email")
+	rules, err := dao.GetAlertRules(email)
+	if err != nil {
+		slog.Error(ErrGetAlertRules.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetAlertRules.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: rules,
+	})
+}
+
+// CreateAlertRule 创建血糖提醒规则，用户创建规则后不再使用默认规则
+func CreateAlertRule(c *gin.Context) {
+	var req request.AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	email := c.GetString("email")
+	if req.NotifyEmail && !checkEmailVerified(c, email) {
+		return
+	}
+
+	rule := convertAlertRuleRequestToModel(req, email)
+	if err := dao.DB.Create(&rule).Error; err != nil {
+		slog.Error(ErrCreateAlertRule.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrCreateAlertRule.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.Response{})
+}
+
+func UpdateAlertRule(c *gin.Context) {
+	var req request.AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 32)
+
+	email := c.GetString("email")
+	if req.NotifyEmail && !checkEmailVerified(c, email) {
+		return
+	}
+
+	existing, err := dao.GetAlertRule(email, uint(id))
+	if err != nil {
+		slog.Error(ErrUpdateAlertRule.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrUpdateAlertRule.Error(),
+		})
+		return
+	}
+	if existing == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.Response{
+			Msg: ErrAlertRuleNotFound.Error(),
+		})
+		return
+	}
+
+	rule := convertAlertRuleRequestToModel(req, email)
+	rule.ID = existing.ID
+	if err := dao.UpdateAlertRule(rule); err != nil {
+		slog.Error(ErrUpdateAlertRule.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrUpdateAlertRule.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{})
+}
+
+func DeleteAlertRule(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 32)
+
+	email := c.GetString("email")
+	if err := dao.DeleteAlertRule(email, uint(id)); err != nil {
+		slog.Error(ErrDeleteAlertRule.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrDeleteAlertRule.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{})
+}
+
+func convertAlertRuleRequestToModel(req request.AlertRuleRequest, email string) model.AlertRule {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	return model.AlertRule{
+		UserEmail:        email,
+		Type:             req.Type,
+		Threshold:        req.Threshold,
+		ConsecutiveCount: req.ConsecutiveCount,
+		CooldownMinutes:  req.CooldownMinutes,
+		NotifyEmail:      req.NotifyEmail,
+		Enabled:          enabled,
+	}
+}
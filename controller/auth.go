@@ -1,12 +1,16 @@
 package controller
 
 import (
-	"diabetes-agent-server/middleware"
+	"diabetes-agent-server/dao"
 	"diabetes-agent-server/request"
 	"diabetes-agent-server/response"
 	"diabetes-agent-server/service/auth"
+	"errors"
+	"io"
 	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -21,20 +25,20 @@ func UserRegister(c *gin.Context) {
 		return
 	}
 
-	token, err := middleware.GenerateToken(req.Email)
+	user, err := auth.UserRegister(req)
 	if err != nil {
-		slog.Error(ErrGenerateToken.Error(), "err", err)
+		slog.Error(ErrUserRegister.Error(), "err", err)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
-			Msg: ErrGenerateToken.Error(),
+			Msg: ErrUserRegister.Error(),
 		})
 		return
 	}
 
-	user, err := auth.UserRegister(req)
+	tokens, err := auth.IssueTokens(user.Email)
 	if err != nil {
-		slog.Error(ErrUserRegister.Error(), "err", err)
+		slog.Error(ErrGenerateToken.Error(), "err", err)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
-			Msg: ErrUserRegister.Error(),
+			Msg: ErrGenerateToken.Error(),
 		})
 		return
 	}
@@ -44,7 +48,12 @@ func UserRegister(c *gin.Context) {
 			Email:                          user.Email,
 			Avatar:                         user.Avatar,
 			EnableWeeklyReportNotification: user.EnableWeeklyReportNotification,
-			Token:                          token,
+			InsufficientDataPolicy:         user.InsufficientDataPolicy,
+			EmailVerified:                  user.EmailVerified,
+			Role:                           user.Role,
+			Token:                          tokens.Token,
+			RefreshToken:                   tokens.RefreshToken,
+			ExpiresIn:                      tokens.ExpiresIn,
 		},
 	})
 }
@@ -59,19 +68,35 @@ func UserLogin(c *gin.Context) {
 		return
 	}
 
-	user, err := auth.UserLogin(req)
+	user, err := auth.UserLogin(req, c.ClientIP())
 	if err != nil {
 		slog.Error(ErrUserLogin.Error(),
 			"email", req.Email,
 			"err", err,
 		)
+
+		var lockedErr *auth.LoginLockedError
+		if errors.As(err, &lockedErr) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockedErr.RetryAfter.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, response.Response{
+				Msg: ErrTooManyLoginAttempts.Error(),
+			})
+			return
+		}
+		if errors.Is(err, auth.ErrUserDisabled) {
+			c.AbortWithStatusJSON(http.StatusForbidden, response.Response{
+				Msg: auth.ErrUserDisabled.Error(),
+			})
+			return
+		}
+
 		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
 			Msg: ErrUserLogin.Error(),
 		})
 		return
 	}
 
-	token, err := middleware.GenerateToken(user.Email)
+	tokens, err := auth.IssueTokens(user.Email)
 	if err != nil {
 		slog.Error(ErrGenerateToken.Error(),
 			"email", user.Email,
@@ -88,11 +113,99 @@ func UserLogin(c *gin.Context) {
 			Email:                          user.Email,
 			Avatar:                         user.Avatar,
 			EnableWeeklyReportNotification: user.EnableWeeklyReportNotification,
-			Token:                          token,
+			InsufficientDataPolicy:         user.InsufficientDataPolicy,
+			EmailVerified:                  user.EmailVerified,
+			Role:                           user.Role,
+			Token:                          tokens.Token,
+			RefreshToken:                   tokens.RefreshToken,
+			ExpiresIn:                      tokens.ExpiresIn,
 		},
 	})
 }
 
+// RefreshToken 使用刷新令牌换取新的访问令牌和刷新令牌
+func RefreshToken(c *gin.Context) {
+	var req request.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	tokens, err := auth.RefreshTokens(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidRefreshToken) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Response{
+				Msg: auth.ErrInvalidRefreshToken.Error(),
+			})
+			return
+		}
+		if errors.Is(err, auth.ErrUserDisabled) {
+			c.AbortWithStatusJSON(http.StatusForbidden, response.Response{
+				Msg: auth.ErrUserDisabled.Error(),
+			})
+			return
+		}
+
+		slog.Error(ErrRefreshToken.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrRefreshToken.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: tokens,
+	})
+}
+
+// Logout 退出当前设备，吊销当前访问令牌及请求中的刷新令牌
+func Logout(c *gin.Context) {
+	var req request.LogoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	email := c.GetString("email")
+	err := auth.Logout(c.Request.Context(), email, c.GetString("jti"),
+		c.GetTime("token_expires_at"), req.RefreshToken)
+	if err != nil {
+		slog.Error(ErrLogout.Error(),
+			"email", email,
+			"err", err,
+		)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrLogout.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{})
+}
+
+// LogoutAll 退出所有设备，吊销用户的所有访问令牌和刷新令牌
+func LogoutAll(c *gin.Context) {
+	email := c.GetString("email")
+	if err := auth.LogoutAll(c.Request.Context(), email); err != nil {
+		slog.Error(ErrLogout.Error(),
+			"email", email,
+			"err", err,
+		)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrLogout.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{})
+}
+
 // SendVerificationCode 发送邮箱验证码
 func SendVerificationCode(c *gin.Context) {
 	var req request.SendEmailCodeRequest
@@ -117,3 +230,119 @@ func SendVerificationCode(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response.Response{})
 }
+
+// ChangePassword 修改密码，成功后其他设备退出登录，返回当前设备的新令牌
+func ChangePassword(c *gin.Context) {
+	var req request.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	email := c.GetString("email")
+	tokens, err := auth.ChangePassword(c.Request.Context(), email, req)
+	if err != nil {
+		if errors.Is(err, auth.ErrIncorrectPassword) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+				Msg: auth.ErrIncorrectPassword.Error(),
+			})
+			return
+		}
+
+		slog.Error(ErrChangePassword.Error(),
+			"email", email,
+			"err", err,
+		)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrChangePassword.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: tokens,
+	})
+}
+
+// ResetPassword 通过邮箱验证码重置密码，成功后所有设备退出登录
+func ResetPassword(c *gin.Context) {
+	var req request.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	if err := auth.ResetPassword(c.Request.Context(), req); err != nil {
+		slog.Error(ErrResetPassword.Error(),
+			"email", req.Email,
+			"err", err,
+		)
+		if errors.Is(err, auth.ErrInvalidCode) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+				Msg: auth.ErrInvalidCode.Error(),
+			})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrResetPassword.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{})
+}
+
+// VerifyEmail 验证已注册用户的邮箱，验证码由 /user/code 发送
+func VerifyEmail(c *gin.Context) {
+	var req request.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	email := c.GetString("email")
+	if err := auth.VerifyEmail(email, req.Code); err != nil {
+		slog.Error(ErrVerifyEmail.Error(),
+			"email", email,
+			"err", err,
+		)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrVerifyEmail.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{})
+}
+
+// 检查用户邮箱是否已验证，未验证时返回 403，用于开启邮件通知前的校验
+func checkEmailVerified(c *gin.Context, email string) bool {
+	user, err := dao.GetUserByEmail(email)
+	if err != nil || user == nil {
+		slog.Error(ErrGetUser.Error(),
+			"email", email,
+			"err", err,
+		)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetUser.Error(),
+		})
+		return false
+	}
+
+	if !user.EmailVerified {
+		c.AbortWithStatusJSON(http.StatusForbidden, response.Response{
+			Msg: ErrEmailNotVerified.Error(),
+		})
+		return false
+	}
+	return true
+}
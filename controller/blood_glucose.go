@@ -5,15 +5,23 @@ import (
 	"diabetes-agent-server/model"
 	"diabetes-agent-server/request"
 	"diabetes-agent-server/response"
+	cgmimport "diabetes-agent-server/service/cgm-import"
+	glucosealert "diabetes-agent-server/service/glucose-alert"
+	glucoseanalytics "diabetes-agent-server/service/glucose-analytics"
+	"diabetes-agent-server/service/mq"
+	ossauth "diabetes-agent-server/service/oss-auth"
 	"diabetes-agent-server/utils"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 func CreateBloodGlucoseRecord(c *gin.Context) {
-	var req request.CreateBloodGlucoseRecordRequest
+	var req request.BloodGlucoseRecordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		slog.Error(ErrParseRequest.Error(), "err", err)
 		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
@@ -23,12 +31,7 @@ func CreateBloodGlucoseRecord(c *gin.Context) {
 	}
 
 	email := c.GetString("email")
-	record := model.BloodGlucoseRecord{
-		UserEmail:    email,
-		Value:        req.Value,
-		MeasuredAt:   req.MeasuredAt,
-		DiningStatus: req.DiningStatus,
-	}
+	record := convertBloodGlucoseRecordRequestToModel(req, email)
 	if err := dao.DB.Create(&record).Error; err != nil {
 		slog.Error(ErrCreateBloodGlucoseRecord.Error(), "err", err)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
@@ -37,11 +40,141 @@ func CreateBloodGlucoseRecord(c *gin.Context) {
 		return
 	}
 
+	glucosealert.SendAlertMessages(c.Request.Context(), mq.SendGlucoseAlertMessage, []model.BloodGlucoseRecord{record})
+
 	c.JSON(http.StatusCreated, response.Response{})
 }
 
-func GetBloodGlucoseRecords(c *gin.Context) {
+// BatchCreateBloodGlucoseRecords 批量录入血糖记录，逐条校验，
+// 校验通过的记录写入数据库，返回每条记录的处理结果
+func BatchCreateBloodGlucoseRecords(c *gin.Context) {
+	var req request.BatchCreateBloodGlucoseRecordsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	email := c.GetString("email")
+	results := make([]response.BatchCreateBloodGlucoseItemResult, len(req.Records))
+	records := make([]model.BloodGlucoseRecord, 0, len(req.Records))
+	// 待写入记录在请求中的下标
+	indexes := make([]int, 0, len(req.Records))
+	for i, item := range req.Records {
+		results[i].Index = i
+		if err := binding.Validator.ValidateStruct(item); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		records = append(records, convertBloodGlucoseRecordRequestToModel(item, email))
+		indexes = append(indexes, i)
+	}
+
+	if len(records) > 0 {
+		if err := dao.DB.Create(&records).Error; err != nil {
+			slog.Error(ErrBatchCreateBloodGlucoseRecords.Error(), "err", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+				Msg: ErrBatchCreateBloodGlucoseRecords.Error(),
+			})
+			return
+		}
+	}
+
+	for i, record := range records {
+		results[indexes[i]].Success = true
+		results[indexes[i]].ID = record.ID
+	}
+	glucosealert.SendAlertMessages(c.Request.Context(), mq.SendGlucoseAlertMessage, records)
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: response.BatchCreateBloodGlucoseRecordsResponse{
+			Created: len(records),
+			Failed:  len(req.Records) - len(records),
+			Results: results,
+		},
+	})
+}
+
+func UpdateBloodGlucoseRecord(c *gin.Context) {
+	var req request.BloodGlucoseRecordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 32)
+
 	email := c.GetString("email")
+	existing, err := dao.GetBloodGlucoseRecord(email, uint(id))
+	if err != nil {
+		slog.Error(ErrUpdateBloodGlucoseRecord.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrUpdateBloodGlucoseRecord.Error(),
+		})
+		return
+	}
+	if existing == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.Response{
+			Msg: ErrBloodGlucoseRecordNotFound.Error(),
+		})
+		return
+	}
+
+	record := convertBloodGlucoseRecordRequestToModel(req, email)
+	record.ID = existing.ID
+	if err := dao.UpdateBloodGlucoseRecord(record); err != nil {
+		slog.Error(ErrUpdateBloodGlucoseRecord.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrUpdateBloodGlucoseRecord.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{})
+}
+
+func DeleteBloodGlucoseRecord(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 32)
+
+	email := c.GetString("email")
+	existing, err := dao.GetBloodGlucoseRecord(email, uint(id))
+	if err != nil {
+		slog.Error(ErrDeleteBloodGlucoseRecord.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrDeleteBloodGlucoseRecord.Error(),
+		})
+		return
+	}
+	if existing == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.Response{
+			Msg: ErrBloodGlucoseRecordNotFound.Error(),
+		})
+		return
+	}
+
+	if err := dao.DeleteBloodGlucoseRecord(email, existing.ID); err != nil {
+		slog.Error(ErrDeleteBloodGlucoseRecord.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrDeleteBloodGlucoseRecord.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{})
+}
+
+func GetBloodGlucoseRecords(c *gin.Context) {
+	getBloodGlucoseRecords(c, c.GetString("email"))
+}
+
+func getBloodGlucoseRecords(c *gin.Context, email string) {
 	startStr := c.Query("start")
 	endStr := c.Query("end")
 
@@ -69,3 +202,107 @@ func GetBloodGlucoseRecords(c *gin.Context) {
 		Data: records,
 	})
 }
+
+// GetBloodGlucoseAnalytics 获取血糖分析指标（TIR/GMI/CV 等）
+func GetBloodGlucoseAnalytics(c *gin.Context) {
+	getBloodGlucoseAnalytics(c, c.GetString("email"))
+}
+
+func getBloodGlucoseAnalytics(c *gin.Context, email string) {
+	startStr := c.Query("start")
+	endStr := c.Query("end")
+
+	start, end, err := utils.ValidateTimeRange(startStr, endStr, "UTC")
+	if err != nil {
+		slog.Error(err.Error(),
+			"start", startStr,
+			"end", endStr)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	analytics, err := glucoseanalytics.GetAnalytics(email, start, end)
+	if err != nil {
+		slog.Error(ErrGetBloodGlucoseAnalytics.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetBloodGlucoseAnalytics.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: analytics,
+	})
+}
+
+// ImportCGMData 在前端将 CGM 导出文件上传到 OSS 后调用，向 MQ 发送导入任务
+// 导入结果通过系统消息通知用户
+func ImportCGMData(c *gin.Context) {
+	var req request.ImportCGMDataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(req.Timezone); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	email := c.GetString("email")
+	objectName, err := ossauth.GenerateKey(request.OSSAuthRequest{
+		Namespace: ossauth.OSSKeyPrefixCGMImport,
+		Email:     email,
+		FileName:  req.FileName,
+	})
+	if err != nil {
+		slog.Error(ErrGenerateOSSKey.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGenerateOSSKey.Error(),
+		})
+		return
+	}
+
+	err = mq.SendMessage(c.Request.Context(), &mq.Message{
+		Topic: mq.TopicBloodGlucose,
+		Tag:   mq.TagCGMImport,
+		Payload: cgmimport.ImportMessage{
+			Email:      email,
+			FileName:   req.FileName,
+			ObjectName: objectName,
+			Timezone:   req.Timezone,
+		},
+	})
+	if err != nil {
+		slog.Error(ErrImportCGMData.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrImportCGMData.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response.Response{})
+}
+
+// 向 MQ 发送提醒规则评估任务，发送失败不影响记录写入
+func convertBloodGlucoseRecordRequestToModel(req request.BloodGlucoseRecordRequest, email string) model.BloodGlucoseRecord {
+	return model.BloodGlucoseRecord{
+		UserEmail:    email,
+		Value:        req.Value,
+		MeasuredAt:   req.MeasuredAt,
+		DiningStatus: req.DiningStatus,
+		Notes:        req.Notes,
+		Source:       model.GlucoseSourceManual,
+	}
+}
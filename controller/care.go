@@ -0,0 +1,344 @@
+package controller
+
+import (
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/request"
+	"diabetes-agent-server/response"
+	ossauth "diabetes-agent-server/service/oss-auth"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCareShares 获取患者创建的数据授权
+func GetCareShares(c *gin.Context) {
+	email := c.GetString("email")
+	shares, err := dao.GetCareSharesByPatient(email)
+	if err != nil {
+		slog.Error(ErrGetCareShares.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetCareShares.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: convertCareSharesToResponse(shares),
+	})
+}
+
+// CreateCareShare 患者授权看护人或医生只读访问指定范围的数据，并通过系统消息通知被授权人
+func CreateCareShare(c *gin.Context) {
+	var req request.CreateCareShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrInvalidCareShareExpiry.Error(),
+		})
+		return
+	}
+
+	email := c.GetString("email")
+	if req.GranteeEmail == email {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrInvalidCareGrantee.Error(),
+		})
+		return
+	}
+
+	grantee, err := dao.GetUserByEmail(req.GranteeEmail)
+	if err != nil {
+		slog.Error(ErrCreateCareShare.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrCreateCareShare.Error(),
+		})
+		return
+	}
+	if grantee == nil || (grantee.Role != model.RoleCaregiver && grantee.Role != model.RoleClinician) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrInvalidCareGrantee.Error(),
+		})
+		return
+	}
+
+	existing, err := dao.GetCareShare(email, req.GranteeEmail)
+	if err != nil {
+		slog.Error(ErrCreateCareShare.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrCreateCareShare.Error(),
+		})
+		return
+	}
+	if existing != nil {
+		c.AbortWithStatusJSON(http.StatusConflict, response.Response{
+			Msg: ErrCareShareExists.Error(),
+		})
+		return
+	}
+
+	share := model.CareShare{
+		PatientEmail: email,
+		GranteeEmail: req.GranteeEmail,
+		Scopes:       joinCareScopes(req.Scopes),
+		ExpiresAt:    req.ExpiresAt,
+	}
+	if err := dao.CreateCareShare(&share); err != nil {
+		slog.Error(ErrCreateCareShare.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrCreateCareShare.Error(),
+		})
+		return
+	}
+
+	content := fmt.Sprintf("%s 已授权您查看其健康数据，授权范围：%s。", email, share.Scopes)
+	if err := dao.CreateSystemMessage(c.Request.Context(), req.GranteeEmail, "健康数据授权", content); err != nil {
+		slog.Error("Failed to save care share message",
+			"email", req.GranteeEmail,
+			"err", err,
+		)
+	}
+
+	c.JSON(http.StatusCreated, response.Response{
+		Data: convertCareShareToResponse(share),
+	})
+}
+
+// UpdateCareShare 修改授权范围和到期时间
+func UpdateCareShare(c *gin.Context) {
+	var req request.UpdateCareShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrInvalidCareShareExpiry.Error(),
+		})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 32)
+
+	email := c.GetString("email")
+	share, err := dao.GetCareShareByID(email, uint(id))
+	if err != nil {
+		slog.Error(ErrUpdateCareShare.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrUpdateCareShare.Error(),
+		})
+		return
+	}
+	if share == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.Response{
+			Msg: ErrCareShareNotFound.Error(),
+		})
+		return
+	}
+
+	share.Scopes = joinCareScopes(req.Scopes)
+	share.ExpiresAt = req.ExpiresAt
+	if err := dao.UpdateCareShare(share); err != nil {
+		slog.Error(ErrUpdateCareShare.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrUpdateCareShare.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: convertCareShareToResponse(*share),
+	})
+}
+
+// DeleteCareShare 撤销授权，被授权人立即失去访问权限
+func DeleteCareShare(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 32)
+
+	email := c.GetString("email")
+	share, err := dao.GetCareShareByID(email, uint(id))
+	if err != nil {
+		slog.Error(ErrDeleteCareShare.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrDeleteCareShare.Error(),
+		})
+		return
+	}
+	if share == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.Response{
+			Msg: ErrCareShareNotFound.Error(),
+		})
+		return
+	}
+
+	if err := dao.DeleteCareShare(email, uint(id)); err != nil {
+		slog.Error(ErrDeleteCareShare.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrDeleteCareShare.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{})
+}
+
+// GetCareAccessLogs 分页获取看护人/医生访问患者数据的记录
+func GetCareAccessLogs(c *gin.Context) {
+	email := c.GetString("email")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+
+	logs, err := dao.GetAccessAuditLogs(email, page)
+	if err != nil {
+		slog.Error(ErrGetCareAccessLogs.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetCareAccessLogs.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: logs,
+	})
+}
+
+// GetCarePatients 获取授权给当前看护人/医生的患者，仅返回有效期内的授权
+func GetCarePatients(c *gin.Context) {
+	email := c.GetString("email")
+	shares, err := dao.GetCareSharesByGrantee(email)
+	if err != nil {
+		slog.Error(ErrGetCarePatients.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetCarePatients.Error(),
+		})
+		return
+	}
+
+	shares = slices.DeleteFunc(shares, func(share model.CareShare) bool {
+		return !share.Active()
+	})
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: convertCareSharesToResponse(shares),
+	})
+}
+
+// 以下接口供看护人/医生只读访问患者数据，患者邮箱由 CareAccessMiddleware 校验授权后写入 patient_email
+
+func GetPatientBloodGlucoseRecords(c *gin.Context) {
+	getBloodGlucoseRecords(c, c.GetString("patient_email"))
+}
+
+func GetPatientBloodGlucoseAnalytics(c *gin.Context) {
+	getBloodGlucoseAnalytics(c, c.GetString("patient_email"))
+}
+
+func GetPatientExerciseRecords(c *gin.Context) {
+	getExerciseRecords(c, c.GetString("patient_email"))
+}
+
+func GetPatientHealthProfile(c *gin.Context) {
+	getHealthProfile(c, c.GetString("patient_email"))
+}
+
+func GetPatientHealthReports(c *gin.Context) {
+	getHealthReports(c, c.GetString("patient_email"))
+}
+
+// GetPatientHealthReportURL 获取患者健康报告的预签名 URL，format 为 pdf 时返回 PDF 版本
+func GetPatientHealthReportURL(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 32)
+
+	email := c.GetString("patient_email")
+	report, err := dao.GetHealthReportByID(uint(id))
+	if err != nil {
+		slog.Error(ErrGetPreSignedURL.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetPreSignedURL.Error(),
+		})
+		return
+	}
+	if report == nil || report.UserEmail != email || report.Status != model.ReportStatusCompleted {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.Response{
+			Msg: ErrHealthReportNotFound.Error(),
+		})
+		return
+	}
+
+	fileName := report.FileName
+	if c.Query("format") == "pdf" {
+		fileName = report.PDFFileName
+	}
+	if fileName == "" {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.Response{
+			Msg: ErrHealthReportNotFound.Error(),
+		})
+		return
+	}
+
+	url, err := ossauth.GeneratePresignedURL(request.OSSAuthRequest{
+		Namespace:       ossauth.OSSKeyPrefixHealthWeeklyReport,
+		Email:           email,
+		FileName:        fileName,
+		UseCustomDomain: c.Query("use-custom-domain") == "true",
+	})
+	if err != nil {
+		slog.Error(ErrGetPreSignedURL.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetPreSignedURL.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: response.GetPreSignedURLResponse{
+			URL: url,
+		},
+	})
+}
+
+// 去重后以逗号拼接，与 MySQL SET 类型的存储格式一致
+func joinCareScopes(scopes []string) string {
+	scopes = slices.Clone(scopes)
+	slices.Sort(scopes)
+	return strings.Join(slices.Compact(scopes), ",")
+}
+
+func convertCareShareToResponse(share model.CareShare) response.CareShareResponse {
+	return response.CareShareResponse{
+		ID:           share.ID,
+		CreatedAt:    share.CreatedAt,
+		PatientEmail: share.PatientEmail,
+		GranteeEmail: share.GranteeEmail,
+		Scopes:       share.ScopeList(),
+		ExpiresAt:    share.ExpiresAt,
+		Active:       share.Active(),
+	}
+}
+
+func convertCareSharesToResponse(shares []model.CareShare) []response.CareShareResponse {
+	resp := make([]response.CareShareResponse, 0, len(shares))
+	for _, share := range shares {
+		resp = append(resp, convertCareShareToResponse(share))
+	}
+	return resp
+}
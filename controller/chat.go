@@ -4,9 +4,11 @@ import (
 	"context"
 	"diabetes-agent-server/request"
 	"diabetes-agent-server/service/chat"
+	llmprovider "diabetes-agent-server/service/llm-provider"
 	"diabetes-agent-server/service/mq"
 	"diabetes-agent-server/service/summarization"
 	"diabetes-agent-server/utils"
+	"errors"
 	"log/slog"
 
 	"github.com/gin-gonic/gin"
@@ -24,6 +26,11 @@ func AgentChat(c *gin.Context) {
 	}
 
 	agent, err := chat.NewAgent(req, c)
+	if errors.Is(err, llmprovider.ErrUnknownModel) {
+		utils.SendSSEMessage(c, utils.EventError, ErrUnknownModel)
+		utils.SendSSEMessage(c, utils.EventDone, nil)
+		return
+	}
 	if err != nil {
 		slog.Error(ErrCreateAgent.Error(), "err", err)
 		utils.SendSSEMessage(c, utils.EventError, ErrCreateAgent)
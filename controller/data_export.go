@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/request"
+	"diabetes-agent-server/response"
+	dataexport "diabetes-agent-server/service/data-export"
+	"diabetes-agent-server/service/mq"
+	ossauth "diabetes-agent-server/service/oss-auth"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateDataExport 创建账号数据导出任务，通过 MQ 异步打包，完成后通过系统消息和邮件发送下载链接。
+// 已有未完成的任务时直接返回该任务
+func CreateDataExport(c *gin.Context) {
+	email := c.GetString("email")
+	existing, err := dao.GetUnfinishedDataExportJob(email)
+	if err != nil {
+		slog.Error(ErrCreateDataExport.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrCreateDataExport.Error(),
+		})
+		return
+	}
+	if existing != nil {
+		c.JSON(http.StatusAccepted, response.Response{
+			Data: response.CreateDataExportResponse{
+				ID: existing.ID,
+			},
+		})
+		return
+	}
+
+	job := model.DataExportJob{
+		UserEmail: email,
+		Status:    model.DataExportStatusPending,
+	}
+	if err := dao.CreateDataExportJob(&job); err != nil {
+		slog.Error(ErrCreateDataExport.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrCreateDataExport.Error(),
+		})
+		return
+	}
+
+	err = mq.SendMessage(c.Request.Context(), &mq.Message{
+		Topic: mq.TopicAccount,
+		Tag:   mq.TagDataExport,
+		Payload: dataexport.ExportMessage{
+			JobID: job.ID,
+		},
+	})
+	if err != nil {
+		slog.Error(ErrCreateDataExport.Error(), "err", err)
+		if err := dao.FailDataExportJob(job.ID, err.Error()); err != nil {
+			slog.Error("Failed to update data export job status", "err", err)
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrCreateDataExport.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response.Response{
+		Data: response.CreateDataExportResponse{
+			ID: job.ID,
+		},
+	})
+}
+
+// GetDataExports 获取账号数据导出任务及状态
+func GetDataExports(c *gin.Context) {
+	email := c.GetString("email")
+	jobs, err := dao.GetDataExportJobs(email)
+	if err != nil {
+		slog.Error(ErrGetDataExports.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetDataExports.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: jobs,
+	})
+}
+
+// GetDataExportURL 重新获取已完成导出文件的下载链接
+func GetDataExportURL(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 32)
+
+	email := c.GetString("email")
+	job, err := dao.GetDataExportJob(email, uint(id))
+	if err != nil {
+		slog.Error(ErrGetPreSignedURL.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetPreSignedURL.Error(),
+		})
+		return
+	}
+	if job == nil || job.Status != model.DataExportStatusCompleted {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.Response{
+			Msg: ErrDataExportNotFound.Error(),
+		})
+		return
+	}
+
+	url, err := ossauth.GeneratePresignedURL(request.OSSAuthRequest{
+		Namespace: ossauth.OSSKeyPrefixDataExport,
+		Email:     email,
+		FileName:  job.FileName,
+		Expires:   dataexport.DownloadURLExpires,
+	})
+	if err != nil {
+		slog.Error(ErrGetPreSignedURL.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetPreSignedURL.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: response.GetPreSignedURLResponse{
+			URL: url,
+		},
+	})
+}
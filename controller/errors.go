@@ -8,7 +8,15 @@ var (
 	ErrUserRegister         = errors.New("failed to register user")
 	ErrGenerateToken        = errors.New("failed to generate token")
 	ErrUserLogin            = errors.New("failed to login")
+	ErrTooManyLoginAttempts = errors.New("too many failed login attempts, please try again later")
 	ErrSendVerificationCode = errors.New("failed to send verification code")
+	ErrRefreshToken         = errors.New("failed to refresh token")
+	ErrLogout               = errors.New("failed to logout")
+	ErrChangePassword       = errors.New("failed to change password")
+	ErrResetPassword        = errors.New("failed to reset password")
+	ErrVerifyEmail          = errors.New("failed to verify email")
+	ErrEmailNotVerified     = errors.New("email is not verified")
+	ErrGetUser              = errors.New("failed to get user")
 
 	ErrCreateSession      = errors.New("failed to create an agent session")
 	ErrGetSessions        = errors.New("failed to get agent sessions")
@@ -16,8 +24,9 @@ var (
 	ErrGetSessionMessages = errors.New("failed to get session messages")
 	ErrUpdateSessionTitle = errors.New("failed to update session title")
 
-	ErrCreateAgent = errors.New("failed to create an agent")
-	ErrCallAgent   = errors.New("error while calling agent")
+	ErrCreateAgent  = errors.New("failed to create an agent")
+	ErrUnknownModel = errors.New("unknown model")
+	ErrCallAgent    = errors.New("error while calling agent")
 
 	ErrGetAudioFile     = errors.New("failed to get audio file")
 	ErrVoiceRecognition = errors.New("failed to recognize audio")
@@ -30,22 +39,108 @@ var (
 	ErrGetPreSignedURL         = errors.New("failed to get presigned url")
 	ErrSearchKnowledgeMetadata = errors.New("failed to search knowledge metadata")
 
-	ErrCreateBloodGlucoseRecord = errors.New("failed to create blood glucose record")
-	ErrGetBloodGlucoseRecords   = errors.New("failed to get blood glucose records")
+	ErrCreateBloodGlucoseRecord       = errors.New("failed to create blood glucose record")
+	ErrGetBloodGlucoseRecords         = errors.New("failed to get blood glucose records")
+	ErrUpdateBloodGlucoseRecord       = errors.New("failed to update blood glucose record")
+	ErrDeleteBloodGlucoseRecord       = errors.New("failed to delete blood glucose record")
+	ErrBloodGlucoseRecordNotFound     = errors.New("blood glucose record not found")
+	ErrBatchCreateBloodGlucoseRecords = errors.New("failed to batch create blood glucose records")
+	ErrGetBloodGlucoseAnalytics       = errors.New("failed to get blood glucose analytics")
+	ErrImportCGMData                  = errors.New("failed to import cgm data")
 
-	ErrGetHealthProfile    = errors.New("failed to get health profile")
-	ErrCreateHealthProfile = errors.New("failed to create health profile")
-	ErrUpdateHealthProfile = errors.New("failed to update health profile")
+	ErrGetAlertRules     = errors.New("failed to get alert rules")
+	ErrCreateAlertRule   = errors.New("failed to create alert rule")
+	ErrUpdateAlertRule   = errors.New("failed to update alert rule")
+	ErrDeleteAlertRule   = errors.New("failed to delete alert rule")
+	ErrAlertRuleNotFound = errors.New("alert rule not found")
+
+	ErrGetLabResults         = errors.New("failed to get lab results")
+	ErrGetLabResultTrend     = errors.New("failed to get lab result trend")
+	ErrCreateLabResult       = errors.New("failed to create lab result")
+	ErrUpdateLabResult       = errors.New("failed to update lab result")
+	ErrDeleteLabResult       = errors.New("failed to delete lab result")
+	ErrLabResultNotFound     = errors.New("lab result not found")
+	ErrInvalidLabKind        = errors.New("invalid lab kind")
+	ErrInvalidReferenceRange = errors.New("invalid reference range")
+
+	ErrGetHealthProfile        = errors.New("failed to get health profile")
+	ErrCreateHealthProfile     = errors.New("failed to create health profile")
+	ErrUpdateHealthProfile     = errors.New("failed to update health profile")
+	ErrHealthProfileNotFound   = errors.New("health profile not found")
+	ErrGetHealthProfileHistory = errors.New("failed to get health profile history")
+	ErrInvalidGlucoseBands     = errors.New("glucose bands must satisfy very_low < low < high < very_high")
 
 	ErrCreateExerciseRecord = errors.New("failed to create exercise record")
 	ErrGetExerciseRecords   = errors.New("failed to get exercise records")
 	ErrDeleteExerciseRecord = errors.New("failed to delete exercise record")
 
-	ErrGetHealthWeeklyReports = errors.New("failed to get health weekly reports")
+	ErrGetVitalRecords   = errors.New("failed to get vital records")
+	ErrCreateVitalRecord = errors.New("failed to create vital record")
+	ErrDeleteVitalRecord = errors.New("failed to delete vital record")
+
+	ErrCreateMealRecord = errors.New("failed to create meal record")
+	ErrGetMealRecords   = errors.New("failed to get meal records")
+	ErrDeleteMealRecord = errors.New("failed to delete meal record")
+
+	ErrGetMedicationPlans     = errors.New("failed to get medication plans")
+	ErrCreateMedicationPlan   = errors.New("failed to create medication plan")
+	ErrUpdateMedicationPlan   = errors.New("failed to update medication plan")
+	ErrDeleteMedicationPlan   = errors.New("failed to delete medication plan")
+	ErrMedicationPlanNotFound = errors.New("medication plan not found")
+	ErrGetMedicationIntakes   = errors.New("failed to get medication intakes")
+	ErrCreateMedicationIntake = errors.New("failed to create medication intake")
+	ErrDeleteMedicationIntake = errors.New("failed to delete medication intake")
+	ErrGetMedicationAdherence = errors.New("failed to get medication adherence")
+
+	ErrGetHealthWeeklyReports  = errors.New("failed to get health weekly reports")
+	ErrGetHealthReports        = errors.New("failed to get health reports")
+	ErrGenerateHealthReport    = errors.New("failed to generate health report")
+	ErrReportRangeTooLong      = errors.New("report range exceeds one year")
+	ErrGetHealthReportJobs     = errors.New("failed to get health report jobs")
+	ErrRerunHealthReportJob    = errors.New("failed to rerun health report job")
+	ErrHealthReportJobNotFound = errors.New("health report job not found")
+	ErrHealthReportJobRunning  = errors.New("health report job is running")
+	ErrHealthReportNotFound    = errors.New("health report not found")
 
 	ErrGetSystemMessages            = errors.New("failed to get system messages")
 	ErrUpdateSystemMessageAsRead    = errors.New("failed to update system message as read")
 	ErrDeleteSystemMessage          = errors.New("failed to delete system message")
 	ErrGetUnreadSystemMessageCount  = errors.New("failed to get unread system message count")
 	ErrUpdateUserEnableNotification = errors.New("failed to update user enable notification")
+	ErrUpdateInsufficientDataPolicy = errors.New("failed to update insufficient data policy")
+
+	ErrInvalidLLMUsageGranularity = errors.New("invalid llm usage granularity")
+	ErrGetLLMUsage                = errors.New("failed to get llm usage")
+
+	ErrGetCareShares          = errors.New("failed to get care shares")
+	ErrCreateCareShare        = errors.New("failed to create care share")
+	ErrUpdateCareShare        = errors.New("failed to update care share")
+	ErrDeleteCareShare        = errors.New("failed to delete care share")
+	ErrCareShareNotFound      = errors.New("care share not found")
+	ErrCareShareExists        = errors.New("care share already exists")
+	ErrInvalidCareGrantee     = errors.New("grantee must be a registered caregiver or clinician")
+	ErrInvalidCareShareExpiry = errors.New("care share expiry must be in the future")
+	ErrGetCareAccessLogs      = errors.New("failed to get care access logs")
+	ErrGetCarePatients        = errors.New("failed to get care patients")
+
+	ErrGetUsers                   = errors.New("failed to get users")
+	ErrUserNotFound               = errors.New("user not found")
+	ErrModifySelf                 = errors.New("cannot modify the current admin user")
+	ErrUpdateUserDisabled         = errors.New("failed to update user disabled")
+	ErrUpdateUserRole             = errors.New("failed to update user role")
+	ErrReprocessKnowledgeMetadata = errors.New("failed to reprocess knowledge metadata")
+	ErrKnowledgeMetadataNotFound  = errors.New("knowledge metadata not found")
+	ErrKnowledgeMetadataNotFailed = errors.New("knowledge metadata is not in failed status")
+	ErrRegenerateHealthReport     = errors.New("failed to regenerate health report")
+	ErrReportPeriodNotEnded       = errors.New("report period has not ended")
+	ErrBroadcastSystemMessage     = errors.New("failed to broadcast system message")
+
+	ErrCreateDataExport   = errors.New("failed to create data export")
+	ErrGetDataExports     = errors.New("failed to get data exports")
+	ErrDataExportNotFound = errors.New("data export not found")
+
+	ErrCreateAccountDeletion   = errors.New("failed to create account deletion")
+	ErrGetAccountDeletion      = errors.New("failed to get account deletion")
+	ErrCancelAccountDeletion   = errors.New("failed to cancel account deletion")
+	ErrAccountDeletionNotFound = errors.New("account deletion not found")
 )
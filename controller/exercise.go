@@ -14,7 +14,10 @@ import (
 )
 
 func GetExerciseRecords(c *gin.Context) {
-	email := c.GetString("email")
+	getExerciseRecords(c, c.GetString("email"))
+}
+
+func getExerciseRecords(c *gin.Context, email string) {
 	startStr := c.Query("start")
 	endStr := c.Query("end")
 
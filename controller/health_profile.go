@@ -1,15 +1,18 @@
 package controller
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 
 	"diabetes-agent-server/dao"
 	"diabetes-agent-server/model"
 	"diabetes-agent-server/request"
 	"diabetes-agent-server/response"
+	glucoseanalytics "diabetes-agent-server/service/glucose-analytics"
 )
 
 func CreateHealthProfile(c *gin.Context) {
@@ -24,7 +27,14 @@ func CreateHealthProfile(c *gin.Context) {
 
 	email := c.GetString("email")
 	profile := convertRequestToModel(req, email)
-	if err := dao.DB.Create(&profile).Error; err != nil {
+	if !isValidGlucoseBands(profile) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrInvalidGlucoseBands.Error(),
+		})
+		return
+	}
+
+	if err := dao.CreateHealthProfile(profile); err != nil {
 		slog.Error(ErrCreateHealthProfile.Error(), "err", err)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
 			Msg: ErrCreateHealthProfile.Error(),
@@ -36,7 +46,10 @@ func CreateHealthProfile(c *gin.Context) {
 }
 
 func GetHealthProfile(c *gin.Context) {
-	email := c.GetString("email")
+	getHealthProfile(c, c.GetString("email"))
+}
+
+func getHealthProfile(c *gin.Context, email string) {
 	profile, err := dao.GetHealthProfile(email)
 	if err != nil {
 		slog.Error(ErrGetHealthProfile.Error(), "err", err)
@@ -61,8 +74,25 @@ func UpdateHealthProfile(c *gin.Context) {
 	}
 
 	email := c.GetString("email")
-	profile := convertRequestToModel(req, email)
-	err := dao.UpdateHealthProfile(profile)
+	err := dao.UpdateHealthProfile(email, func(profile *model.HealthProfile) error {
+		applyHealthProfileRequest(req, profile)
+		if !isValidGlucoseBands(*profile) {
+			return ErrInvalidGlucoseBands
+		}
+		return nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.Response{
+			Msg: ErrHealthProfileNotFound.Error(),
+		})
+		return
+	}
+	if errors.Is(err, ErrInvalidGlucoseBands) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrInvalidGlucoseBands.Error(),
+		})
+		return
+	}
 	if err != nil {
 		slog.Error(ErrUpdateHealthProfile.Error(), "err", err)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
@@ -74,21 +104,63 @@ func UpdateHealthProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, response.Response{})
 }
 
+func GetHealthProfileHistory(c *gin.Context) {
+	email := c.GetString("email")
+	history, err := dao.GetHealthProfileHistory(email)
+	if err != nil {
+		slog.Error(ErrGetHealthProfileHistory.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetHealthProfileHistory.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: history,
+	})
+}
+
 func convertRequestToModel(req request.HealthProfileRequest, email string) model.HealthProfile {
-	return model.HealthProfile{
-		UserEmail:         email,
-		Gender:            req.Gender,
-		Age:               req.Age,
-		Height:            req.Height,
-		Weight:            req.Weight,
-		DietaryPreference: req.DietaryPreference,
-		SmokingStatus:     req.SmokingStatus,
-		ActivityLevel:     req.ActivityLevel,
-		DiabetesType:      req.DiabetesType,
-		DiagnosisYear:     req.DiagnosisYear,
-		TherapyMode:       req.TherapyMode,
-		Medication:        req.Medication,
-		Allergies:         req.Allergies,
-		Complications:     req.Complications,
+	profile := model.HealthProfile{
+		UserEmail: email,
+	}
+	applyHealthProfileRequest(req, &profile)
+	return profile
+}
+
+// 将请求中包含的字段写入档案，未包含的字段保持不变
+func applyHealthProfileRequest(req request.HealthProfileRequest, profile *model.HealthProfile) {
+	assign(&profile.Gender, req.Gender)
+	assign(&profile.Age, req.Age)
+	assign(&profile.Height, req.Height)
+	assign(&profile.Weight, req.Weight)
+	assign(&profile.DietaryPreference, req.DietaryPreference)
+	assign(&profile.SmokingStatus, req.SmokingStatus)
+	assign(&profile.ActivityLevel, req.ActivityLevel)
+	assign(&profile.DiabetesType, req.DiabetesType)
+	assign(&profile.DiagnosisYear, req.DiagnosisYear)
+	assign(&profile.TherapyMode, req.TherapyMode)
+	assign(&profile.Medication, req.Medication)
+	assign(&profile.Allergies, req.Allergies)
+	assign(&profile.Complications, req.Complications)
+	assign(&profile.GlucoseVeryLow, req.GlucoseVeryLow)
+	assign(&profile.GlucoseLow, req.GlucoseLow)
+	assign(&profile.GlucoseHigh, req.GlucoseHigh)
+	assign(&profile.GlucoseVeryHigh, req.GlucoseVeryHigh)
+}
+
+func assign[T any](dst *T, src *T) {
+	if src != nil {
+		*dst = *src
 	}
 }
+
+// 未设置的阈值按默认值参与校验，合并后需满足 very_low < low < high < very_high
+func isValidGlucoseBands(profile model.HealthProfile) bool {
+	return glucoseanalytics.NewBands(
+		profile.GlucoseVeryLow,
+		profile.GlucoseLow,
+		profile.GlucoseHigh,
+		profile.GlucoseVeryHigh,
+	).Valid()
+}
@@ -3,14 +3,23 @@ package controller
 import (
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"diabetes-agent-server/dao"
+	"diabetes-agent-server/model"
 	"diabetes-agent-server/request"
 	"diabetes-agent-server/response"
+	healthreport "diabetes-agent-server/service/health-weekly-report"
+	"diabetes-agent-server/service/mq"
+	"diabetes-agent-server/utils"
 )
 
+// 自定义周期报告的最大时间跨度
+const maxCustomReportRange = 366 * 24 * time.Hour
+
 func GetHealthWeeklyReports(c *gin.Context) {
 	email := c.GetString("email")
 	reports, err := dao.GetHealthWeeklyReports(email)
@@ -27,6 +36,221 @@ func GetHealthWeeklyReports(c *gin.Context) {
 	})
 }
 
+// GetHealthReports 获取各周期的健康报告及生成状态，可按 period_type 过滤
+func GetHealthReports(c *gin.Context) {
+	getHealthReports(c, c.GetString("email"))
+}
+
+func getHealthReports(c *gin.Context, email string) {
+	periodType := c.Query("period_type")
+
+	reports, err := dao.GetHealthReports(email, periodType)
+	if err != nil {
+		slog.Error(ErrGetHealthReports.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetHealthReports.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: reports,
+	})
+}
+
+// GenerateHealthReport 创建自定义周期的健康报告，通过 MQ 异步生成，
+// 生成状态可通过报告列表查询
+func GenerateHealthReport(c *gin.Context) {
+	var req request.GenerateHealthReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	start, end, err := utils.ValidateTimeRange(req.Start, req.End, "UTC")
+	if err != nil {
+		slog.Error(err.Error(),
+			"start", req.Start,
+			"end", req.End)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: err.Error(),
+		})
+		return
+	}
+	if end.Sub(start) > maxCustomReportRange {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrReportRangeTooLong.Error(),
+		})
+		return
+	}
+
+	email := c.GetString("email")
+	existing, err := dao.GetHealthReportByRange(email, model.ReportPeriodCustom, start.UTC(), end.UTC())
+	if err != nil {
+		slog.Error(ErrGenerateHealthReport.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGenerateHealthReport.Error(),
+		})
+		return
+	}
+
+	// 相同时间范围的报告已存在且未失败、未中断时直接返回，避免重复生成
+	if existing != nil && existing.Status != model.ReportStatusFailed && !isStaleReport(existing) {
+		c.JSON(http.StatusAccepted, response.Response{
+			Data: response.GenerateHealthReportResponse{
+				ID: existing.ID,
+			},
+		})
+		return
+	}
+
+	// 失败的报告重置为等待生成，中断的报告直接重新投递消息，由消费者领取后生成
+	report := existing
+	if report == nil {
+		report = &model.HealthWeeklyReport{
+			UserEmail:  email,
+			PeriodType: model.ReportPeriodCustom,
+			StartAt:    start.UTC(),
+			EndAt:      end.UTC(),
+			Status:     model.ReportStatusPending,
+		}
+		created, err := dao.CreateHealthReport(report)
+		if err != nil {
+			slog.Error(ErrGenerateHealthReport.Error(), "err", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+				Msg: ErrGenerateHealthReport.Error(),
+			})
+			return
+		}
+
+		// 相同时间范围的报告已由并发请求创建，返回该报告
+		if !created {
+			report, err = dao.GetHealthReportByRange(email, model.ReportPeriodCustom, start.UTC(), end.UTC())
+			if err != nil || report == nil {
+				slog.Error(ErrGenerateHealthReport.Error(), "err", err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+					Msg: ErrGenerateHealthReport.Error(),
+				})
+				return
+			}
+
+			c.JSON(http.StatusAccepted, response.Response{
+				Data: response.GenerateHealthReportResponse{
+					ID: report.ID,
+				},
+			})
+			return
+		}
+	} else if report.Status == model.ReportStatusFailed {
+		if err := dao.UpdateHealthReportStatus(report.ID, model.ReportStatusPending, ""); err != nil {
+			slog.Error(ErrGenerateHealthReport.Error(), "err", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+				Msg: ErrGenerateHealthReport.Error(),
+			})
+			return
+		}
+	}
+
+	err = mq.SendMessage(c.Request.Context(), &mq.Message{
+		Topic: mq.TopicHealthReport,
+		Tag:   mq.TagGenerateReport,
+		Payload: healthreport.GenerateReportMessage{
+			ReportID: report.ID,
+		},
+	})
+	if err != nil {
+		slog.Error(ErrGenerateHealthReport.Error(), "err", err)
+		if err := dao.UpdateHealthReportStatus(report.ID, model.ReportStatusFailed, err.Error()); err != nil {
+			slog.Error("Failed to update health report status", "err", err)
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGenerateHealthReport.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response.Response{
+		Data: response.GenerateHealthReportResponse{
+			ID: report.ID,
+		},
+	})
+}
+
+// GetHealthReportJobs 获取定时报告任务的执行状态
+func GetHealthReportJobs(c *gin.Context) {
+	email := c.GetString("email")
+	jobs, err := dao.GetHealthReportJobs(email)
+	if err != nil {
+		slog.Error(ErrGetHealthReportJobs.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetHealthReportJobs.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: jobs,
+	})
+}
+
+// RerunHealthReportJob 重新执行定时报告任务，用于生成失败后重试或重新生成已有报告
+func RerunHealthReportJob(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 32)
+
+	email := c.GetString("email")
+	job, err := dao.GetHealthReportJob(email, uint(id))
+	if err != nil {
+		slog.Error(ErrRerunHealthReportJob.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrRerunHealthReportJob.Error(),
+		})
+		return
+	}
+	if job == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.Response{
+			Msg: ErrHealthReportJobNotFound.Error(),
+		})
+		return
+	}
+
+	reset, err := dao.ResetHealthReportJob(email, job.ID)
+	if err != nil {
+		slog.Error(ErrRerunHealthReportJob.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrRerunHealthReportJob.Error(),
+		})
+		return
+	}
+	if !reset {
+		c.AbortWithStatusJSON(http.StatusConflict, response.Response{
+			Msg: ErrHealthReportJobRunning.Error(),
+		})
+		return
+	}
+
+	// 投递失败时任务保持等待状态，由定时续跑重新投递
+	err = mq.SendMessage(c.Request.Context(), &mq.Message{
+		Topic: mq.TopicHealthReport,
+		Tag:   mq.TagReportJob,
+		Payload: healthreport.ReportJobMessage{
+			JobID: job.ID,
+		},
+	})
+	if err != nil {
+		slog.Error(ErrRerunHealthReportJob.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrRerunHealthReportJob.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response.Response{})
+}
+
 func UpdateUserEnableNotification(c *gin.Context) {
 	var req request.UpdateUserEnableNotificationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -38,6 +262,10 @@ func UpdateUserEnableNotification(c *gin.Context) {
 	}
 
 	email := c.GetString("email")
+	if req.EnableWeeklyReportNotification && !checkEmailVerified(c, email) {
+		return
+	}
+
 	if err := dao.UpdateEnableNotification(email, req.EnableWeeklyReportNotification); err != nil {
 		slog.Error(ErrUpdateUserEnableNotification.Error(), "err", err)
 		c.JSON(http.StatusInternalServerError, response.Response{
@@ -47,3 +275,33 @@ func UpdateUserEnableNotification(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, response.Response{})
 }
+
+// UpdateInsufficientDataPolicy 设置定时报告数据不足时跳过报告或生成提示报告
+func UpdateInsufficientDataPolicy(c *gin.Context) {
+	var req request.UpdateInsufficientDataPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	email := c.GetString("email")
+	if err := dao.UpdateInsufficientDataPolicy(email, req.Policy); err != nil {
+		slog.Error(ErrUpdateInsufficientDataPolicy.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrUpdateInsufficientDataPolicy.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, response.Response{})
+}
+
+// 判断报告是否长时间停留在等待生成或生成中状态，如消息丢失或服务在生成过程中重启
+func isStaleReport(report *model.HealthWeeklyReport) bool {
+	if report.Status != model.ReportStatusPending && report.Status != model.ReportStatusGenerating {
+		return false
+	}
+	return time.Since(report.UpdatedAt) > healthreport.StaleReportTimeout
+}
@@ -0,0 +1,231 @@
+package controller
+
+import (
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/request"
+	"diabetes-agent-server/response"
+	"diabetes-agent-server/utils"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetLabResults(c *gin.Context) {
+	email := c.GetString("email")
+	startStr := c.Query("start")
+	endStr := c.Query("end")
+	kind := c.Query("kind")
+
+	start, end, err := utils.ValidateTimeRange(startStr, endStr, "UTC")
+	if err != nil {
+		slog.Error(err.Error(),
+			"start", startStr,
+			"end", endStr)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	if _, ok := model.LabKinds[kind]; kind != "" && !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrInvalidLabKind.Error(),
+		})
+		return
+	}
+
+	results, err := dao.GetLabResults(email, kind, start, end)
+	if err != nil {
+		slog.Error(ErrGetLabResults.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetLabResults.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: results,
+	})
+}
+
+// GetLatestLabResults 获取各检验项目的最近一次结果
+func GetLatestLabResults(c *gin.Context) {
+	email := c.GetString("email")
+	results, err := dao.GetLatestLabResults(email)
+	if err != nil {
+		slog.Error(ErrGetLabResults.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetLabResults.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: results,
+	})
+}
+
+// GetLabResultTrend 获取单个检验项目的变化趋势
+func GetLabResultTrend(c *gin.Context) {
+	email := c.GetString("email")
+	startStr := c.Query("start")
+	endStr := c.Query("end")
+	kind := c.Query("kind")
+
+	start, end, err := utils.ValidateTimeRange(startStr, endStr, "UTC")
+	if err != nil {
+		slog.Error(err.Error(),
+			"start", startStr,
+			"end", endStr)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	if _, ok := model.LabKinds[kind]; !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrInvalidLabKind.Error(),
+		})
+		return
+	}
+
+	trend, err := dao.GetLabResultTrend(email, kind, start, end)
+	if err != nil {
+		slog.Error(ErrGetLabResultTrend.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetLabResultTrend.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: trend,
+	})
+}
+
+func CreateLabResult(c *gin.Context) {
+	var req request.LabResultRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	email := c.GetString("email")
+	result := convertLabResultRequestToModel(req, email)
+	if !isValidReferenceRange(result) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrInvalidReferenceRange.Error(),
+		})
+		return
+	}
+
+	if err := dao.DB.Create(&result).Error; err != nil {
+		slog.Error(ErrCreateLabResult.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrCreateLabResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.Response{})
+}
+
+func UpdateLabResult(c *gin.Context) {
+	var req request.LabResultRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 32)
+
+	email := c.GetString("email")
+	existing, err := dao.GetLabResult(email, uint(id))
+	if err != nil {
+		slog.Error(ErrUpdateLabResult.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrUpdateLabResult.Error(),
+		})
+		return
+	}
+	if existing == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.Response{
+			Msg: ErrLabResultNotFound.Error(),
+		})
+		return
+	}
+
+	result := convertLabResultRequestToModel(req, email)
+	result.ID = existing.ID
+	if !isValidReferenceRange(result) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrInvalidReferenceRange.Error(),
+		})
+		return
+	}
+
+	if err := dao.UpdateLabResult(result); err != nil {
+		slog.Error(ErrUpdateLabResult.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrUpdateLabResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{})
+}
+
+func DeleteLabResult(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 32)
+
+	email := c.GetString("email")
+	if err := dao.DeleteLabResult(email, uint(id)); err != nil {
+		slog.Error(ErrDeleteLabResult.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrDeleteLabResult.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{})
+}
+
+// 单位取自检验项目定义，未填写参考范围时使用默认参考范围
+func convertLabResultRequestToModel(req request.LabResultRequest, email string) model.LabResult {
+	labKind := model.LabKinds[req.Kind]
+
+	result := model.LabResult{
+		UserEmail:     email,
+		Kind:          req.Kind,
+		Value:         req.Value,
+		Unit:          labKind.Unit,
+		ReferenceLow:  req.ReferenceLow,
+		ReferenceHigh: req.ReferenceHigh,
+		TestedAt:      req.TestedAt,
+		Notes:         req.Notes,
+	}
+	if result.ReferenceLow == nil && result.ReferenceHigh == nil {
+		result.ReferenceLow = labKind.ReferenceLow
+		result.ReferenceHigh = labKind.ReferenceHigh
+	}
+	return result
+}
+
+func isValidReferenceRange(result model.LabResult) bool {
+	if result.ReferenceLow == nil || result.ReferenceHigh == nil {
+		return true
+	}
+	return *result.ReferenceLow <= *result.ReferenceHigh
+}
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/response"
+	"diabetes-agent-server/utils"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetLLMUsage 按天或按月汇总当前用户的 token 用量，默认按天
+func GetLLMUsage(c *gin.Context) {
+	getLLMUsage(c, c.GetString("email"))
+}
+
+func getLLMUsage(c *gin.Context, email string) {
+	granularity := c.DefaultQuery("granularity", dao.LLMUsageGranularityDaily)
+	startStr := c.Query("start")
+	endStr := c.Query("end")
+
+	if granularity != dao.LLMUsageGranularityDaily && granularity != dao.LLMUsageGranularityMonthly {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrInvalidLLMUsageGranularity.Error(),
+		})
+		return
+	}
+
+	start, end, err := utils.ValidateTimeRange(startStr, endStr, "UTC")
+	if err != nil {
+		slog.Error(err.Error(),
+			"start", startStr,
+			"end", endStr)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	usages, err := dao.GetLLMUsage(email, granularity, start, end)
+	if err != nil {
+		slog.Error(ErrGetLLMUsage.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetLLMUsage.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: usages,
+	})
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/request"
+	"diabetes-agent-server/response"
+	"diabetes-agent-server/utils"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetMealRecords(c *gin.Context) {
+	email := c.GetString("email")
+	startStr := c.Query("start")
+	endStr := c.Query("end")
+
+	start, end, err := utils.ValidateTimeRange(startStr, endStr, "UTC")
+	if err != nil {
+		slog.Error(err.Error(),
+			"start", startStr,
+			"end", endStr)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	records, err := dao.GetMealRecords(email, start, end)
+	if err != nil {
+		slog.Error(ErrGetMealRecords.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetMealRecords.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: records,
+	})
+}
+
+func CreateMealRecord(c *gin.Context) {
+	var req request.MealRecordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	email := c.GetString("email")
+	meal := model.MealRecord{
+		UserEmail:    email,
+		MealType:     req.MealType,
+		EatenAt:      req.EatenAt,
+		Foods:        make([]model.MealFood, 0, len(req.Foods)),
+		Carbohydrate: req.Carbohydrate,
+		Calories:     req.Calories,
+		Notes:        req.Notes,
+	}
+
+	var foodsCarbohydrate, foodsCalories float32
+	for _, food := range req.Foods {
+		meal.Foods = append(meal.Foods, model.MealFood{
+			Name:         food.Name,
+			Amount:       food.Amount,
+			Carbohydrate: food.Carbohydrate,
+			Calories:     food.Calories,
+		})
+		foodsCarbohydrate += food.Carbohydrate
+		foodsCalories += food.Calories
+	}
+
+	// 未填写总量时，按食物明细累加
+	if meal.Carbohydrate == 0 {
+		meal.Carbohydrate = foodsCarbohydrate
+	}
+	if meal.Calories == 0 {
+		meal.Calories = foodsCalories
+	}
+
+	if err := dao.DB.Create(&meal).Error; err != nil {
+		slog.Error(ErrCreateMealRecord.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrCreateMealRecord.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.Response{})
+}
+
+func DeleteMealRecord(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 32)
+
+	email := c.GetString("email")
+	if err := dao.DeleteMealRecord(email, uint(id)); err != nil {
+		slog.Error(ErrDeleteMealRecord.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrDeleteMealRecord.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{})
+}
@@ -0,0 +1,269 @@
+package controller
+
+import (
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/request"
+	"diabetes-agent-server/response"
+	"diabetes-agent-server/utils"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetMedicationPlans(c *gin.Context) {
+	email := c.GetString("email")
+	plans, err := dao.GetMedicationPlans(email)
+	if err != nil {
+		slog.Error(ErrGetMedicationPlans.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetMedicationPlans.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: plans,
+	})
+}
+
+func CreateMedicationPlan(c *gin.Context) {
+	var req request.MedicationPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	email := c.GetString("email")
+	plan := convertMedicationPlanRequestToModel(req, email)
+	if err := dao.DB.Create(&plan).Error; err != nil {
+		slog.Error(ErrCreateMedicationPlan.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrCreateMedicationPlan.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.Response{})
+}
+
+func UpdateMedicationPlan(c *gin.Context) {
+	var req request.MedicationPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 32)
+
+	email := c.GetString("email")
+	existing, err := dao.GetMedicationPlan(email, uint(id))
+	if err != nil {
+		slog.Error(ErrUpdateMedicationPlan.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrUpdateMedicationPlan.Error(),
+		})
+		return
+	}
+	if existing == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, response.Response{
+			Msg: ErrMedicationPlanNotFound.Error(),
+		})
+		return
+	}
+
+	plan := convertMedicationPlanRequestToModel(req, email)
+	plan.ID = existing.ID
+	if err := dao.UpdateMedicationPlan(plan); err != nil {
+		slog.Error(ErrUpdateMedicationPlan.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrUpdateMedicationPlan.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{})
+}
+
+func DeleteMedicationPlan(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 32)
+
+	email := c.GetString("email")
+	if err := dao.DeleteMedicationPlan(email, uint(id)); err != nil {
+		slog.Error(ErrDeleteMedicationPlan.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrDeleteMedicationPlan.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{})
+}
+
+func GetMedicationIntakes(c *gin.Context) {
+	email := c.GetString("email")
+	startStr := c.Query("start")
+	endStr := c.Query("end")
+
+	start, end, err := utils.ValidateTimeRange(startStr, endStr, "UTC")
+	if err != nil {
+		slog.Error(err.Error(),
+			"start", startStr,
+			"end", endStr)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	intakes, err := dao.GetMedicationIntakes(email, start, end)
+	if err != nil {
+		slog.Error(ErrGetMedicationIntakes.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetMedicationIntakes.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: intakes,
+	})
+}
+
+func CreateMedicationIntake(c *gin.Context) {
+	var req request.MedicationIntakeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	email := c.GetString("email")
+	intake := model.MedicationIntake{
+		UserEmail: email,
+		PlanID:    req.PlanID,
+		DrugName:  req.DrugName,
+		Dose:      req.Dose,
+		DoseUnit:  req.DoseUnit,
+		TakenAt:   req.TakenAt,
+		Status:    req.Status,
+		Notes:     req.Notes,
+	}
+
+	// 关联用药计划时，未填写的药品信息按计划补全
+	if req.PlanID > 0 {
+		plan, err := dao.GetMedicationPlan(email, req.PlanID)
+		if err != nil {
+			slog.Error(ErrCreateMedicationIntake.Error(), "err", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+				Msg: ErrCreateMedicationIntake.Error(),
+			})
+			return
+		}
+		if plan == nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+				Msg: ErrMedicationPlanNotFound.Error(),
+			})
+			return
+		}
+
+		if intake.DrugName == "" {
+			intake.DrugName = plan.DrugName
+		}
+		if intake.Dose == 0 {
+			intake.Dose = plan.Dose
+		}
+		if intake.DoseUnit == "" {
+			intake.DoseUnit = plan.DoseUnit
+		}
+	}
+
+	if intake.DrugName == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	if err := dao.DB.Create(&intake).Error; err != nil {
+		slog.Error(ErrCreateMedicationIntake.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrCreateMedicationIntake.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.Response{})
+}
+
+func DeleteMedicationIntake(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 32)
+
+	email := c.GetString("email")
+	if err := dao.DeleteMedicationIntake(email, uint(id)); err != nil {
+		slog.Error(ErrDeleteMedicationIntake.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrDeleteMedicationIntake.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{})
+}
+
+func GetMedicationAdherence(c *gin.Context) {
+	email := c.GetString("email")
+	startStr := c.Query("start")
+	endStr := c.Query("end")
+
+	start, end, err := utils.ValidateTimeRange(startStr, endStr, "UTC")
+	if err != nil {
+		slog.Error(err.Error(),
+			"start", startStr,
+			"end", endStr)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	stats, err := dao.GetMedicationAdherenceStats(email, start, end)
+	if err != nil {
+		slog.Error(ErrGetMedicationAdherence.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetMedicationAdherence.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: stats,
+	})
+}
+
+func convertMedicationPlanRequestToModel(req request.MedicationPlanRequest, email string) model.MedicationPlan {
+	return model.MedicationPlan{
+		UserEmail:     email,
+		DrugName:      req.DrugName,
+		DrugType:      req.DrugType,
+		Dose:          req.Dose,
+		DoseUnit:      req.DoseUnit,
+		ScheduleTimes: req.ScheduleTimes,
+		StartDate:     req.StartDate,
+		EndDate:       req.EndDate,
+		Notes:         req.Notes,
+	}
+}
@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/request"
+	"diabetes-agent-server/response"
+	"diabetes-agent-server/utils"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetVitalRecords(c *gin.Context) {
+	email := c.GetString("email")
+	startStr := c.Query("start")
+	endStr := c.Query("end")
+
+	start, end, err := utils.ValidateTimeRange(startStr, endStr, "UTC")
+	if err != nil {
+		slog.Error(err.Error(),
+			"start", startStr,
+			"end", endStr)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	records, err := dao.GetVitalRecords(email, start, end)
+	if err != nil {
+		slog.Error(ErrGetVitalRecords.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetVitalRecords.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: records,
+	})
+}
+
+func GetVitalStats(c *gin.Context) {
+	email := c.GetString("email")
+	startStr := c.Query("start")
+	endStr := c.Query("end")
+
+	start, end, err := utils.ValidateTimeRange(startStr, endStr, "UTC")
+	if err != nil {
+		slog.Error(err.Error(),
+			"start", startStr,
+			"end", endStr)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	records, err := dao.GetVitalRecords(email, start, end)
+	if err != nil {
+		slog.Error(ErrGetVitalRecords.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrGetVitalRecords.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Data: dao.GetVitalStats(records),
+	})
+}
+
+func CreateVitalRecord(c *gin.Context) {
+	var req request.VitalRecordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error(ErrParseRequest.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	// 至少记录一项指标，且收缩压和舒张压需同时填写
+	isEmpty := req.Weight == nil && req.Systolic == nil && req.Diastolic == nil &&
+		req.HeartRate == nil && req.Waist == nil
+	if isEmpty || (req.Systolic == nil) != (req.Diastolic == nil) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{
+			Msg: ErrParseRequest.Error(),
+		})
+		return
+	}
+
+	email := c.GetString("email")
+	record := model.VitalRecord{
+		UserEmail:  email,
+		MeasuredAt: req.MeasuredAt,
+		Weight:     req.Weight,
+		Systolic:   req.Systolic,
+		Diastolic:  req.Diastolic,
+		HeartRate:  req.HeartRate,
+		Waist:      req.Waist,
+		Notes:      req.Notes,
+	}
+	if err := dao.DB.Create(&record).Error; err != nil {
+		slog.Error(ErrCreateVitalRecord.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrCreateVitalRecord.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.Response{})
+}
+
+func DeleteVitalRecord(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 32)
+
+	email := c.GetString("email")
+	if err := dao.DeleteVitalRecord(email, uint(id)); err != nil {
+		slog.Error(ErrDeleteVitalRecord.Error(), "err", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+			Msg: ErrDeleteVitalRecord.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{})
+}
@@ -0,0 +1,33 @@
+package dao
+
+import (
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/response"
+)
+
+func CreateAccessAuditLog(log *model.AccessAuditLog) error {
+	return DB.Create(log).Error
+}
+
+// GetAccessAuditLogs 分页获取患者数据的访问记录
+func GetAccessAuditLogs(patientEmail string, page int) (*response.GetAccessAuditLogsResponse, error) {
+	var logs response.GetAccessAuditLogsResponse
+	err := DB.Model(&model.AccessAuditLog{}).
+		Select("id, created_at, actor_email, actor_role, scope, method, path, ip, status_code").
+		Where("patient_email = ?", patientEmail).
+		Order("created_at DESC").
+		Count(&logs.Total).
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&logs.Logs).Error
+	return &logs, err
+}
+
+// GetAllAccessAuditLogs 获取患者数据的所有访问记录
+func GetAllAccessAuditLogs(patientEmail string) ([]model.AccessAuditLog, error) {
+	var logs []model.AccessAuditLog
+	err := DB.Where("patient_email = ?", patientEmail).
+		Order("id ASC").
+		Find(&logs).Error
+	return logs, err
+}
@@ -0,0 +1,300 @@
+package dao
+
+import (
+	"context"
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/response"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Redis SCAN 每批返回的 key 数量
+const redisScanCount = 500
+
+// 按 glob 规则转义邮箱中的特殊字符
+var redisGlobEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`*`, `\*`,
+	`?`, `\?`,
+	`[`, `\[`,
+	`]`, `\]`,
+)
+
+// 用户数据所在的表及筛选条件
+type userDataTable struct {
+	model any
+	query string
+	args  []any
+}
+
+func CreateAccountDeletionJob(job *model.AccountDeletionJob) error {
+	return DB.Create(job).Error
+}
+
+// GetPendingAccountDeletionJob 获取用户处于冷静期的注销任务，不存在时返回 nil
+func GetPendingAccountDeletionJob(email string) (*model.AccountDeletionJob, error) {
+	var job model.AccountDeletionJob
+	err := DB.Where("user_email = ? AND status = ?", email, model.AccountDeletionStatusPending).
+		Order("id DESC").
+		First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &job, err
+}
+
+// CancelAccountDeletionJob 撤销冷静期内的注销任务，返回 false 表示任务已开始执行或不存在
+func CancelAccountDeletionJob(email string, id uint) (bool, error) {
+	result := DB.Model(&model.AccountDeletionJob{}).
+		Where("id = ? AND user_email = ? AND status = ?", id, email, model.AccountDeletionStatusPending).
+		Update("status", model.AccountDeletionStatusCanceled)
+	return result.RowsAffected > 0, result.Error
+}
+
+func GetAccountDeletionJobByID(id uint) (*model.AccountDeletionJob, error) {
+	var job model.AccountDeletionJob
+	err := DB.Where("id = ?", id).
+		First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &job, err
+}
+
+// GetAccountDeletionJobs 分页查询注销任务，status 为空时不过滤状态
+func GetAccountDeletionJobs(status string, page int) (*response.GetAccountDeletionJobsResponse, error) {
+	db := DB.Model(&model.AccountDeletionJob{})
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+
+	var jobs response.GetAccountDeletionJobsResponse
+	err := db.Select("id, created_at, updated_at, user_email, status, scheduled_at, attempts, last_error, completed_at").
+		Order("id DESC").
+		Count(&jobs.Total).
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&jobs.Jobs).Error
+	return &jobs, err
+}
+
+// GetDueAccountDeletionJobs 获取冷静期已结束、执行失败及执行超时的任务，用于投递 MQ 消息。
+// 执行次数达到 maxAttempts 的任务不再投递，需人工处理
+func GetDueAccountDeletionJobs(now, staleBefore time.Time, maxAttempts int) ([]model.AccountDeletionJob, error) {
+	var jobs []model.AccountDeletionJob
+	err := DB.Where("attempts < ? AND ((status = ? AND scheduled_at <= ?) OR status = ? OR (status = ? AND updated_at < ?))",
+		maxAttempts,
+		model.AccountDeletionStatusPending, now,
+		model.AccountDeletionStatusFailed,
+		model.AccountDeletionStatusProcessing, staleBefore).
+		Order("id ASC").
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// ClaimAccountDeletionJob 领取注销任务并增加执行次数，仅冷静期已结束、失败或执行超时且执行次数未达上限的任务可被领取，
+// 返回 false 表示任务已撤销、已完成、正由其他消费者执行或已达执行次数上限
+func ClaimAccountDeletionJob(id uint, now, staleBefore time.Time, maxAttempts int) (bool, error) {
+	result := DB.Model(&model.AccountDeletionJob{}).
+		Where("id = ? AND attempts < ? AND ((status = ? AND scheduled_at <= ?) OR status = ? OR (status = ? AND updated_at < ?))",
+			id, maxAttempts,
+			model.AccountDeletionStatusPending, now,
+			model.AccountDeletionStatusFailed,
+			model.AccountDeletionStatusProcessing, staleBefore,
+		).
+		Updates(map[string]any{
+			"status":   model.AccountDeletionStatusProcessing,
+			"attempts": gorm.Expr("attempts + 1"),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func FailAccountDeletionJob(id uint, errMsg string, report *model.AccountDeletionReport) error {
+	return DB.Model(&model.AccountDeletionJob{ID: id}).
+		Select("status", "last_error", "report").
+		Updates(&model.AccountDeletionJob{
+			Status:    model.AccountDeletionStatusFailed,
+			LastError: errMsg,
+			Report:    report,
+		}).Error
+}
+
+// AbortAccountDeletionJob 终止注销任务，不再执行删除
+func AbortAccountDeletionJob(id uint, reason string) error {
+	return DB.Model(&model.AccountDeletionJob{ID: id}).
+		Select("status", "last_error").
+		Updates(&model.AccountDeletionJob{
+			Status:    model.AccountDeletionStatusCanceled,
+			LastError: reason,
+		}).Error
+}
+
+func CompleteAccountDeletionJob(id uint, report *model.AccountDeletionReport) error {
+	now := time.Now()
+	return DB.Model(&model.AccountDeletionJob{ID: id}).
+		Select("status", "last_error", "report", "completed_at").
+		Updates(&model.AccountDeletionJob{
+			Status:      model.AccountDeletionStatusCompleted,
+			Report:      report,
+			CompletedAt: &now,
+		}).Error
+}
+
+// DeleteUserData 在事务中删除用户在所有表中的数据，返回各表删除的记录数
+func DeleteUserData(email string) (map[string]int64, error) {
+	deleted := make(map[string]int64)
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		tables, err := userDataTables(tx, email)
+		if err != nil {
+			return err
+		}
+
+		for _, table := range tables {
+			result := tx.Where(table.query, table.args...).Delete(table.model)
+			if result.Error != nil {
+				return fmt.Errorf("failed to delete %s: %v", tableName(tx, table.model), result.Error)
+			}
+			deleted[tableName(tx, table.model)] = result.RowsAffected
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// CountUserData 统计用户在所有表中剩余的记录数，用于删除后复查
+func CountUserData(email string) (map[string]int64, error) {
+	tables, err := userDataTables(DB, email)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(tables))
+	for _, table := range tables {
+		var count int64
+		err := DB.Model(table.model).
+			Where(table.query, table.args...).
+			Count(&count).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s: %v", tableName(DB, table.model), err)
+		}
+		counts[tableName(DB, table.model)] = count
+	}
+	return counts, nil
+}
+
+// 返回用户数据所在的表，会话关联的表在会话之前删除，用户表最后删除。
+// 访问审计日志仅删除用户作为患者被访问的记录，用户作为看护人/医生的访问记录属于对应患者，予以保留
+func userDataTables(db *gorm.DB, email string) ([]userDataTable, error) {
+	var sessionIDs []string
+	err := db.Model(&model.Session{}).
+		Where("user_email = ?", email).
+		Pluck("session_id", &sessionIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session ids: %v", err)
+	}
+
+	byEmail := func(m any) userDataTable {
+		return userDataTable{model: m, query: "user_email = ?", args: []any{email}}
+	}
+	bySession := func(m any) userDataTable {
+		return userDataTable{model: m, query: "session_id IN ?", args: []any{sessionIDs}}
+	}
+
+	return []userDataTable{
+		bySession(&model.Message{}),
+		bySession(&model.InterMediateSteps{}),
+		bySession(&model.ToolCallResults{}),
+		bySession(&model.ChatUploadedFile{}),
+		byEmail(&model.Session{}),
+		byEmail(&model.KnowledgeMetadata{}),
+		byEmail(&model.HealthProfile{}),
+		byEmail(&model.HealthProfileHistory{}),
+		byEmail(&model.BloodGlucoseRecord{}),
+		byEmail(&model.ExerciseRecord{}),
+		byEmail(&model.MealRecord{}),
+		byEmail(&model.VitalRecord{}),
+		byEmail(&model.LabResult{}),
+		byEmail(&model.MedicationPlan{}),
+		byEmail(&model.MedicationIntake{}),
+		byEmail(&model.AlertRule{}),
+		byEmail(&model.HealthWeeklyReport{}),
+		byEmail(&model.HealthReportJob{}),
+		byEmail(&model.DataExportJob{}),
+		byEmail(&model.SystemMessage{}),
+		byEmail(&model.LLMUsage{}),
+		byEmail(&model.RefreshToken{}),
+		{
+			model: &model.CareShare{},
+			query: "patient_email = ? OR grantee_email = ?",
+			args:  []any{email, email},
+		},
+		{
+			model: &model.AccessAuditLog{},
+			query: "patient_email = ?",
+			args:  []any{email},
+		},
+		{
+			model: &model.User{},
+			query: "email = ?",
+			args:  []any{email},
+		},
+	}, nil
+}
+
+func tableName(db *gorm.DB, m any) string {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(m); err != nil {
+		return fmt.Sprintf("%T", m)
+	}
+	return stmt.Schema.Table
+}
+
+// DeleteUserRedisKeys 删除以 user:{email}: 为前缀的所有 Redis key，返回删除的数量
+func DeleteUserRedisKeys(ctx context.Context, email string) (int64, error) {
+	var deleted int64
+	iter := RedisClient.Scan(ctx, 0, userRedisKeyPattern(email), redisScanCount).Iterator()
+	keys := make([]string, 0, redisScanCount)
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) < redisScanCount {
+			continue
+		}
+		n, err := RedisClient.Del(ctx, keys...).Result()
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+		keys = keys[:0]
+	}
+	if err := iter.Err(); err != nil {
+		return deleted, err
+	}
+
+	if len(keys) > 0 {
+		n, err := RedisClient.Del(ctx, keys...).Result()
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+	}
+	return deleted, nil
+}
+
+// CountUserRedisKeys 统计以 user:{email}: 为前缀的 Redis key 数量
+func CountUserRedisKeys(ctx context.Context, email string) (int64, error) {
+	var count int64
+	iter := RedisClient.Scan(ctx, 0, userRedisKeyPattern(email), redisScanCount).Iterator()
+	for iter.Next(ctx) {
+		count++
+	}
+	return count, iter.Err()
+}
+
+// 用户相关的 Redis key 均以 user:{email}: 为前缀，见 constants 包
+func userRedisKeyPattern(email string) string {
+	return "user:" + redisGlobEscaper.Replace(email) + ":*"
+}
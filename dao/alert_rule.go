@@ -0,0 +1,59 @@
+package dao
+
+import (
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/response"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+func GetAlertRules(email string) ([]response.GetAlertRulesResponse, error) {
+	var rules []response.GetAlertRulesResponse
+	err := DB.Model(&model.AlertRule{}).
+		Select("id, type, threshold, consecutive_count, cooldown_minutes, notify_email, enabled").
+		Where("user_email = ?", email).
+		Order("id ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+// GetEnabledAlertRules 获取用户已启用的提醒规则，用于规则评估
+func GetEnabledAlertRules(email string) ([]model.AlertRule, error) {
+	var rules []model.AlertRule
+	err := DB.Where("user_email = ? AND enabled = ?", email, true).
+		Order("id ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+// CountAlertRules 统计用户配置的提醒规则数（含未启用的规则）
+func CountAlertRules(email string) (int64, error) {
+	var count int64
+	err := DB.Model(&model.AlertRule{}).
+		Where("user_email = ?", email).
+		Count(&count).Error
+	return count, err
+}
+
+func GetAlertRule(email string, id uint) (*model.AlertRule, error) {
+	var rule model.AlertRule
+	err := DB.Where("id = ? AND user_email = ?", id, email).
+		First(&rule).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &rule, err
+}
+
+func UpdateAlertRule(rule model.AlertRule) error {
+	return DB.Model(&model.AlertRule{}).
+		Where("id = ? AND user_email = ?", rule.ID, rule.UserEmail).
+		Select("type", "threshold", "consecutive_count", "cooldown_minutes", "notify_email", "enabled").
+		Updates(rule).Error
+}
+
+func DeleteAlertRule(email string, id uint) error {
+	return DB.Where("id = ? AND user_email = ?", id, email).
+		Delete(&model.AlertRule{}).Error
+}
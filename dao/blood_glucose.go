@@ -3,7 +3,10 @@ package dao
 import (
 	"diabetes-agent-server/model"
 	"diabetes-agent-server/response"
+	"errors"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type BloodGlucoseStats struct {
@@ -16,13 +19,52 @@ type BloodGlucoseStats struct {
 func GetBloodGlucoseRecords(email string, start, end time.Time) ([]response.GetBloodGlucoseRecordsResponse, error) {
 	var records []response.GetBloodGlucoseRecordsResponse
 	err := DB.Model(&model.BloodGlucoseRecord{}).
-		Select("value, measured_at, dining_status").
+		Select("id, value, measured_at, dining_status, notes, source").
 		Where("user_email = ? AND measured_at BETWEEN ? AND ?", email, start, end).
 		Order("measured_at ASC").
 		Find(&records).Error
 	return records, err
 }
 
+func GetBloodGlucoseRecord(email string, id uint) (*model.BloodGlucoseRecord, error) {
+	var record model.BloodGlucoseRecord
+	err := DB.Where("id = ? AND user_email = ?", id, email).
+		First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &record, err
+}
+
+func UpdateBloodGlucoseRecord(record model.BloodGlucoseRecord) error {
+	return DB.Model(&model.BloodGlucoseRecord{}).
+		Where("id = ? AND user_email = ?", record.ID, record.UserEmail).
+		Select("value", "measured_at", "dining_status", "notes").
+		Updates(record).Error
+}
+
+func DeleteBloodGlucoseRecord(email string, id uint) error {
+	return DB.Where("id = ? AND user_email = ?", id, email).
+		Delete(&model.BloodGlucoseRecord{}).Error
+}
+
+// GetLatestBloodGlucoseRecords 获取指定时间范围内最近的若干条血糖记录，按测量时间升序返回
+func GetLatestBloodGlucoseRecords(email string, start, end time.Time, limit int) ([]model.BloodGlucoseRecord, error) {
+	var records []model.BloodGlucoseRecord
+	err := DB.Where("user_email = ? AND measured_at BETWEEN ? AND ?", email, start, end).
+		Order("measured_at DESC").
+		Limit(limit).
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+	return records, nil
+}
+
 // GetBloodGlucoseStats 获取指定时间范围内的血糖统计信息
 func GetBloodGlucoseStats(email string, start, end time.Time) (*BloodGlucoseStats, error) {
 	var stats BloodGlucoseStats
@@ -32,3 +74,12 @@ func GetBloodGlucoseStats(email string, start, end time.Time) (*BloodGlucoseStat
 		Take(&stats).Error
 	return &stats, err
 }
+
+// GetBloodGlucoseMeasuredTimes 获取指定时间范围内已有血糖记录的测量时间，用于导入去重
+func GetBloodGlucoseMeasuredTimes(email string, start, end time.Time) ([]time.Time, error) {
+	var measuredTimes []time.Time
+	err := DB.Model(&model.BloodGlucoseRecord{}).
+		Where("user_email = ? AND measured_at BETWEEN ? AND ?", email, start, end).
+		Pluck("measured_at", &measuredTimes).Error
+	return measuredTimes, err
+}
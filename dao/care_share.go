@@ -0,0 +1,63 @@
+package dao
+
+import (
+	"diabetes-agent-server/model"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// GetCareSharesByPatient 获取患者创建的所有授权
+func GetCareSharesByPatient(patientEmail string) ([]model.CareShare, error) {
+	var shares []model.CareShare
+	err := DB.Where("patient_email = ?", patientEmail).
+		Order("created_at DESC").
+		Find(&shares).Error
+	return shares, err
+}
+
+// GetCareSharesByGrantee 获取授权给看护人/医生的所有患者授权
+func GetCareSharesByGrantee(granteeEmail string) ([]model.CareShare, error) {
+	var shares []model.CareShare
+	err := DB.Where("grantee_email = ?", granteeEmail).
+		Order("created_at DESC").
+		Find(&shares).Error
+	return shares, err
+}
+
+// GetCareShare 获取患者对被授权人的授权，不存在时返回 nil
+func GetCareShare(patientEmail, granteeEmail string) (*model.CareShare, error) {
+	var share model.CareShare
+	err := DB.Where("patient_email = ? AND grantee_email = ?", patientEmail, granteeEmail).
+		First(&share).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &share, err
+}
+
+// GetCareShareByID 获取患者的指定授权，不存在时返回 nil
+func GetCareShareByID(patientEmail string, id uint) (*model.CareShare, error) {
+	var share model.CareShare
+	err := DB.Where("id = ? AND patient_email = ?", id, patientEmail).
+		First(&share).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &share, err
+}
+
+func CreateCareShare(share *model.CareShare) error {
+	return DB.Create(share).Error
+}
+
+func UpdateCareShare(share *model.CareShare) error {
+	return DB.Model(share).
+		Select("scopes", "expires_at").
+		Updates(share).Error
+}
+
+func DeleteCareShare(patientEmail string, id uint) error {
+	return DB.Where("id = ? AND patient_email = ?", id, patientEmail).
+		Delete(&model.CareShare{}).Error
+}
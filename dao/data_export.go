@@ -0,0 +1,112 @@
+package dao
+
+import (
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/response"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func CreateDataExportJob(job *model.DataExportJob) error {
+	return DB.Create(job).Error
+}
+
+// GetUnfinishedDataExportJob 获取用户等待中或导出中的任务，不存在时返回 nil
+func GetUnfinishedDataExportJob(email string) (*model.DataExportJob, error) {
+	var job model.DataExportJob
+	err := DB.Where("user_email = ? AND status IN ?", email,
+		[]string{model.DataExportStatusPending, model.DataExportStatusProcessing}).
+		Order("id DESC").
+		First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &job, err
+}
+
+func GetDataExportJobs(email string) ([]response.GetDataExportJobsResponse, error) {
+	var jobs []response.GetDataExportJobsResponse
+	err := DB.Model(&model.DataExportJob{}).
+		Select("id, created_at, updated_at, status, error, file_name").
+		Where("user_email = ?", email).
+		Order("id DESC").
+		Find(&jobs).Error
+	return jobs, err
+}
+
+func GetDataExportJob(email string, id uint) (*model.DataExportJob, error) {
+	var job model.DataExportJob
+	err := DB.Where("id = ? AND user_email = ?", id, email).
+		First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &job, err
+}
+
+func GetDataExportJobByID(id uint) (*model.DataExportJob, error) {
+	var job model.DataExportJob
+	err := DB.Where("id = ?", id).
+		First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &job, err
+}
+
+// ClaimDataExportJob 领取导出任务，仅等待中、失败或执行超时的任务可被领取，
+// 返回 false 表示任务已完成或正由其他消费者执行
+func ClaimDataExportJob(id uint, staleBefore time.Time) (bool, error) {
+	result := DB.Model(&model.DataExportJob{}).
+		Where("id = ? AND (status IN ? OR (status = ? AND updated_at < ?))",
+			id,
+			[]string{model.DataExportStatusPending, model.DataExportStatusFailed},
+			model.DataExportStatusProcessing,
+			staleBefore,
+		).
+		Update("status", model.DataExportStatusProcessing)
+	return result.RowsAffected > 0, result.Error
+}
+
+func FailDataExportJob(id uint, errMsg string) error {
+	return DB.Model(&model.DataExportJob{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status": model.DataExportStatusFailed,
+			"error":  errMsg,
+		}).Error
+}
+
+func CompleteDataExportJob(id uint, fileName, objectName string) error {
+	return DB.Model(&model.DataExportJob{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":      model.DataExportStatusCompleted,
+			"error":       "",
+			"file_name":   fileName,
+			"object_name": objectName,
+		}).Error
+}
+
+// GetUserRecords 获取用户在 T 对应表中的所有记录，T 对应的表需包含 user_email 列
+func GetUserRecords[T any](email string) ([]T, error) {
+	var records []T
+	err := DB.Where("user_email = ?", email).
+		Order("id ASC").
+		Find(&records).Error
+	return records, err
+}
+
+// GetSessionRecords 获取会话在 T 对应表中的所有记录，T 对应的表需包含 session_id 列
+func GetSessionRecords[T any](sessionIDs []string) ([]T, error) {
+	records := []T{}
+	if len(sessionIDs) == 0 {
+		return records, nil
+	}
+	err := DB.Where("session_id IN ?", sessionIDs).
+		Order("id ASC").
+		Find(&records).Error
+	return records, err
+}
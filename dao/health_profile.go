@@ -4,14 +4,37 @@ import (
 	"diabetes-agent-server/model"
 	"diabetes-agent-server/response"
 	"errors"
+	"reflect"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+const (
+	// 统计档案变更前后平均血糖的时间窗口
+	changeImpactWindow = 30 * 24 * time.Hour
+)
+
+// 不纳入变更记录的字段
+var untrackedProfileFields = map[string]bool{
+	"ID":        true,
+	"CreatedAt": true,
+	"UpdatedAt": true,
+	"UserEmail": true,
+}
+
+// 与治疗方案相关的字段，用于分析变更前后的血糖变化
+var treatmentProfileFields = map[string]bool{
+	"diabetes_type": true,
+	"therapy_mode":  true,
+	"medication":    true,
+}
+
 func GetHealthProfile(email string) (*response.GetHealthProfileResponse, error) {
 	var profile response.GetHealthProfileResponse
 	err := DB.Model(&model.HealthProfile{}).
-		Select("gender, age, height, weight, dietary_preference, smoking_status, activity_level, diabetes_type, diagnosis_year, therapy_mode, medication, allergies, complications").
+		Select("gender, age, height, weight, dietary_preference, smoking_status, activity_level, diabetes_type, diagnosis_year, therapy_mode, medication, allergies, complications, glucose_very_low, glucose_low, glucose_high, glucose_very_high").
 		Where("user_email = ?", email).
 		First(&profile).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -20,8 +43,200 @@ func GetHealthProfile(email string) (*response.GetHealthProfileResponse, error)
 	return &profile, err
 }
 
-func UpdateHealthProfile(profile model.HealthProfile) error {
-	return DB.Model(&model.HealthProfile{}).
-		Where("user_email = ?", profile.UserEmail).
-		Updates(profile).Error
+// CreateHealthProfile 创建健康档案，并记录为第 1 个版本
+func CreateHealthProfile(profile model.HealthProfile) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&profile).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&model.HealthProfileHistory{
+			UserEmail: profile.UserEmail,
+			Version:   1,
+			Changes:   diffHealthProfile(model.HealthProfile{}, profile),
+		}).Error
+	})
+}
+
+// UpdateHealthProfile 使用 apply 修改已有档案，仅更新发生变化的字段，档案有变化时记录新版本；
+// 档案不存在时返回 gorm.ErrRecordNotFound，apply 返回错误时不更新档案并返回该错误
+func UpdateHealthProfile(email string, apply func(profile *model.HealthProfile) error) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var existing model.HealthProfile
+		if err := tx.Where("user_email = ?", email).
+			First(&existing).Error; err != nil {
+			return err
+		}
+
+		profile := existing
+		if err := apply(&profile); err != nil {
+			return err
+		}
+
+		changes := diffHealthProfile(existing, profile)
+		if len(changes) == 0 {
+			return nil
+		}
+
+		// 仅更新变化的字段，允许将字段更新为零值
+		columns := make([]string, 0, len(changes))
+		for _, change := range changes {
+			columns = append(columns, change.Field)
+		}
+		err := tx.Model(&existing).
+			Select(columns).
+			Updates(&profile).Error
+		if err != nil {
+			return err
+		}
+
+		var version int
+		err = tx.Model(&model.HealthProfileHistory{}).
+			Select("COALESCE(MAX(version), 0)").
+			Where("user_email = ?", email).
+			Scan(&version).Error
+		if err != nil {
+			return err
+		}
+
+		// 早于变更记录功能创建的档案没有历史版本，将其视为第 1 个版本
+		if version == 0 {
+			version = 1
+		}
+
+		return tx.Create(&model.HealthProfileHistory{
+			UserEmail: email,
+			Version:   version + 1,
+			Changes:   changes,
+		}).Error
+	})
+}
+
+// GetHealthProfileHistory 获取健康档案的变更记录，按版本倒序排列
+func GetHealthProfileHistory(email string) ([]response.GetHealthProfileHistoryResponse, error) {
+	var histories []model.HealthProfileHistory
+	err := DB.Where("user_email = ?", email).
+		Order("version DESC").
+		Find(&histories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]response.GetHealthProfileHistoryResponse, 0, len(histories))
+	for _, h := range histories {
+		records = append(records, response.GetHealthProfileHistoryResponse{
+			Version:   h.Version,
+			ChangedAt: h.CreatedAt,
+			Changes:   h.Changes,
+		})
+	}
+	return records, nil
+}
+
+// GetHealthProfileChangeImpacts 获取 since 之后治疗相关的档案变更，
+// 并统计变更前 30 天与变更后 30 天（遇到下一次治疗变更时截止）的平均血糖
+func GetHealthProfileChangeImpacts(email string, since time.Time) ([]response.HealthProfileChangeImpact, error) {
+	// 首个版本为创建档案，不视为变更
+	var histories []model.HealthProfileHistory
+	err := DB.Where("user_email = ? AND version > 1 AND created_at >= ?", email, since).
+		Order("version ASC").
+		Find(&histories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var treatmentChanges []model.HealthProfileHistory
+	for _, h := range histories {
+		var changes []model.HealthProfileChange
+		for _, c := range h.Changes {
+			if treatmentProfileFields[c.Field] {
+				changes = append(changes, c)
+			}
+		}
+		if len(changes) > 0 {
+			h.Changes = changes
+			treatmentChanges = append(treatmentChanges, h)
+		}
+	}
+
+	impacts := make([]response.HealthProfileChangeImpact, 0, len(treatmentChanges))
+	for i, h := range treatmentChanges {
+		afterEnd := h.CreatedAt.Add(changeImpactWindow)
+		if i+1 < len(treatmentChanges) {
+			afterEnd = minTime(afterEnd, treatmentChanges[i+1].CreatedAt)
+		}
+		afterEnd = minTime(afterEnd, time.Now().UTC())
+
+		avgBefore, countBefore, err := getAverageGlucose(email, h.CreatedAt.Add(-changeImpactWindow), h.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		avgAfter, countAfter, err := getAverageGlucose(email, h.CreatedAt, afterEnd)
+		if err != nil {
+			return nil, err
+		}
+
+		impacts = append(impacts, response.HealthProfileChangeImpact{
+			ChangedAt:        h.CreatedAt,
+			Changes:          h.Changes,
+			AvgGlucoseBefore: avgBefore,
+			AvgGlucoseAfter:  avgAfter,
+			ReadingsBefore:   countBefore,
+			ReadingsAfter:    countAfter,
+		})
+	}
+
+	return impacts, nil
+}
+
+// 获取时间范围内的平均血糖，无记录时返回 nil
+func getAverageGlucose(email string, start, end time.Time) (*float32, int, error) {
+	var result struct {
+		Avg   float32
+		Count int
+	}
+	err := DB.Model(&model.BloodGlucoseRecord{}).
+		Select("COALESCE(AVG(value), 0) as avg, COUNT(*) as count").
+		Where("user_email = ? AND measured_at BETWEEN ? AND ?", email, start, end).
+		Take(&result).Error
+	if err != nil || result.Count == 0 {
+		return nil, 0, err
+	}
+	return &result.Avg, result.Count, nil
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// 对比两个版本的健康档案，返回发生变化的字段
+func diffHealthProfile(prev, curr model.HealthProfile) []model.HealthProfileChange {
+	var changes []model.HealthProfileChange
+
+	oldValue := reflect.ValueOf(prev)
+	newValue := reflect.ValueOf(curr)
+	profileType := oldValue.Type()
+	for i := 0; i < profileType.NumField(); i++ {
+		field := profileType.Field(i)
+		if untrackedProfileFields[field.Name] {
+			continue
+		}
+
+		o := oldValue.Field(i).Interface()
+		n := newValue.Field(i).Interface()
+		if reflect.DeepEqual(o, n) {
+			continue
+		}
+
+		changes = append(changes, model.HealthProfileChange{
+			Field:    strings.Split(field.Tag.Get("json"), ",")[0],
+			OldValue: o,
+			NewValue: n,
+		})
+	}
+
+	return changes
 }
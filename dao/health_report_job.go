@@ -0,0 +1,152 @@
+package dao
+
+import (
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/response"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateHealthReportJobs 为用户创建指定周期的报告任务，已存在的任务保持不变，
+// 重复触发定时任务时不会产生重复任务
+func CreateHealthReportJobs(jobs []model.HealthReportJob) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+	return DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&jobs).Error
+}
+
+// GetUnfinishedHealthReportJobs 获取指定周期未完成的任务，用于投递 MQ 消息
+func GetUnfinishedHealthReportJobs(periodType string, start time.Time) ([]model.HealthReportJob, error) {
+	var jobs []model.HealthReportJob
+	err := DB.Where("period_type = ? AND start_at = ? AND status <> ?",
+		periodType, start, model.ReportJobStatusCompleted).
+		Order("id ASC").
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// GetResumableHealthReportJobs 获取超时未消费及执行超时的任务，用于定时续跑消息丢失或执行中断的任务
+func GetResumableHealthReportJobs(staleBefore time.Time) ([]model.HealthReportJob, error) {
+	var jobs []model.HealthReportJob
+	err := DB.Where("status IN ? AND updated_at < ?",
+		[]string{model.ReportJobStatusPending, model.ReportJobStatusRunning}, staleBefore).
+		Order("id ASC").
+		Find(&jobs).Error
+	return jobs, err
+}
+
+func GetHealthReportJobs(email string) ([]response.GetHealthReportJobsResponse, error) {
+	var jobs []response.GetHealthReportJobsResponse
+	err := DB.Model(&model.HealthReportJob{}).
+		Select("id, period_type, start_at, end_at, status, attempts, last_error, decision, report_id, updated_at").
+		Where("user_email = ?", email).
+		Order("start_at DESC, id DESC").
+		Find(&jobs).Error
+	return jobs, err
+}
+
+func GetHealthReportJob(email string, id uint) (*model.HealthReportJob, error) {
+	var job model.HealthReportJob
+	err := DB.Where("id = ? AND user_email = ?", id, email).
+		First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &job, err
+}
+
+func GetHealthReportJobByID(id uint) (*model.HealthReportJob, error) {
+	var job model.HealthReportJob
+	err := DB.Where("id = ?", id).
+		First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &job, err
+}
+
+// GetHealthReportJobByPeriod 获取用户指定周期的任务，不存在时返回 nil
+func GetHealthReportJobByPeriod(email, periodType string, start time.Time) (*model.HealthReportJob, error) {
+	var job model.HealthReportJob
+	err := DB.Where("user_email = ? AND period_type = ? AND start_at = ?", email, periodType, start).
+		First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &job, err
+}
+
+// ClaimHealthReportJob 领取任务并累加执行次数，仅等待中、失败或执行超时的任务可被领取，
+// 返回 false 表示任务已完成或正由其他消费者执行
+func ClaimHealthReportJob(id uint, staleBefore time.Time) (bool, error) {
+	result := DB.Model(&model.HealthReportJob{}).
+		Where("id = ? AND (status IN ? OR (status = ? AND updated_at < ?))",
+			id,
+			[]string{model.ReportJobStatusPending, model.ReportJobStatusFailed},
+			model.ReportJobStatusRunning,
+			staleBefore,
+		).
+		Updates(map[string]any{
+			"status":   model.ReportJobStatusRunning,
+			"attempts": gorm.Expr("attempts + 1"),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func UpdateHealthReportJobStatus(id uint, status, lastError string) error {
+	return DB.Model(&model.HealthReportJob{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":     status,
+			"last_error": lastError,
+		}).Error
+}
+
+// CompleteHealthReportJob 记录数据充足性判断结果和生成的报告并标记任务完成，跳过报告时 reportID 为 nil
+func CompleteHealthReportJob(id uint, decision string, reportID *uint) error {
+	return DB.Model(&model.HealthReportJob{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":     model.ReportJobStatusCompleted,
+			"last_error": "",
+			"decision":   decision,
+			"report_id":  reportID,
+		}).Error
+}
+
+// ResetHealthReportJob 重置任务及对应周期的报告以便重新生成并通知，执行中的任务不可重置
+func ResetHealthReportJob(email string, id uint) (bool, error) {
+	reset := false
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.HealthReportJob{}).
+			Where("id = ? AND user_email = ? AND status <> ?", id, email, model.ReportJobStatusRunning).
+			Updates(map[string]any{
+				"status":     model.ReportJobStatusPending,
+				"attempts":   0,
+				"last_error": "",
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		reset = true
+
+		var job model.HealthReportJob
+		if err := tx.Where("id = ?", id).First(&job).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.HealthWeeklyReport{}).
+			Where("user_email = ? AND period_type = ? AND start_at = ? AND end_at = ?",
+				job.UserEmail, job.PeriodType, job.StartAt, job.EndAt).
+			Updates(map[string]any{
+				"status":      model.ReportStatusPending,
+				"error":       "",
+				"notified_at": nil,
+			}).Error
+	})
+	return reset, err
+}
@@ -3,13 +3,126 @@ package dao
 import (
 	"diabetes-agent-server/model"
 	"diabetes-agent-server/response"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// GetHealthWeeklyReports 获取已生成的健康周报
 func GetHealthWeeklyReports(email string) ([]response.GetHealthWeeklyReportsResponse, error) {
 	var reports []response.GetHealthWeeklyReportsResponse
 	err := DB.Model(&model.HealthWeeklyReport{}).
-		Where("user_email = ?", email).
+		Where("user_email = ? AND period_type = ? AND status = ?",
+			email, model.ReportPeriodWeekly, model.ReportStatusCompleted).
 		Order("start_at DESC").
 		Find(&reports).Error
 	return reports, err
 }
+
+// GetHealthReports 获取各周期的健康报告及生成状态，periodType 为空时返回全部周期
+func GetHealthReports(email, periodType string) ([]response.GetHealthReportsResponse, error) {
+	query := DB.Model(&model.HealthWeeklyReport{}).
+		Where("user_email = ?", email)
+	if periodType != "" {
+		query = query.Where("period_type = ?", periodType)
+	}
+
+	var reports []response.GetHealthReportsResponse
+	err := query.Select("id, created_at, period_type, start_at, end_at, status, error, file_name, pdf_file_name").
+		Order("start_at DESC, id DESC").
+		Find(&reports).Error
+	return reports, err
+}
+
+func GetHealthReportByID(id uint) (*model.HealthWeeklyReport, error) {
+	var report model.HealthWeeklyReport
+	err := DB.Where("id = ?", id).
+		First(&report).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &report, err
+}
+
+// GetHealthReportByRange 获取用户指定周期和时间范围的报告，不存在时返回 nil
+func GetHealthReportByRange(email, periodType string, start, end time.Time) (*model.HealthWeeklyReport, error) {
+	var report model.HealthWeeklyReport
+	err := DB.Where("user_email = ? AND period_type = ? AND start_at = ? AND end_at = ?",
+		email, periodType, start, end).
+		First(&report).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &report, err
+}
+
+// GetOrCreateHealthReport 获取用户指定周期的报告，不存在时创建，重新执行任务时复用已有报告
+func GetOrCreateHealthReport(email, periodType string, start, end time.Time) (*model.HealthWeeklyReport, error) {
+	report := model.HealthWeeklyReport{
+		UserEmail:  email,
+		PeriodType: periodType,
+		StartAt:    start,
+		EndAt:      end,
+	}
+	err := DB.Where("user_email = ? AND period_type = ? AND start_at = ? AND end_at = ?",
+		email, periodType, start, end).
+		Attrs(model.HealthWeeklyReport{Status: model.ReportStatusPending}).
+		FirstOrCreate(&report).Error
+	return &report, err
+}
+
+// CreateHealthReport 创建报告，相同周期和时间范围的报告已存在时不创建并返回 false
+func CreateHealthReport(report *model.HealthWeeklyReport) (bool, error) {
+	result := DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(report)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ClaimHealthReport 将报告标记为生成中，仅等待生成、失败或生成超时的报告可被领取，
+// 返回 false 表示报告已生成或正由其他消费者生成
+func ClaimHealthReport(id uint, staleBefore time.Time) (bool, error) {
+	result := DB.Model(&model.HealthWeeklyReport{}).
+		Where("id = ? AND (status IN ? OR (status = ? AND updated_at < ?))",
+			id,
+			[]string{model.ReportStatusPending, model.ReportStatusFailed},
+			model.ReportStatusGenerating,
+			staleBefore,
+		).
+		Updates(map[string]any{
+			"status": model.ReportStatusGenerating,
+			"error":  "",
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func UpdateHealthReportStatus(id uint, status, errMsg string) error {
+	return DB.Model(&model.HealthWeeklyReport{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status": status,
+			"error":  errMsg,
+		}).Error
+}
+
+// CompleteHealthReport 记录报告文件并标记为已生成，未生成 PDF 版本时 pdfFileName 为空
+func CompleteHealthReport(id uint, fileName, objectName, pdfFileName, pdfObjectName string) error {
+	return DB.Model(&model.HealthWeeklyReport{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":          model.ReportStatusCompleted,
+			"error":           "",
+			"file_name":       fileName,
+			"object_name":     objectName,
+			"pdf_file_name":   pdfFileName,
+			"pdf_object_name": pdfObjectName,
+		}).Error
+}
+
+// MarkHealthReportNotified 记录报告已通知用户
+func MarkHealthReportNotified(id uint) error {
+	return DB.Model(&model.HealthWeeklyReport{}).
+		Where("id = ?", id).
+		Update("notified_at", time.Now()).Error
+}
@@ -18,7 +18,14 @@ var (
 	RedisClient  *redis.Client
 )
 
-func init() {
+// Init 连接 MySQL、Milvus 和 Redis，需在加载配置后调用
+func Init() {
+	initMySQL()
+	initMilvus()
+	initRedis()
+}
+
+func initMySQL() {
 	var err error
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
 		config.Cfg.DB.MySQL.Username,
@@ -33,7 +40,7 @@ func init() {
 	}
 }
 
-func init() {
+func initMilvus() {
 	var err error
 	MilvusClient, err = milvusclient.New(context.Background(), &milvusclient.ClientConfig{
 		Address: config.Cfg.Milvus.Endpoint,
@@ -44,7 +51,7 @@ func init() {
 	}
 }
 
-func init() {
+func initRedis() {
 	RedisClient = redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%s", config.Cfg.Redis.Host, config.Cfg.Redis.Port),
 		Password: config.Cfg.Redis.Password,
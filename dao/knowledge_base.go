@@ -41,6 +41,13 @@ func UpdateKnowledgeMetadataStatus(email, fileName string, status model.Status)
 		Update("status", status).Error
 }
 
+// ResetFailedKnowledgeMetadata 将向量化失败的文件重置为已上传状态，文件已被重新处理时不更新
+func ResetFailedKnowledgeMetadata(email, fileName string) error {
+	return DB.Model(&model.KnowledgeMetadata{}).
+		Where("user_email = ? AND file_name = ? AND status = ?", email, fileName, model.StatusProcessedFailed).
+		Update("status", model.StatusUploaded).Error
+}
+
 func SearchKnowledgeMetadataByFullText(email, query string) ([]response.MetadataResponse, error) {
 	var fileMetadata []response.MetadataResponse
 
@@ -53,3 +60,26 @@ func SearchKnowledgeMetadataByFullText(email, query string) ([]response.Metadata
 
 	return fileMetadata, err
 }
+
+// GetFailedKnowledgeMetadata 获取向量化失败的知识文件，email 为空时返回所有用户的文件
+func GetFailedKnowledgeMetadata(email string) ([]model.KnowledgeMetadata, error) {
+	db := DB.Where("status = ?", model.StatusProcessedFailed)
+	if email != "" {
+		db = db.Where("user_email = ?", email)
+	}
+
+	var fileMetadata []model.KnowledgeMetadata
+	err := db.Order("updated_at DESC").
+		Find(&fileMetadata).Error
+	return fileMetadata, err
+}
+
+func GetKnowledgeMetadataByID(id uint) (*model.KnowledgeMetadata, error) {
+	var fileMetadata model.KnowledgeMetadata
+	err := DB.Where("id = ?", id).
+		First(&fileMetadata).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &fileMetadata, err
+}
@@ -0,0 +1,127 @@
+package dao
+
+import (
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/response"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GetLabResults 获取指定时间范围内的检验结果，kind 为空时返回全部检验项目
+func GetLabResults(email, kind string, start, end time.Time) ([]response.GetLabResultsResponse, error) {
+	query := DB.Where("user_email = ? AND tested_at BETWEEN ? AND ?", email, start, end)
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	var results []model.LabResult
+	if err := query.Order("tested_at ASC").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return convertLabResults(results), nil
+}
+
+// GetLatestLabResults 获取用户各检验项目的最近一次结果
+func GetLatestLabResults(email string) ([]response.GetLabResultsResponse, error) {
+	latest := DB.Model(&model.LabResult{}).
+		Select("kind, MAX(tested_at)").
+		Where("user_email = ?", email).
+		Group("kind")
+
+	var results []model.LabResult
+	err := DB.Where("user_email = ? AND (kind, tested_at) IN (?)", email, latest).
+		Order("kind ASC").
+		Find(&results).Error
+	if err != nil {
+		return nil, err
+	}
+	return convertLabResults(results), nil
+}
+
+// GetLabResultTrend 获取单个检验项目在指定时间范围内的变化趋势
+func GetLabResultTrend(email, kind string, start, end time.Time) (*response.GetLabResultTrendResponse, error) {
+	results, err := GetLabResults(email, kind, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	labKind := model.LabKinds[kind]
+	trend := response.GetLabResultTrendResponse{
+		Kind:   kind,
+		Name:   labKind.Name,
+		Unit:   labKind.Unit,
+		Points: make([]response.LabTrendPoint, 0, len(results)),
+	}
+	for _, r := range results {
+		trend.Points = append(trend.Points, response.LabTrendPoint{
+			TestedAt: r.TestedAt,
+			Value:    r.Value,
+			Flag:     r.Flag,
+		})
+	}
+
+	if n := len(results); n > 0 {
+		latest := results[n-1].Value
+		trend.Latest = &latest
+		if n > 1 {
+			change := latest - results[n-2].Value
+			trend.Change = &change
+		}
+	}
+
+	return &trend, nil
+}
+
+func GetLabResult(email string, id uint) (*model.LabResult, error) {
+	var result model.LabResult
+	err := DB.Where("id = ? AND user_email = ?", id, email).
+		First(&result).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &result, err
+}
+
+func UpdateLabResult(result model.LabResult) error {
+	return DB.Model(&model.LabResult{}).
+		Where("id = ? AND user_email = ?", result.ID, result.UserEmail).
+		Select("kind", "value", "unit", "reference_low", "reference_high", "tested_at", "notes").
+		Updates(result).Error
+}
+
+func DeleteLabResult(email string, id uint) error {
+	return DB.Where("id = ? AND user_email = ?", id, email).
+		Delete(&model.LabResult{}).Error
+}
+
+func convertLabResults(results []model.LabResult) []response.GetLabResultsResponse {
+	converted := make([]response.GetLabResultsResponse, 0, len(results))
+	for _, r := range results {
+		converted = append(converted, response.GetLabResultsResponse{
+			ID:            r.ID,
+			Kind:          r.Kind,
+			Name:          model.LabKinds[r.Kind].Name,
+			Value:         r.Value,
+			Unit:          r.Unit,
+			ReferenceLow:  r.ReferenceLow,
+			ReferenceHigh: r.ReferenceHigh,
+			TestedAt:      r.TestedAt,
+			Notes:         r.Notes,
+			Flag:          labResultFlag(r),
+		})
+	}
+	return converted
+}
+
+func labResultFlag(r model.LabResult) string {
+	switch {
+	case r.ReferenceLow != nil && r.Value < *r.ReferenceLow:
+		return response.LabFlagLow
+	case r.ReferenceHigh != nil && r.Value > *r.ReferenceHigh:
+		return response.LabFlagHigh
+	default:
+		return response.LabFlagNormal
+	}
+}
@@ -0,0 +1,39 @@
+package dao
+
+import (
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/response"
+	"time"
+)
+
+const (
+	LLMUsageGranularityDaily   = "daily"
+	LLMUsageGranularityMonthly = "monthly"
+)
+
+var llmUsagePeriodFormats = map[string]string{
+	LLMUsageGranularityDaily:   "%Y-%m-%d",
+	LLMUsageGranularityMonthly: "%Y-%m",
+}
+
+func CreateLLMUsage(usage *model.LLMUsage) error {
+	return DB.Create(usage).Error
+}
+
+// GetLLMUsage 按天或按月汇总用户在时间范围内的 token 用量
+func GetLLMUsage(email, granularity string, start, end time.Time) ([]response.GetLLMUsageResponse, error) {
+	period := "DATE_FORMAT(created_at, '" + llmUsagePeriodFormats[granularity] + "')"
+
+	var usages []response.GetLLMUsageResponse
+	err := DB.Model(&model.LLMUsage{}).
+		Select(period+" AS period, COUNT(*) AS calls, "+
+			"SUM(prompt_tokens) AS prompt_tokens, "+
+			"SUM(completion_tokens) AS completion_tokens, "+
+			"SUM(total_tokens) AS total_tokens, "+
+			"SUM(cost) AS cost").
+		Where("user_email = ? AND created_at BETWEEN ? AND ?", email, start, end).
+		Group("period").
+		Order("period ASC").
+		Find(&usages).Error
+	return usages, err
+}
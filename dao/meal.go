@@ -0,0 +1,148 @@
+package dao
+
+import (
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/response"
+	"time"
+)
+
+const (
+	// 餐前血糖的匹配窗口：进餐前 60 分钟内
+	preMealWindow = 60 * time.Minute
+
+	// 餐后血糖的匹配窗口：进餐后 60~180 分钟，优先选取最接近餐后 2 小时的记录
+	postMealWindowStart = 60 * time.Minute
+	postMealWindowEnd   = 180 * time.Minute
+	postMealTarget      = 120 * time.Minute
+
+	// 餐后血糖升幅达到该值(mmol/L)视为血糖飙升
+	GlucoseSpikeThreshold = 3.0
+)
+
+type MealStats struct {
+	Count             int     `json:"count"`
+	TotalCarbohydrate float32 `json:"total_carbohydrate"`
+	TotalCalories     float32 `json:"total_calories"`
+
+	// 有完整餐前/餐后血糖的记录中的平均升幅
+	AvgGlucoseRise float32 `json:"avg_glucose_rise"`
+
+	// 导致血糖飙升的进餐次数
+	SpikeCount int `json:"spike_count"`
+}
+
+// GetMealRecords 获取指定时间范围内的饮食记录，并按时间窗口关联餐前/餐后血糖
+func GetMealRecords(email string, start, end time.Time) ([]response.GetMealRecordsResponse, error) {
+	var meals []model.MealRecord
+	err := DB.Where("user_email = ? AND eaten_at BETWEEN ? AND ?", email, start, end).
+		Order("eaten_at ASC").
+		Find(&meals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]response.GetMealRecordsResponse, 0, len(meals))
+	if len(meals) == 0 {
+		return records, nil
+	}
+
+	// 扩展查询范围，覆盖首餐的餐前窗口和末餐的餐后窗口
+	glucoseRecords, err := GetBloodGlucoseRecords(email,
+		meals[0].EatenAt.Add(-preMealWindow),
+		meals[len(meals)-1].EatenAt.Add(postMealWindowEnd),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, meal := range meals {
+		record := response.GetMealRecordsResponse{
+			ID:           meal.ID,
+			MealType:     meal.MealType,
+			EatenAt:      meal.EatenAt,
+			Foods:        meal.Foods,
+			Carbohydrate: meal.Carbohydrate,
+			Calories:     meal.Calories,
+			Notes:        meal.Notes,
+			PreGlucose:   matchPreMealGlucose(glucoseRecords, meal.EatenAt),
+			PostGlucose:  matchPostMealGlucose(glucoseRecords, meal.EatenAt),
+		}
+		if record.PreGlucose != nil && record.PostGlucose != nil {
+			rise := *record.PostGlucose - *record.PreGlucose
+			record.GlucoseRise = &rise
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// 选取餐前窗口内距离进餐时间最近的血糖记录，glucoseRecords 需按测量时间升序排列
+func matchPreMealGlucose(glucoseRecords []response.GetBloodGlucoseRecordsResponse, eatenAt time.Time) *float32 {
+	var matched *float32
+	for i := range glucoseRecords {
+		measuredAt := glucoseRecords[i].MeasuredAt
+		if measuredAt.After(eatenAt) {
+			break
+		}
+		if !measuredAt.Before(eatenAt.Add(-preMealWindow)) {
+			matched = &glucoseRecords[i].Value
+		}
+	}
+	return matched
+}
+
+// 选取餐后窗口内最接近餐后 2 小时的血糖记录
+func matchPostMealGlucose(glucoseRecords []response.GetBloodGlucoseRecordsResponse, eatenAt time.Time) *float32 {
+	windowStart := eatenAt.Add(postMealWindowStart)
+	windowEnd := eatenAt.Add(postMealWindowEnd)
+	target := eatenAt.Add(postMealTarget)
+
+	var matched *float32
+	var minDiff time.Duration
+	for i := range glucoseRecords {
+		measuredAt := glucoseRecords[i].MeasuredAt
+		if measuredAt.Before(windowStart) || measuredAt.After(windowEnd) {
+			continue
+		}
+
+		diff := measuredAt.Sub(target).Abs()
+		if matched == nil || diff < minDiff {
+			matched = &glucoseRecords[i].Value
+			minDiff = diff
+		}
+	}
+	return matched
+}
+
+// GetMealStats 统计饮食记录的碳水、热量及餐后血糖升幅
+func GetMealStats(records []response.GetMealRecordsResponse) *MealStats {
+	stats := MealStats{Count: len(records)}
+
+	var totalRise float32
+	var riseCount int
+	for _, record := range records {
+		stats.TotalCarbohydrate += record.Carbohydrate
+		stats.TotalCalories += record.Calories
+
+		if record.GlucoseRise == nil {
+			continue
+		}
+		totalRise += *record.GlucoseRise
+		riseCount++
+		if *record.GlucoseRise >= GlucoseSpikeThreshold {
+			stats.SpikeCount++
+		}
+	}
+
+	if riseCount > 0 {
+		stats.AvgGlucoseRise = totalRise / float32(riseCount)
+	}
+
+	return &stats
+}
+
+func DeleteMealRecord(email string, id uint) error {
+	return DB.Where("id = ? AND user_email = ?", id, email).
+		Delete(&model.MealRecord{}).Error
+}
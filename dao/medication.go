@@ -0,0 +1,188 @@
+package dao
+
+import (
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/response"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MedicationAdherenceStats 用药依从性统计
+type MedicationAdherenceStats struct {
+	// 按计划应服药次数
+	ExpectedDoses int `json:"expected_doses"`
+
+	// 实际服药次数
+	TakenDoses int `json:"taken_doses"`
+
+	// 主动跳过次数
+	SkippedDoses int `json:"skipped_doses"`
+
+	// 依从率(0~1)，无用药计划时为 0
+	AdherenceRate float64 `json:"adherence_rate"`
+
+	Plans []MedicationPlanAdherence `json:"plans"`
+}
+
+type MedicationPlanAdherence struct {
+	PlanID        uint    `json:"plan_id"`
+	DrugName      string  `json:"drug_name"`
+	Dose          float32 `json:"dose"`
+	DoseUnit      string  `json:"dose_unit"`
+	ExpectedDoses int     `json:"expected_doses"`
+	TakenDoses    int     `json:"taken_doses"`
+	SkippedDoses  int     `json:"skipped_doses"`
+	AdherenceRate float64 `json:"adherence_rate"`
+}
+
+func GetMedicationPlans(email string) ([]response.GetMedicationPlansResponse, error) {
+	var plans []response.GetMedicationPlansResponse
+	err := DB.Model(&model.MedicationPlan{}).
+		Select("id, drug_name, drug_type, dose, dose_unit, schedule_times, start_date, end_date, notes").
+		Where("user_email = ?", email).
+		Order("start_date DESC").
+		Find(&plans).Error
+	return plans, err
+}
+
+func GetMedicationPlan(email string, id uint) (*model.MedicationPlan, error) {
+	var plan model.MedicationPlan
+	err := DB.Where("id = ? AND user_email = ?", id, email).
+		First(&plan).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &plan, err
+}
+
+func UpdateMedicationPlan(plan model.MedicationPlan) error {
+	return DB.Model(&model.MedicationPlan{}).
+		Where("id = ? AND user_email = ?", plan.ID, plan.UserEmail).
+		Select("drug_name", "drug_type", "dose", "dose_unit", "schedule_times", "start_date", "end_date", "notes").
+		Updates(plan).Error
+}
+
+func DeleteMedicationPlan(email string, id uint) error {
+	return DB.Where("id = ? AND user_email = ?", id, email).
+		Delete(&model.MedicationPlan{}).Error
+}
+
+func GetMedicationIntakes(email string, start, end time.Time) ([]response.GetMedicationIntakesResponse, error) {
+	var intakes []response.GetMedicationIntakesResponse
+	err := DB.Model(&model.MedicationIntake{}).
+		Select("id, plan_id, drug_name, dose, dose_unit, taken_at, status, notes").
+		Where("user_email = ? AND taken_at BETWEEN ? AND ?", email, start, end).
+		Order("taken_at ASC").
+		Find(&intakes).Error
+	return intakes, err
+}
+
+func DeleteMedicationIntake(email string, id uint) error {
+	return DB.Where("id = ? AND user_email = ?", id, email).
+		Delete(&model.MedicationIntake{}).Error
+}
+
+// GetMedicationAdherenceStats 获取指定时间范围内的用药依从性统计
+// 应服药次数按计划的每日服药时间点计算，实际服药次数按关联计划的服药记录计算
+func GetMedicationAdherenceStats(email string, start, end time.Time) (*MedicationAdherenceStats, error) {
+	var plans []model.MedicationPlan
+	err := DB.Where("user_email = ? AND start_date <= ? AND (end_date IS NULL OR end_date >= ?)",
+		email, end, start.UTC().Truncate(24*time.Hour)).
+		Find(&plans).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var intakeCounts []struct {
+		PlanID uint
+		Status string
+		Count  int
+	}
+	err = DB.Model(&model.MedicationIntake{}).
+		Select("plan_id, status, COUNT(*) as count").
+		Where("user_email = ? AND plan_id > 0 AND taken_at BETWEEN ? AND ?", email, start, end).
+		Group("plan_id, status").
+		Find(&intakeCounts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	taken := make(map[uint]int)
+	skipped := make(map[uint]int)
+	for _, c := range intakeCounts {
+		switch c.Status {
+		case model.IntakeStatusTaken:
+			taken[c.PlanID] = c.Count
+		case model.IntakeStatusSkipped:
+			skipped[c.PlanID] = c.Count
+		}
+	}
+
+	stats := MedicationAdherenceStats{
+		Plans: make([]MedicationPlanAdherence, 0, len(plans)),
+	}
+	for _, plan := range plans {
+		planStats := MedicationPlanAdherence{
+			PlanID:        plan.ID,
+			DrugName:      plan.DrugName,
+			Dose:          plan.Dose,
+			DoseUnit:      plan.DoseUnit,
+			ExpectedDoses: countExpectedDoses(plan, start, end),
+			TakenDoses:    taken[plan.ID],
+			SkippedDoses:  skipped[plan.ID],
+		}
+		planStats.AdherenceRate = adherenceRate(planStats.TakenDoses, planStats.ExpectedDoses)
+
+		stats.ExpectedDoses += planStats.ExpectedDoses
+		stats.TakenDoses += planStats.TakenDoses
+		stats.SkippedDoses += planStats.SkippedDoses
+		stats.Plans = append(stats.Plans, planStats)
+	}
+	stats.AdherenceRate = adherenceRate(stats.TakenDoses, stats.ExpectedDoses)
+
+	return &stats, nil
+}
+
+// 计算用药计划在 [start, end] 内的应服药次数，停药日期当天的服药时间点均计入，尚未到达的服药时间点不计入
+func countExpectedDoses(plan model.MedicationPlan, start, end time.Time) int {
+	if plan.StartDate.After(start) {
+		start = plan.StartDate
+	}
+	if plan.EndDate != nil {
+		planEnd := plan.EndDate.UTC().Truncate(24 * time.Hour).Add(24*time.Hour - time.Nanosecond)
+		if planEnd.Before(end) {
+			end = planEnd
+		}
+	}
+	if now := time.Now(); now.Before(end) {
+		end = now
+	}
+
+	count := 0
+	for day := start.UTC().Truncate(24 * time.Hour); !day.After(end); day = day.AddDate(0, 0, 1) {
+		for _, scheduleTime := range plan.ScheduleTimes {
+			t, err := time.Parse("15:04", scheduleTime)
+			if err != nil {
+				continue
+			}
+
+			scheduledAt := day.Add(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute)
+			if !scheduledAt.Before(start) && !scheduledAt.After(end) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func adherenceRate(taken, expected int) float64 {
+	if expected == 0 {
+		return 0
+	}
+	if taken >= expected {
+		return 1
+	}
+	return float64(taken) / float64(expected)
+}
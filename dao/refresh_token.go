@@ -0,0 +1,37 @@
+package dao
+
+import (
+	"diabetes-agent-server/model"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+func CreateRefreshToken(token *model.RefreshToken) error {
+	return DB.Create(token).Error
+}
+
+func GetRefreshTokenByHash(tokenHash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := DB.Where("token_hash = ?", tokenHash).
+		First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &token, err
+}
+
+// RevokeRefreshToken 吊销未吊销的刷新令牌，返回 false 表示令牌已被吊销，用于并发刷新时保证只有一次成功
+func RevokeRefreshToken(id uint) (bool, error) {
+	result := DB.Model(&model.RefreshToken{}).
+		Where("id = ? AND revoked = ?", id, false).
+		Update("revoked", true)
+	return result.RowsAffected > 0, result.Error
+}
+
+// RevokeUserRefreshTokens 吊销用户的所有刷新令牌
+func RevokeUserRefreshTokens(email string) error {
+	return DB.Model(&model.RefreshToken{}).
+		Where("user_email = ? AND revoked = ?", email, false).
+		Update("revoked", true).Error
+}
@@ -107,6 +107,17 @@ func GetMessageByID(messageID uint) (*model.Message, error) {
 	return &message, err
 }
 
+// GetSessionUserEmail 获取会话所属用户的邮箱
+func GetSessionUserEmail(sessionID string) (string, error) {
+	var email string
+	err := DB.Model(&model.Session{}).
+		Select("user_email").
+		Where("session_id = ?", sessionID).
+		Limit(1).
+		Scan(&email).Error
+	return email, err
+}
+
 func UpdateSessionTitle(email, sessionID, title string) error {
 	return DB.Model(&model.Session{}).
 		Where("user_email = ? AND session_id = ?", email, sessionID).
@@ -1,8 +1,14 @@
 package dao
 
 import (
+	"context"
+	"diabetes-agent-server/constants"
 	"diabetes-agent-server/model"
 	"diabetes-agent-server/response"
+	"fmt"
+	"slices"
+
+	"github.com/redis/go-redis/v9"
 )
 
 const pageSize = 10
@@ -48,3 +54,51 @@ func DeleteSystemMessage(id string) (*model.SystemMessage, error) {
 
 	return &message, nil
 }
+
+// 批量写入系统消息的批次大小
+const broadcastBatchSize = 500
+
+// BroadcastSystemMessage 向多个用户批量存储同一条系统消息，并更新各用户的未读消息计数
+func BroadcastSystemMessage(ctx context.Context, emails []string, title, content string) error {
+	for batch := range slices.Chunk(emails, broadcastBatchSize) {
+		msgs := make([]model.SystemMessage, 0, len(batch))
+		for _, email := range batch {
+			msgs = append(msgs, model.SystemMessage{
+				UserEmail: email,
+				Title:     title,
+				Content:   content,
+				IsRead:    false,
+			})
+		}
+		if err := DB.Create(&msgs).Error; err != nil {
+			return err
+		}
+
+		_, err := RedisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, email := range batch {
+				pipe.Incr(ctx, fmt.Sprintf(constants.KeyUnreadMsgCount, email))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateSystemMessage 存储系统消息，并更新用户的未读消息计数
+func CreateSystemMessage(ctx context.Context, email, title, content string) error {
+	msg := model.SystemMessage{
+		UserEmail: email,
+		Title:     title,
+		Content:   content,
+		IsRead:    false,
+	}
+	if err := DB.Create(&msg).Error; err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf(constants.KeyUnreadMsgCount, email)
+	return RedisClient.Incr(ctx, key).Err()
+}
@@ -2,11 +2,16 @@ package dao
 
 import (
 	"diabetes-agent-server/model"
+	"diabetes-agent-server/response"
 	"errors"
+	"strings"
 
 	"gorm.io/gorm"
 )
 
+// 转义 LIKE 中的通配符，使查询内容按字面匹配
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 func GetUserByEmail(email string) (*model.User, error) {
 	var user model.User
 	err := DB.Where("email = ?", email).First(&user).Error
@@ -20,10 +25,22 @@ func GetUserByEmail(email string) (*model.User, error) {
 	return &user, nil
 }
 
-func GetAllUsers() ([]model.User, error) {
-	var users []model.User
-	err := DB.Find(&users).Error
-	return users, err
+func MarkUserEmailVerified(email string) error {
+	return DB.Model(&model.User{}).
+		Where("email = ?", email).
+		Update("email_verified", true).Error
+}
+
+func UpdateUserPassword(email, hashedPassword string) error {
+	return DB.Model(&model.User{}).
+		Where("email = ?", email).
+		Update("password", hashedPassword).Error
+}
+
+func UpdateInsufficientDataPolicy(email, policy string) error {
+	return DB.Model(&model.User{}).
+		Where("email = ?", email).
+		Update("insufficient_data_policy", policy).Error
 }
 
 func UpdateEnableNotification(email string, enable bool) error {
@@ -31,3 +48,51 @@ func UpdateEnableNotification(email string, enable bool) error {
 		Where("email = ?", email).
 		Update("enable_weekly_report_notification", enable).Error
 }
+
+// SearchUsers 分页查询用户，query 按邮箱模糊匹配，role 为空时不过滤角色
+func SearchUsers(query, role string, page int) (*response.GetUsersResponse, error) {
+	db := DB.Model(&model.User{})
+	if query != "" {
+		db = db.Where("email LIKE ?", "%"+likeEscaper.Replace(query)+"%")
+	}
+	if role != "" {
+		db = db.Where("role = ?", role)
+	}
+
+	var users response.GetUsersResponse
+	err := db.Select("id, created_at, email, role, email_verified, disabled, enable_weekly_report_notification").
+		Order("id DESC").
+		Count(&users.Total).
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&users.Users).Error
+	return &users, err
+}
+
+// GetActiveUserEmails 获取未禁用用户的邮箱，role 为空时返回所有角色，emails 非空时仅返回其中已注册的用户
+func GetActiveUserEmails(role string, emails []string) ([]string, error) {
+	db := DB.Model(&model.User{}).
+		Where("disabled = ?", false)
+	if role != "" {
+		db = db.Where("role = ?", role)
+	}
+	if len(emails) > 0 {
+		db = db.Where("email IN ?", emails)
+	}
+
+	var result []string
+	err := db.Pluck("email", &result).Error
+	return result, err
+}
+
+func UpdateUserDisabled(email string, disabled bool) error {
+	return DB.Model(&model.User{}).
+		Where("email = ?", email).
+		Update("disabled", disabled).Error
+}
+
+func UpdateUserRole(email, role string) error {
+	return DB.Model(&model.User{}).
+		Where("email = ?", email).
+		Update("role", role).Error
+}
@@ -0,0 +1,139 @@
+package dao
+
+import (
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/response"
+	"time"
+)
+
+const (
+	// 糖尿病患者的血压控制目标(mmHg)，达到任一值视为血压偏高
+	HighSystolicThreshold  = 130
+	HighDiastolicThreshold = 80
+)
+
+type VitalStats struct {
+	Count int `json:"count"`
+
+	// 时间范围内最近一次体重(kg)及相对首次体重的变化量
+	LatestWeight *float32 `json:"latest_weight"`
+	WeightChange *float32 `json:"weight_change"`
+	LatestBMI    *float32 `json:"latest_bmi"`
+
+	AvgSystolic  *float32 `json:"avg_systolic"`
+	AvgDiastolic *float32 `json:"avg_diastolic"`
+
+	// 血压偏高的测量次数
+	HighBloodPressureCount int `json:"high_blood_pressure_count"`
+
+	AvgHeartRate *float32 `json:"avg_heart_rate"`
+	LatestWaist  *float32 `json:"latest_waist"`
+}
+
+// GetVitalRecords 获取指定时间范围内的体征记录，并根据健康档案中的身高计算 BMI
+func GetVitalRecords(email string, start, end time.Time) ([]response.GetVitalRecordsResponse, error) {
+	var vitals []model.VitalRecord
+	err := DB.Where("user_email = ? AND measured_at BETWEEN ? AND ?", email, start, end).
+		Order("measured_at ASC").
+		Find(&vitals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]response.GetVitalRecordsResponse, 0, len(vitals))
+	if len(vitals) == 0 {
+		return records, nil
+	}
+
+	profile, err := GetHealthProfile(email)
+	if err != nil {
+		return nil, err
+	}
+	var height float32
+	if profile != nil {
+		height = profile.Height
+	}
+
+	for _, vital := range vitals {
+		records = append(records, response.GetVitalRecordsResponse{
+			ID:         vital.ID,
+			MeasuredAt: vital.MeasuredAt,
+			Weight:     vital.Weight,
+			BMI:        calcBMI(vital.Weight, height),
+			Systolic:   vital.Systolic,
+			Diastolic:  vital.Diastolic,
+			HeartRate:  vital.HeartRate,
+			Waist:      vital.Waist,
+			Notes:      vital.Notes,
+		})
+	}
+
+	return records, nil
+}
+
+// GetVitalStats 统计体征记录，records 需按测量时间升序排列
+func GetVitalStats(records []response.GetVitalRecordsResponse) *VitalStats {
+	stats := VitalStats{
+		Count: len(records),
+	}
+
+	var firstWeight *float32
+	var systolicSum, diastolicSum, bloodPressureCount int
+	var heartRateSum, heartRateCount int
+	for _, r := range records {
+		if r.Weight != nil {
+			if firstWeight == nil {
+				firstWeight = r.Weight
+			}
+			stats.LatestWeight = r.Weight
+			stats.LatestBMI = r.BMI
+		}
+		if r.Systolic != nil && r.Diastolic != nil {
+			systolicSum += *r.Systolic
+			diastolicSum += *r.Diastolic
+			bloodPressureCount++
+			if *r.Systolic >= HighSystolicThreshold || *r.Diastolic >= HighDiastolicThreshold {
+				stats.HighBloodPressureCount++
+			}
+		}
+		if r.HeartRate != nil {
+			heartRateSum += *r.HeartRate
+			heartRateCount++
+		}
+		if r.Waist != nil {
+			stats.LatestWaist = r.Waist
+		}
+	}
+
+	if firstWeight != nil && firstWeight != stats.LatestWeight {
+		change := *stats.LatestWeight - *firstWeight
+		stats.WeightChange = &change
+	}
+	if bloodPressureCount > 0 {
+		avgSystolic := float32(systolicSum) / float32(bloodPressureCount)
+		avgDiastolic := float32(diastolicSum) / float32(bloodPressureCount)
+		stats.AvgSystolic = &avgSystolic
+		stats.AvgDiastolic = &avgDiastolic
+	}
+	if heartRateCount > 0 {
+		avgHeartRate := float32(heartRateSum) / float32(heartRateCount)
+		stats.AvgHeartRate = &avgHeartRate
+	}
+
+	return &stats
+}
+
+func DeleteVitalRecord(email string, id uint) error {
+	return DB.Where("id = ? AND user_email = ?", id, email).
+		Delete(&model.VitalRecord{}).Error
+}
+
+// BMI = 体重(kg) / 身高(m)²，身高单位为 cm
+func calcBMI(weight *float32, height float32) *float32 {
+	if weight == nil || height <= 0 {
+		return nil
+	}
+	heightInMeters := height / 100
+	bmi := *weight / (heightInMeters * heightInMeters)
+	return &bmi
+}
@@ -17,6 +17,65 @@
 SET NAMES utf8mb4;
 SET FOREIGN_KEY_CHECKS = 0;
 
+-- ----------------------------
+-- Table structure for access_audit_log
+-- ----------------------------
+DROP TABLE IF EXISTS `access_audit_log`;
+CREATE TABLE `access_audit_log`  (
+  `id` bigint UNSIGNED NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `actor_email` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '访问者邮箱',
+  `actor_role` varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `patient_email` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `scope` varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '访问的数据范围',
+  `method` varchar(10) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `path` varchar(512) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `ip` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `status_code` int NOT NULL COMMENT '响应状态码',
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `idx_patient_created`(`patient_email` ASC, `created_at` ASC) USING BTREE,
+  INDEX `idx_actor`(`actor_email` ASC) USING BTREE
+) ENGINE = InnoDB AUTO_INCREMENT = 10000 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = DYNAMIC;
+
+-- ----------------------------
+-- Table structure for account_deletion_job
+-- ----------------------------
+DROP TABLE IF EXISTS `account_deletion_job`;
+CREATE TABLE `account_deletion_job`  (
+  `id` bigint UNSIGNED NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `user_email` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `status` enum('pending','canceled','processing','completed','failed') CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT 'pending' COMMENT '任务状态',
+  `scheduled_at` timestamp NOT NULL COMMENT '冷静期结束时间',
+  `attempts` int NOT NULL DEFAULT 0 COMMENT '已执行次数',
+  `last_error` text CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL COMMENT '最近一次执行失败的原因',
+  `report` json NULL COMMENT '删除报告',
+  `completed_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `idx_email`(`user_email` ASC) USING BTREE,
+  INDEX `idx_status_scheduled`(`status` ASC, `scheduled_at` ASC) USING BTREE
+) ENGINE = InnoDB AUTO_INCREMENT = 10000 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = DYNAMIC;
+
+-- ----------------------------
+-- Table structure for alert_rule
+-- ----------------------------
+DROP TABLE IF EXISTS `alert_rule`;
+CREATE TABLE `alert_rule`  (
+  `id` bigint UNSIGNED NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `user_email` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `type` enum('below','above','rising','fasting_above') CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `threshold` float NOT NULL COMMENT '血糖阈值(mmol/L)，rising 规则中为累计上升幅度',
+  `consecutive_count` int NOT NULL DEFAULT 0 COMMENT 'rising 规则的连续读数次数',
+  `cooldown_minutes` int NOT NULL DEFAULT 60 COMMENT '提醒冷却期(分钟)',
+  `notify_email` tinyint(1) NOT NULL DEFAULT 0,
+  `enabled` tinyint(1) NOT NULL DEFAULT 1,
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `idx_email`(`user_email` ASC) USING BTREE
+) ENGINE = InnoDB AUTO_INCREMENT = 10000 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = DYNAMIC;
+
 -- ----------------------------
 -- Table structure for blood_glucose_record
 -- ----------------------------
@@ -30,10 +89,28 @@ CREATE TABLE `blood_glucose_record`  (
   `measured_at` timestamp NOT NULL,
   `dining_status` enum('fasting','before_breakfast','after_breakfast','before_lunch','after_lunch','before_dinner','after_dinner','bedtime','random') CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
   `notes` text CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL,
+  `source` enum('manual','cgm') CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT 'manual' COMMENT '数据来源',
   PRIMARY KEY (`id`) USING BTREE,
   INDEX `idx_email_measured_at`(`user_email` ASC, `measured_at` ASC) USING BTREE
 ) ENGINE = InnoDB AUTO_INCREMENT = 10000 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = DYNAMIC;
 
+-- ----------------------------
+-- Table structure for care_share
+-- ----------------------------
+DROP TABLE IF EXISTS `care_share`;
+CREATE TABLE `care_share`  (
+  `id` bigint UNSIGNED NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `patient_email` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `grantee_email` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '被授权的看护人/医生邮箱',
+  `scopes` set('glucose','exercise','profile','report') CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '授权的数据范围',
+  `expires_at` timestamp NULL DEFAULT NULL COMMENT '授权到期时间(为空时长期有效)',
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `idx_patient_grantee`(`patient_email` ASC, `grantee_email` ASC) USING BTREE,
+  INDEX `idx_grantee`(`grantee_email` ASC) USING BTREE
+) ENGINE = InnoDB AUTO_INCREMENT = 10000 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = DYNAMIC;
+
 -- ----------------------------
 -- Table structure for chat_intermediate_steps
 -- ----------------------------
@@ -110,6 +187,23 @@ CREATE TABLE `chat_uploaded_file`  (
   INDEX `idx_message`(`message_id` ASC) USING BTREE
 ) ENGINE = InnoDB AUTO_INCREMENT = 10000 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci ROW_FORMAT = DYNAMIC;
 
+-- ----------------------------
+-- Table structure for data_export_job
+-- ----------------------------
+DROP TABLE IF EXISTS `data_export_job`;
+CREATE TABLE `data_export_job`  (
+  `id` bigint UNSIGNED NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `user_email` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `status` enum('pending','processing','completed','failed') CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT 'pending' COMMENT '任务状态',
+  `error` text CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL COMMENT '最近一次导出失败的原因',
+  `object_name` varchar(512) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '' COMMENT '导出文件的 OSS 对象名',
+  `file_name` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '' COMMENT '导出文件名',
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `idx_email`(`user_email` ASC) USING BTREE
+) ENGINE = InnoDB AUTO_INCREMENT = 10000 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = DYNAMIC;
+
 -- ----------------------------
 -- Table structure for exercise_record
 -- ----------------------------
@@ -154,10 +248,50 @@ CREATE TABLE `health_profile`  (
   `diagnosis_year` int NULL DEFAULT NULL COMMENT '患病年数',
   `therapy_mode` enum('lifestyle','oral_meds','insulin','combined') CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '治疗模式',
   `allergies` text CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL COMMENT '药物/食物过敏史',
+  `glucose_very_low` float NOT NULL DEFAULT 0 COMMENT '严重低血糖阈值(0为默认值)',
+  `glucose_low` float NOT NULL DEFAULT 0 COMMENT '目标范围下限(0为默认值)',
+  `glucose_high` float NOT NULL DEFAULT 0 COMMENT '目标范围上限(0为默认值)',
+  `glucose_very_high` float NOT NULL DEFAULT 0 COMMENT '严重高血糖阈值(0为默认值)',
   PRIMARY KEY (`id`) USING BTREE,
   UNIQUE INDEX `idx_email`(`user_email` ASC) USING BTREE
 ) ENGINE = InnoDB AUTO_INCREMENT = 10000 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = DYNAMIC;
 
+-- ----------------------------
+-- Table structure for health_profile_history
+-- ----------------------------
+DROP TABLE IF EXISTS `health_profile_history`;
+CREATE TABLE `health_profile_history`  (
+  `id` bigint UNSIGNED NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `user_email` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `version` int NOT NULL COMMENT '档案版本号，从 1 开始',
+  `changes` json NULL COMMENT '变更字段及变更前后的值',
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `idx_email_version`(`user_email` ASC, `version` ASC) USING BTREE
+) ENGINE = InnoDB AUTO_INCREMENT = 10000 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = DYNAMIC;
+
+-- ----------------------------
+-- Table structure for health_report_job
+-- ----------------------------
+DROP TABLE IF EXISTS `health_report_job`;
+CREATE TABLE `health_report_job`  (
+  `id` bigint UNSIGNED NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `user_email` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `period_type` enum('weekly','monthly','quarterly') CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '报告周期',
+  `start_at` timestamp NOT NULL,
+  `end_at` timestamp NOT NULL,
+  `status` enum('pending','running','completed','failed') CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT 'pending' COMMENT '任务状态',
+  `attempts` int NOT NULL DEFAULT 0 COMMENT '已执行次数',
+  `last_error` text CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL COMMENT '最近一次执行失败的原因',
+  `decision` varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '' COMMENT '数据充足性判断结果',
+  `report_id` bigint UNSIGNED NULL DEFAULT NULL COMMENT '生成的报告',
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `idx_email_period_start`(`user_email` ASC, `period_type` ASC, `start_at` ASC) USING BTREE,
+  INDEX `idx_status`(`status` ASC) USING BTREE
+) ENGINE = InnoDB AUTO_INCREMENT = 10000 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = DYNAMIC;
+
 -- ----------------------------
 -- Table structure for health_weekly_report
 -- ----------------------------
@@ -167,12 +301,18 @@ CREATE TABLE `health_weekly_report`  (
   `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
   `user_email` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `period_type` enum('weekly','monthly','quarterly','custom') CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT 'weekly' COMMENT '报告周期',
   `start_at` timestamp NOT NULL,
   `end_at` timestamp NOT NULL,
+  `status` enum('pending','generating','completed','failed') CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT 'completed' COMMENT '生成状态',
+  `error` text CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL COMMENT '最近一次生成失败的原因',
   `file_name` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
   `object_name` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `pdf_file_name` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '' COMMENT 'PDF 版本文件名',
+  `pdf_object_name` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '' COMMENT 'PDF 版本对象名',
+  `notified_at` timestamp NULL DEFAULT NULL COMMENT '通知用户的时间',
   PRIMARY KEY (`id`) USING BTREE,
-  INDEX `idx_email_start_at`(`user_email` ASC, `start_at` ASC) USING BTREE
+  UNIQUE INDEX `idx_email_period_range`(`user_email` ASC, `period_type` ASC, `start_at` ASC, `end_at` ASC) USING BTREE
 ) ENGINE = InnoDB AUTO_INCREMENT = 10000 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = DYNAMIC;
 
 -- ----------------------------
@@ -194,6 +334,124 @@ CREATE TABLE `knowledge_metadata`  (
   FULLTEXT INDEX `idx_fulltext_file_name`(`file_name`) WITH PARSER `ngram`
 ) ENGINE = InnoDB AUTO_INCREMENT = 10000 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = DYNAMIC;
 
+-- ----------------------------
+-- Table structure for lab_result
+-- ----------------------------
+DROP TABLE IF EXISTS `lab_result`;
+CREATE TABLE `lab_result`  (
+  `id` bigint UNSIGNED NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `user_email` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `kind` enum('hba1c','total_cholesterol','ldl','hdl','triglycerides','creatinine','egfr','uacr') CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '检验项目',
+  `value` float NOT NULL,
+  `unit` varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `reference_low` float NULL DEFAULT NULL COMMENT '参考范围下限',
+  `reference_high` float NULL DEFAULT NULL COMMENT '参考范围上限',
+  `tested_at` timestamp NOT NULL COMMENT '检验时间',
+  `notes` text CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `idx_email_kind_tested_at`(`user_email` ASC, `kind` ASC, `tested_at` ASC) USING BTREE
+) ENGINE = InnoDB AUTO_INCREMENT = 10000 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = DYNAMIC;
+
+-- ----------------------------
+-- Table structure for llm_usage
+-- ----------------------------
+DROP TABLE IF EXISTS `llm_usage`;
+CREATE TABLE `llm_usage`  (
+  `id` bigint UNSIGNED NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `user_email` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `session_id` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `task` varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '任务类型',
+  `provider` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `model` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `prompt_tokens` int NOT NULL DEFAULT 0,
+  `completion_tokens` int NOT NULL DEFAULT 0,
+  `total_tokens` int NOT NULL DEFAULT 0,
+  `cost` decimal(12, 6) NOT NULL DEFAULT 0.000000 COMMENT '费用(元)',
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `idx_email_created`(`user_email` ASC, `created_at` ASC) USING BTREE
+) ENGINE = InnoDB AUTO_INCREMENT = 10000 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = DYNAMIC;
+
+-- ----------------------------
+-- Table structure for meal_record
+-- ----------------------------
+DROP TABLE IF EXISTS `meal_record`;
+CREATE TABLE `meal_record`  (
+  `id` bigint UNSIGNED NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `user_email` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `meal_type` enum('breakfast','lunch','dinner','snack') CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `eaten_at` timestamp NOT NULL,
+  `foods` json NULL COMMENT '食物明细',
+  `carbohydrate` float NOT NULL DEFAULT 0 COMMENT '碳水化合物总量(g)',
+  `calories` float NOT NULL DEFAULT 0 COMMENT '总热量(kcal)',
+  `notes` text CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `idx_email_eaten_at`(`user_email` ASC, `eaten_at` ASC) USING BTREE
+) ENGINE = InnoDB AUTO_INCREMENT = 10000 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = DYNAMIC;
+
+-- ----------------------------
+-- Table structure for medication_intake
+-- ----------------------------
+DROP TABLE IF EXISTS `medication_intake`;
+CREATE TABLE `medication_intake`  (
+  `id` bigint UNSIGNED NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `user_email` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `plan_id` bigint UNSIGNED NOT NULL DEFAULT 0 COMMENT '关联的用药计划(0为计划外用药)',
+  `drug_name` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `dose` float NOT NULL,
+  `dose_unit` varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `taken_at` timestamp NOT NULL,
+  `status` enum('taken','skipped') CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `notes` text CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `idx_email_taken_at`(`user_email` ASC, `taken_at` ASC) USING BTREE,
+  INDEX `idx_plan`(`plan_id` ASC) USING BTREE
+) ENGINE = InnoDB AUTO_INCREMENT = 10000 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = DYNAMIC;
+
+-- ----------------------------
+-- Table structure for medication_plan
+-- ----------------------------
+DROP TABLE IF EXISTS `medication_plan`;
+CREATE TABLE `medication_plan`  (
+  `id` bigint UNSIGNED NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `user_email` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `drug_name` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `drug_type` enum('oral','insulin','glp1','other') CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `dose` float NOT NULL COMMENT '单次剂量',
+  `dose_unit` varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '剂量单位',
+  `schedule_times` json NULL COMMENT '每日服药时间点(HH:MM)',
+  `start_date` timestamp NOT NULL,
+  `end_date` timestamp NULL DEFAULT NULL COMMENT '停药日期',
+  `notes` text CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `idx_email`(`user_email` ASC) USING BTREE
+) ENGINE = InnoDB AUTO_INCREMENT = 10000 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = DYNAMIC;
+
+-- ----------------------------
+-- Table structure for refresh_token
+-- ----------------------------
+DROP TABLE IF EXISTS `refresh_token`;
+CREATE TABLE `refresh_token`  (
+  `id` bigint UNSIGNED NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `user_email` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `token_hash` char(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '刷新令牌的 SHA-256 摘要',
+  `expires_at` timestamp NOT NULL,
+  `revoked` tinyint(1) NOT NULL DEFAULT 0,
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `idx_token_hash`(`token_hash` ASC) USING BTREE,
+  INDEX `idx_email`(`user_email` ASC) USING BTREE
+) ENGINE = InnoDB AUTO_INCREMENT = 10000 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = DYNAMIC;
+
 -- ----------------------------
 -- Table structure for system_message
 -- ----------------------------
@@ -222,8 +480,32 @@ CREATE TABLE `user`  (
   `password` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL,
   `avatar` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL,
   `enable_weekly_report_notification` tinyint NOT NULL,
+  `insufficient_data_policy` enum('skip','placeholder') CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT 'placeholder' COMMENT '定时报告数据不足时的处理方式',
+  `email_verified` tinyint(1) NOT NULL DEFAULT 0 COMMENT '邮箱是否已验证',
+  `disabled` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否被管理员禁用',
+  `role` enum('patient','caregiver','clinician','admin') CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT 'patient' COMMENT '用户角色',
   PRIMARY KEY (`id`) USING BTREE,
   UNIQUE INDEX `idx_email`(`email` ASC) USING BTREE
 ) ENGINE = InnoDB AUTO_INCREMENT = 10000 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci ROW_FORMAT = DYNAMIC;
 
+-- ----------------------------
+-- Table structure for vital_record
+-- ----------------------------
+DROP TABLE IF EXISTS `vital_record`;
+CREATE TABLE `vital_record`  (
+  `id` bigint UNSIGNED NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `user_email` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL,
+  `measured_at` timestamp NOT NULL,
+  `weight` float NULL DEFAULT NULL COMMENT '体重(kg)',
+  `systolic` int NULL DEFAULT NULL COMMENT '收缩压(mmHg)',
+  `diastolic` int NULL DEFAULT NULL COMMENT '舒张压(mmHg)',
+  `heart_rate` int NULL DEFAULT NULL COMMENT '心率(次/分)',
+  `waist` float NULL DEFAULT NULL COMMENT '腰围(cm)',
+  `notes` text CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `idx_email_measured_at`(`user_email` ASC, `measured_at` ASC) USING BTREE
+) ENGINE = InnoDB AUTO_INCREMENT = 10000 CHARACTER SET = utf8mb4 COLLATE = utf8mb4_unicode_ci ROW_FORMAT = DYNAMIC;
+
 SET FOREIGN_KEY_CHECKS = 1;
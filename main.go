@@ -1,19 +1,34 @@
 package main
 
 import (
+	"context"
 	"diabetes-agent-server/config"
+	"diabetes-agent-server/dao"
 	"diabetes-agent-server/router"
+	accountdeletion "diabetes-agent-server/service/account-deletion"
 	healthreport "diabetes-agent-server/service/health-weekly-report"
+	"diabetes-agent-server/service/knowledge-base/etl"
+	llmprovider "diabetes-agent-server/service/llm-provider"
 	"diabetes-agent-server/service/mq"
+	voicerecognition "diabetes-agent-server/service/voice-recognition"
 	"log/slog"
 	"os"
 	"strings"
 )
 
 func main() {
+	// 加载配置
+	config.Load()
+
 	// 设置日志
 	setSysLog()
 
+	// 连接数据库及初始化依赖配置的服务
+	dao.Init()
+	llmprovider.Init()
+	etl.Init()
+	voicerecognition.Init()
+
 	// 启动 MQ 服务
 	if err := mq.Run(); err != nil {
 		slog.Error("Failed to start MQ service", "err", err)
@@ -21,8 +36,23 @@ func main() {
 	}
 	defer mq.Shutdown()
 
-	// 启动健康周报定时任务
-	go healthreport.SetupHealthWeeklyReportScheduler()
+	// 启动健康报告定时任务，按用户投递报告任务消息
+	go healthreport.SetupHealthWeeklyReportScheduler(func(ctx context.Context, msg healthreport.ReportJobMessage) error {
+		return mq.SendMessage(ctx, &mq.Message{
+			Topic:   mq.TopicHealthReport,
+			Tag:     mq.TagReportJob,
+			Payload: msg,
+		})
+	})
+
+	// 启动账号注销定时任务，投递冷静期已结束的注销任务消息
+	go accountdeletion.SetupAccountDeletionScheduler(func(ctx context.Context, msg accountdeletion.DeletionMessage) error {
+		return mq.SendMessage(ctx, &mq.Message{
+			Topic:   mq.TopicAccount,
+			Tag:     mq.TagDeleteAccount,
+			Payload: msg,
+		})
+	})
 
 	// 启动 HTTP 服务
 	r := router.Register()
@@ -1,32 +1,110 @@
 package middleware
 
 import (
+	"context"
 	"diabetes-agent-server/config"
+	"diabetes-agent-server/constants"
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/model"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 )
 
+// 访问令牌的默认有效期
+const defaultAccessTokenTTL = 15 * time.Minute
+
 type Claims struct {
 	Email string
+	Role  string
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(email string) (string, error) {
+// AccessTokenTTL 访问令牌有效期
+func AccessTokenTTL() time.Duration {
+	if minutes := config.Cfg.JWT.AccessTokenMinutes; minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return defaultAccessTokenTTL
+}
+
+// GenerateToken 签发访问令牌，并记录令牌 ID(jti) 用于退出所有设备
+func GenerateToken(email, role string) (string, error) {
+	now := time.Now()
+	expiresAt := now.Add(AccessTokenTTL())
 	claims := Claims{
 		Email: email,
+		Role:  role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	secretKey := []byte(config.Cfg.JWT.SecretKey)
-	return token.SignedString(secretKey)
+	signed, err := token.SignedString(secretKey)
+	if err != nil {
+		return "", err
+	}
+
+	ctx := context.Background()
+	key := fmt.Sprintf(constants.KeyUserTokens, email)
+	_, err = dao.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, key, redis.Z{
+			Score:  float64(expiresAt.Unix()),
+			Member: claims.ID,
+		})
+		// 清理已过期的令牌 ID
+		pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(now.Unix(), 10))
+		pipe.Expire(ctx, key, AccessTokenTTL())
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to record token id: %v", err)
+	}
+
+	return signed, nil
+}
+
+// RevokeToken 将访问令牌加入吊销列表，直到令牌过期
+func RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return dao.RedisClient.Set(ctx, fmt.Sprintf(constants.KeyRevokedToken, jti), 1, ttl).Err()
+}
+
+// RevokeAllTokens 吊销用户所有未过期的访问令牌
+func RevokeAllTokens(ctx context.Context, email string) error {
+	key := fmt.Sprintf(constants.KeyUserTokens, email)
+	now := time.Now()
+	tokens, err := dao.RedisClient.ZRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+		Min: strconv.FormatInt(now.Unix(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get user token ids: %v", err)
+	}
+
+	_, err = dao.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, token := range tokens {
+			expiresAt := time.Unix(int64(token.Score), 0)
+			pipe.Set(ctx, fmt.Sprintf(constants.KeyRevokedToken, token.Member), 1, expiresAt.Sub(now)+time.Second)
+		}
+		pipe.Del(ctx, key)
+		return nil
+	})
+	return err
 }
 
 func AuthMiddleware() gin.HandlerFunc {
@@ -59,7 +137,33 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 检查令牌是否已通过退出登录吊销
+		revoked, err := dao.RedisClient.Exists(c.Request.Context(),
+			fmt.Sprintf(constants.KeyRevokedToken, claims.ID)).Result()
+		if err != nil {
+			slog.Error("Failed to check revoked token",
+				"user_email", claims.Email,
+				"err", err,
+			)
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		if revoked > 0 {
+			slog.Error("Token revoked", "user_email", claims.Email)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		// 角色上线前签发的令牌不含角色，视为患者
+		role := claims.Role
+		if role == "" {
+			role = model.RolePatient
+		}
+
 		c.Set("email", claims.Email)
+		c.Set("role", role)
+		c.Set("jti", claims.ID)
+		c.Set("token_expires_at", claims.ExpiresAt.Time)
 		c.Next()
 	}
 }
@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"diabetes-agent-server/config"
+	"diabetes-agent-server/constants"
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/response"
+	"diabetes-agent-server/utils"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// 限流的路由组，对应 rate_limit.groups 中的配置
+const (
+	RateLimitGroupChat       = "chat"
+	RateLimitGroupVoice      = "voice"
+	RateLimitGroupKBUpload   = "kb_upload"
+	RateLimitGroupReport     = "report"
+	RateLimitGroupDataExport = "data_export"
+)
+
+// 每日配额计数的过期时间，覆盖整个统计日
+const quotaKeyTTL = 48 * time.Hour
+
+// 限流脚本的拒绝原因
+const (
+	limitReasonRate  = 1
+	limitReasonQuota = 2
+)
+
+var (
+	ErrRateLimitExceeded  = errors.New("too many requests, please try again later")
+	ErrDailyQuotaExceeded = errors.New("daily quota exceeded, please try again tomorrow")
+)
+
+// 在同一脚本中检查每日配额和令牌桶，保证并发请求下的原子性，rate 或 burst 不大于 0 时仅检查每日配额。
+// 返回 {是否放行, 需等待的毫秒数, 拒绝原因}
+var rateLimitScript = redis.NewScript(`
+local bucket_key = KEYS[1]
+local quota_key = KEYS[2]
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local quota = tonumber(ARGV[3])
+local now = tonumber(ARGV[4])
+local quota_ttl = tonumber(ARGV[5])
+
+if quota > 0 then
+	local used = tonumber(redis.call('GET', quota_key) or '0')
+	if used >= quota then
+		return {0, 0, 2}
+	end
+end
+
+if rate > 0 and burst > 0 then
+	local data = redis.call('HMGET', bucket_key, 'tokens', 'ts')
+	local tokens = tonumber(data[1])
+	local ts = tonumber(data[2])
+	if tokens == nil or ts == nil then
+		tokens = burst
+		ts = now
+	end
+	tokens = math.min(burst, tokens + math.max(0, now - ts) / 1000 * rate)
+
+	if tokens < 1 then
+		return {0, math.ceil((1 - tokens) / rate * 1000), 1}
+	end
+
+	tokens = tokens - 1
+	redis.call('HSET', bucket_key, 'tokens', tostring(tokens), 'ts', now)
+	redis.call('PEXPIRE', bucket_key, math.ceil(burst / rate * 1000) + 1000)
+end
+
+if quota > 0 then
+	if redis.call('INCR', quota_key) == 1 then
+		redis.call('EXPIRE', quota_key, quota_ttl)
+	end
+end
+return {1, 0, 0}
+`)
+
+// RateLimitMiddleware 按用户对路由组进行令牌桶限流和每日配额限制，超出限制时返回 429
+func RateLimitMiddleware(group string) gin.HandlerFunc {
+	return rateLimit(group, func(c *gin.Context, err error) {
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, response.Response{
+			Msg: err.Error(),
+		})
+	})
+}
+
+// SSERateLimitMiddleware 用于 SSE 接口的限流，超出限制时推送 error 事件
+func SSERateLimitMiddleware(group string) gin.HandlerFunc {
+	return rateLimit(group, func(c *gin.Context, err error) {
+		c.Status(http.StatusTooManyRequests)
+		utils.SetSSEHeaders(c)
+		utils.SendSSEMessage(c, utils.EventError, err.Error())
+		utils.SendSSEMessage(c, utils.EventDone, nil)
+		c.Abort()
+	})
+}
+
+// 需在 AuthMiddleware 之后使用，按 email 区分用户
+func rateLimit(group string, onLimited func(c *gin.Context, err error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg, ok := config.Cfg.RateLimit.Groups[group]
+		if !ok || ((cfg.Rate <= 0 || cfg.Burst <= 0) && cfg.DailyQuota <= 0) {
+			c.Next()
+			return
+		}
+
+		// 每日配额按 UTC 日期统计，与服务器时区无关
+		email := c.GetString("email")
+		now := time.Now().UTC()
+		bucketKey := fmt.Sprintf(constants.KeyRateLimitBucket, email, group)
+		quotaKey := fmt.Sprintf(constants.KeyRateLimitQuota, email, group, now.Format("20060102"))
+
+		result, err := rateLimitScript.Run(c.Request.Context(), dao.RedisClient,
+			[]string{bucketKey, quotaKey},
+			cfg.Rate, cfg.Burst, cfg.DailyQuota, now.UnixMilli(), int(quotaKeyTTL.Seconds()),
+		).Int64Slice()
+
+		// Redis 不可用时放行，避免影响正常请求
+		if err != nil {
+			slog.Error("Failed to check rate limit",
+				"group", group,
+				"user_email", email,
+				"err", err,
+			)
+			c.Next()
+			return
+		}
+
+		if result[0] == 1 {
+			c.Next()
+			return
+		}
+
+		limitErr := ErrRateLimitExceeded
+		retryAfter := time.Duration(result[1]) * time.Millisecond
+		if result[2] == limitReasonQuota {
+			limitErr = ErrDailyQuotaExceeded
+			tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+			retryAfter = tomorrow.Sub(now)
+		}
+
+		slog.Warn(limitErr.Error(),
+			"group", group,
+			"user_email", email,
+			"retry_after", retryAfter,
+		)
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		onLimited(c, limitErr)
+	}
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/response"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrCareAccessDenied = errors.New("no access to the patient's data")
+	ErrCheckCareAccess  = errors.New("failed to check care access")
+)
+
+// RoleMiddleware 仅允许指定角色访问，需在 AuthMiddleware 之后使用
+func RoleMiddleware(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		if !slices.Contains(roles, role) {
+			slog.Warn(ErrPermissionDenied.Error(),
+				"user_email", c.GetString("email"),
+				"role", role,
+				"path", c.FullPath(),
+			)
+			c.AbortWithStatusJSON(http.StatusForbidden, response.Response{
+				Msg: ErrPermissionDenied.Error(),
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// CareAccessMiddleware 校验看护人/医生是否获得路径参数 email 对应患者在 scope 范围内的有效授权，
+// 通过后将患者邮箱写入 patient_email。无论是否通过，均记录访问审计日志
+func CareAccessMiddleware(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actorEmail := c.GetString("email")
+		patientEmail := c.Param("email")
+		defer recordCareAccess(c, scope, actorEmail, patientEmail)
+
+		share, err := dao.GetCareShare(patientEmail, actorEmail)
+		if err != nil {
+			slog.Error(ErrCheckCareAccess.Error(),
+				"user_email", actorEmail,
+				"patient_email", patientEmail,
+				"err", err,
+			)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{
+				Msg: ErrCheckCareAccess.Error(),
+			})
+			return
+		}
+
+		if share == nil || !share.Active() || !share.HasScope(scope) {
+			slog.Warn(ErrCareAccessDenied.Error(),
+				"user_email", actorEmail,
+				"patient_email", patientEmail,
+				"scope", scope,
+			)
+			c.AbortWithStatusJSON(http.StatusForbidden, response.Response{
+				Msg: ErrCareAccessDenied.Error(),
+			})
+			return
+		}
+
+		c.Set("patient_email", patientEmail)
+		c.Next()
+	}
+}
+
+func recordCareAccess(c *gin.Context, scope, actorEmail, patientEmail string) {
+	err := dao.CreateAccessAuditLog(&model.AccessAuditLog{
+		ActorEmail:   actorEmail,
+		ActorRole:    c.GetString("role"),
+		PatientEmail: patientEmail,
+		Scope:        scope,
+		Method:       c.Request.Method,
+		Path:         c.Request.URL.Path,
+		IP:           c.ClientIP(),
+		StatusCode:   c.Writer.Status(),
+	})
+	if err != nil {
+		slog.Error("Failed to save access audit log",
+			"user_email", actorEmail,
+			"patient_email", patientEmail,
+			"err", err,
+		)
+	}
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// AccessAuditLog 看护人/医生访问患者数据的审计日志，拒绝访问的请求同样记录
+type AccessAuditLog struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	CreatedAt    time.Time `gorm:"not null;index:idx_patient_created,priority:2" json:"created_at"`
+	ActorEmail   string    `gorm:"not null;index:idx_actor" json:"actor_email"`
+	ActorRole    string    `gorm:"not null" json:"actor_role"`
+	PatientEmail string    `gorm:"not null;index:idx_patient_created,priority:1" json:"patient_email"`
+
+	// 访问的数据范围
+	Scope  string `gorm:"not null" json:"scope"`
+	Method string `gorm:"not null" json:"method"`
+	Path   string `gorm:"not null" json:"path"`
+	IP     string `gorm:"not null" json:"ip"`
+
+	// 响应状态码，403 表示未授权的访问
+	StatusCode int `gorm:"not null" json:"status_code"`
+}
+
+func (AccessAuditLog) TableName() string {
+	return "access_audit_log"
+}
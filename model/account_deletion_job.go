@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+const (
+	// 冷静期内，到期后执行删除，期间用户可撤销
+	AccountDeletionStatusPending = "pending"
+
+	// 用户已撤销，或用户记录删除后同一邮箱已重新注册而终止
+	AccountDeletionStatusCanceled = "canceled"
+
+	// 删除中
+	AccountDeletionStatusProcessing = "processing"
+
+	// 删除完成，复查无残留数据
+	AccountDeletionStatusCompleted = "completed"
+
+	// 删除失败，定时任务会再次投递
+	AccountDeletionStatusFailed = "failed"
+)
+
+// AccountDeletionJob 账号注销任务，删除完成后保留任务记录及删除报告，用于核查注销结果
+type AccountDeletionJob struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null" json:"updated_at"`
+	UserEmail string    `gorm:"not null;index:idx_email" json:"user_email"`
+	Status    string    `gorm:"not null;type:enum('pending','canceled','processing','completed','failed');default:pending;index:idx_status_scheduled" json:"status"`
+
+	// 冷静期结束时间，到期后执行删除
+	ScheduledAt time.Time `gorm:"not null;index:idx_status_scheduled" json:"scheduled_at"`
+
+	// 已执行次数，包括 MQ 重试
+	Attempts int `gorm:"not null;default:0" json:"attempts"`
+
+	// 最近一次执行失败的原因
+	LastError string `gorm:"type:text" json:"last_error"`
+
+	// 删除报告，失败时记录已完成的部分
+	Report *AccountDeletionReport `gorm:"type:json;serializer:json" json:"report"`
+
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+// AccountDeletionReport 账号注销的删除报告
+type AccountDeletionReport struct {
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+
+	// 各数据表删除的记录数
+	Tables map[string]int64 `json:"tables"`
+
+	// 各 OSS 前缀下删除的对象数
+	Objects map[string]int `json:"objects"`
+
+	// 按元数据删除的知识文件数
+	KnowledgeFiles int `json:"knowledge_files"`
+
+	// 按 user_email 删除的残留向量数
+	Vectors int64 `json:"vectors"`
+
+	// 删除的 Redis key 数
+	RedisKeys int64 `json:"redis_keys"`
+
+	// 删除后复查的残留数量，键为 table:{表名}、oss:{前缀}、milvus 或 redis，全部为 0 时任务完成
+	Remaining map[string]int64 `json:"remaining"`
+}
+
+func (AccountDeletionJob) TableName() string {
+	return "account_deletion_job"
+}
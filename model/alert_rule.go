@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+const (
+	// 血糖低于阈值
+	AlertRuleTypeBelow = "below"
+
+	// 血糖高于阈值
+	AlertRuleTypeAbove = "above"
+
+	// 连续多次读数持续上升
+	AlertRuleTypeRising = "rising"
+
+	// 空腹血糖高于阈值
+	AlertRuleTypeFastingAbove = "fasting_above"
+)
+
+type AlertRule struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null" json:"updated_at"`
+	UserEmail string    `gorm:"not null;index:idx_email" json:"user_email"`
+	Type      string    `gorm:"not null;type:enum('below','above','rising','fasting_above')" json:"type"`
+
+	// 血糖阈值(mmol/L)，rising 规则中为累计上升幅度
+	Threshold float32 `gorm:"not null" json:"threshold"`
+
+	// rising 规则的连续读数次数
+	ConsecutiveCount int `gorm:"not null" json:"consecutive_count"`
+
+	// 同一规则两次提醒的最小间隔(分钟)
+	CooldownMinutes int  `gorm:"not null" json:"cooldown_minutes"`
+	NotifyEmail     bool `gorm:"not null" json:"notify_email"`
+	Enabled         bool `gorm:"not null" json:"enabled"`
+}
+
+func (AlertRule) TableName() string {
+	return "alert_rule"
+}
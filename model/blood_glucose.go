@@ -2,6 +2,14 @@ package model
 
 import "time"
 
+const (
+	// 手动录入的血糖记录
+	GlucoseSourceManual = "manual"
+
+	// 持续葡萄糖监测(CGM)导入的血糖记录
+	GlucoseSourceCGM = "cgm"
+)
+
 type BloodGlucoseRecord struct {
 	ID           uint      `gorm:"primarykey" json:"id"`
 	CreatedAt    time.Time `gorm:"not null" json:"created_at"`
@@ -10,6 +18,8 @@ type BloodGlucoseRecord struct {
 	Value        float32   `gorm:"not null" json:"value"`
 	MeasuredAt   time.Time `gorm:"not null;index:idx_email_measured_at" json:"measured_at"`
 	DiningStatus string    `gorm:"not null;type:enum('fasting','before_breakfast','after_breakfast','before_lunch','after_lunch','before_dinner','after_dinner','bedtime','random')" json:"dining_status"`
+	Notes        string    `json:"notes"`
+	Source       string    `gorm:"not null;type:enum('manual','cgm');default:manual" json:"source"`
 }
 
 func (BloodGlucoseRecord) TableName() string {
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// 患者可授权的数据范围
+const (
+	CareScopeGlucose  = "glucose"
+	CareScopeExercise = "exercise"
+	CareScopeProfile  = "profile"
+	CareScopeReport   = "report"
+)
+
+// CareShare 患者授权看护人或医生只读访问自己的健康数据，授权由患者创建、修改和撤销。
+// 同一患者对同一被授权人只保留一条授权
+type CareShare struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	CreatedAt    time.Time `gorm:"not null" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"not null" json:"updated_at"`
+	PatientEmail string    `gorm:"not null;uniqueIndex:idx_patient_grantee" json:"patient_email"`
+	GranteeEmail string    `gorm:"not null;uniqueIndex:idx_patient_grantee;index:idx_grantee" json:"grantee_email"`
+
+	// 授权的数据范围，多个范围以逗号分隔
+	Scopes string `gorm:"not null;type:set('glucose','exercise','profile','report')" json:"scopes"`
+
+	// 授权到期时间，为空时长期有效
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (CareShare) TableName() string {
+	return "care_share"
+}
+
+// Active 授权是否在有效期内
+func (s *CareShare) Active() bool {
+	return s.ExpiresAt == nil || time.Now().Before(*s.ExpiresAt)
+}
+
+// HasScope 是否授权了指定数据范围
+func (s *CareShare) HasScope(scope string) bool {
+	return slices.Contains(s.ScopeList(), scope)
+}
+
+func (s *CareShare) ScopeList() []string {
+	if s.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(s.Scopes, ",")
+}
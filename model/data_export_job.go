@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+const (
+	// 已创建，等待消费
+	DataExportStatusPending = "pending"
+
+	// 导出中
+	DataExportStatusProcessing = "processing"
+
+	// 导出文件已生成
+	DataExportStatusCompleted = "completed"
+
+	// 导出失败，MQ 重试时可再次领取
+	DataExportStatusFailed = "failed"
+)
+
+// DataExportJob 账号数据导出任务，将用户的所有数据打包为 ZIP 存储在 OSS
+type DataExportJob struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null" json:"updated_at"`
+	UserEmail string    `gorm:"not null;index:idx_email" json:"user_email"`
+	Status    string    `gorm:"not null;type:enum('pending','processing','completed','failed');default:pending" json:"status"`
+
+	// 最近一次导出失败的原因
+	Error string `gorm:"type:text" json:"error"`
+
+	// 导出文件，未生成时为空
+	ObjectName string `gorm:"not null;default:''" json:"object_name"`
+	FileName   string `gorm:"not null;default:''" json:"file_name"`
+}
+
+func (DataExportJob) TableName() string {
+	return "data_export_job"
+}
@@ -36,6 +36,12 @@ type HealthProfile struct {
 
 	// 并发症情况
 	Complications string `gorm:"type:text" json:"complications"`
+
+	// 血糖目标范围(mmol/L)，为 0 时使用默认值
+	GlucoseVeryLow  float32 `gorm:"not null;default:0" json:"glucose_very_low"`
+	GlucoseLow      float32 `gorm:"not null;default:0" json:"glucose_low"`
+	GlucoseHigh     float32 `gorm:"not null;default:0" json:"glucose_high"`
+	GlucoseVeryHigh float32 `gorm:"not null;default:0" json:"glucose_very_high"`
 }
 
 func (HealthProfile) TableName() string {
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// HealthProfileHistory 健康档案的变更记录，每次创建或更新档案生成一个版本
+type HealthProfileHistory struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+	UserEmail string    `gorm:"not null;uniqueIndex:idx_email_version" json:"user_email"`
+	Version   int       `gorm:"not null;uniqueIndex:idx_email_version" json:"version"`
+
+	// 本次变更的字段，创建档案时为所有已填写的字段
+	Changes []HealthProfileChange `gorm:"type:json;serializer:json" json:"changes"`
+}
+
+type HealthProfileChange struct {
+	// 字段名，与健康档案的 JSON 字段名一致
+	Field    string `json:"field"`
+	OldValue any    `json:"old_value"`
+	NewValue any    `json:"new_value"`
+}
+
+func (HealthProfileHistory) TableName() string {
+	return "health_profile_history"
+}
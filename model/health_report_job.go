@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+const (
+	// 已创建，等待消费
+	ReportJobStatusPending = "pending"
+
+	// 执行中
+	ReportJobStatusRunning = "running"
+
+	// 报告已生成
+	ReportJobStatusCompleted = "completed"
+
+	// 执行失败，MQ 重试或用户重新执行时可再次领取
+	ReportJobStatusFailed = "failed"
+)
+
+const (
+	// 数据充足，生成完整报告
+	ReportDecisionGenerated = "generated"
+
+	// 数据不足，生成模板化的提示报告
+	ReportDecisionPlaceholder = "placeholder"
+
+	// 数据不足，按用户设置跳过报告
+	ReportDecisionSkipped = "skipped"
+)
+
+// HealthReportJob 定时生成健康报告的用户级任务，同一用户同一周期只有一条任务，
+// 用于去重、断点续跑以及单个用户的重新生成
+type HealthReportJob struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time `gorm:"not null" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"not null" json:"updated_at"`
+	UserEmail  string    `gorm:"not null;uniqueIndex:idx_email_period_start" json:"user_email"`
+	PeriodType string    `gorm:"not null;type:enum('weekly','monthly','quarterly');uniqueIndex:idx_email_period_start" json:"period_type"`
+	StartAt    time.Time `gorm:"not null;uniqueIndex:idx_email_period_start" json:"start_at"`
+	EndAt      time.Time `gorm:"not null" json:"end_at"`
+	Status     string    `gorm:"not null;type:enum('pending','running','completed','failed');default:pending;index:idx_status" json:"status"`
+
+	// 已执行次数，包括 MQ 重试
+	Attempts int `gorm:"not null;default:0" json:"attempts"`
+
+	// 最近一次执行失败的原因
+	LastError string `gorm:"type:text" json:"last_error"`
+
+	// 数据充足性判断结果，任务完成前为空
+	Decision string `gorm:"not null;default:''" json:"decision"`
+
+	// 生成的报告，未生成或跳过时为空
+	ReportID *uint `json:"report_id"`
+}
+
+func (HealthReportJob) TableName() string {
+	return "health_report_job"
+}
@@ -2,15 +2,51 @@ package model
 
 import "time"
 
+const (
+	ReportPeriodWeekly    = "weekly"
+	ReportPeriodMonthly   = "monthly"
+	ReportPeriodQuarterly = "quarterly"
+	ReportPeriodCustom    = "custom"
+)
+
+const (
+	// 等待生成
+	ReportStatusPending = "pending"
+
+	// 生成中
+	ReportStatusGenerating = "generating"
+
+	// 已生成
+	ReportStatusCompleted = "completed"
+
+	// 生成失败
+	ReportStatusFailed = "failed"
+)
+
+// HealthWeeklyReport 健康报告，包括定时生成的周报/月报/季报和用户按需生成的自定义周期报告。
+// 同一用户同一周期同一时间范围只保留一份报告，月报与周报的开始时间可能相同，因此唯一索引包含周期类型
 type HealthWeeklyReport struct {
 	ID         uint      `gorm:"primarykey" json:"id"`
 	CreatedAt  time.Time `gorm:"not null" json:"created_at"`
 	UpdatedAt  time.Time `gorm:"not null" json:"updated_at"`
-	UserEmail  string    `gorm:"not null;index:idx_email_start_at" json:"user_email"`
-	StartAt    time.Time `gorm:"not null;index:idx_email_start_at" json:"start_at"`
-	EndAt      time.Time `gorm:"not null" json:"end_at"`
-	ObjectName string    `gorm:"not null" json:"object_name"`
-	FileName   string    `gorm:"not null" json:"file_name"`
+	UserEmail  string    `gorm:"not null;uniqueIndex:idx_email_period_range" json:"user_email"`
+	PeriodType string    `gorm:"not null;type:enum('weekly','monthly','quarterly','custom');default:weekly;uniqueIndex:idx_email_period_range" json:"period_type"`
+	StartAt    time.Time `gorm:"not null;uniqueIndex:idx_email_period_range" json:"start_at"`
+	EndAt      time.Time `gorm:"not null;uniqueIndex:idx_email_period_range" json:"end_at"`
+	Status     string    `gorm:"not null;type:enum('pending','generating','completed','failed');default:completed" json:"status"`
+
+	// 最近一次生成失败的原因
+	Error string `gorm:"type:text" json:"error"`
+
+	ObjectName string `gorm:"not null" json:"object_name"`
+	FileName   string `gorm:"not null" json:"file_name"`
+
+	// PDF 版本与 HTML 存放在同一目录，未生成时为空
+	PDFObjectName string `gorm:"not null;default:''" json:"pdf_object_name"`
+	PDFFileName   string `gorm:"not null;default:''" json:"pdf_file_name"`
+
+	// 通知用户的时间，未通知时为空，重新执行任务时不再重复通知
+	NotifiedAt *time.Time `json:"notified_at"`
 }
 
 func (HealthWeeklyReport) TableName() string {
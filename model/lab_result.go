@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+const (
+	LabKindHbA1c            = "hba1c"
+	LabKindTotalCholesterol = "total_cholesterol"
+	LabKindLDL              = "ldl"
+	LabKindHDL              = "hdl"
+	LabKindTriglycerides    = "triglycerides"
+	LabKindCreatinine       = "creatinine"
+	LabKindEGFR             = "egfr"
+	LabKindUACR             = "uacr"
+)
+
+// LabKind 检验项目的名称、单位及默认参考范围，参考范围的上/下限为 nil 表示无限制
+type LabKind struct {
+	Name          string
+	Unit          string
+	ReferenceLow  *float32
+	ReferenceHigh *float32
+}
+
+// LabKinds 支持的检验项目，数值统一使用对应单位录入
+var LabKinds = map[string]LabKind{
+	LabKindHbA1c:            {Name: "糖化血红蛋白", Unit: "%", ReferenceLow: ptr(4.0), ReferenceHigh: ptr(6.0)},
+	LabKindTotalCholesterol: {Name: "总胆固醇", Unit: "mmol/L", ReferenceHigh: ptr(5.2)},
+	LabKindLDL:              {Name: "低密度脂蛋白胆固醇", Unit: "mmol/L", ReferenceHigh: ptr(3.4)},
+	LabKindHDL:              {Name: "高密度脂蛋白胆固醇", Unit: "mmol/L", ReferenceLow: ptr(1.0)},
+	LabKindTriglycerides:    {Name: "甘油三酯", Unit: "mmol/L", ReferenceHigh: ptr(1.7)},
+	LabKindCreatinine:       {Name: "血肌酐", Unit: "μmol/L", ReferenceLow: ptr(44), ReferenceHigh: ptr(133)},
+	LabKindEGFR:             {Name: "估算肾小球滤过率", Unit: "mL/min/1.73m²", ReferenceLow: ptr(90)},
+	LabKindUACR:             {Name: "尿白蛋白/肌酐比值", Unit: "mg/g", ReferenceHigh: ptr(30)},
+}
+
+type LabResult struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null" json:"updated_at"`
+	UserEmail string    `gorm:"not null;index:idx_email_kind_tested_at" json:"user_email"`
+	Kind      string    `gorm:"not null;index:idx_email_kind_tested_at;type:enum('hba1c','total_cholesterol','ldl','hdl','triglycerides','creatinine','egfr','uacr')" json:"kind"`
+	Value     float32   `gorm:"not null" json:"value"`
+	Unit      string    `gorm:"not null" json:"unit"`
+
+	// 检验报告上的参考范围，未填写时使用默认参考范围
+	ReferenceLow  *float32 `json:"reference_low"`
+	ReferenceHigh *float32 `json:"reference_high"`
+
+	TestedAt time.Time `gorm:"not null;index:idx_email_kind_tested_at" json:"tested_at"`
+	Notes    string    `json:"notes"`
+}
+
+func (LabResult) TableName() string {
+	return "lab_result"
+}
+
+func ptr(v float32) *float32 {
+	return &v
+}
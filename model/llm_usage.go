@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// LLMUsage 单次 LLM 调用的 token 用量，回退链中每次成功调用的模型各记录一条
+type LLMUsage struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"not null;index:idx_email_created" json:"created_at"`
+
+	// 系统发起且无法关联用户的调用为空
+	UserEmail string `gorm:"not null;default:'';index:idx_email_created" json:"user_email"`
+	SessionID string `gorm:"not null;default:''" json:"session_id"`
+
+	// 任务类型，如 chat、summarize、report
+	Task     string `gorm:"not null" json:"task"`
+	Provider string `gorm:"not null" json:"provider"`
+	Model    string `gorm:"not null" json:"model"`
+
+	PromptTokens     int `gorm:"not null;default:0" json:"prompt_tokens"`
+	CompletionTokens int `gorm:"not null;default:0" json:"completion_tokens"`
+	TotalTokens      int `gorm:"not null;default:0" json:"total_tokens"`
+
+	// 按模型配置的单价计算的费用(元)，未配置单价时为 0
+	Cost float64 `gorm:"not null;type:decimal(12,6);default:0" json:"cost"`
+}
+
+func (LLMUsage) TableName() string {
+	return "llm_usage"
+}
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// MealRecord 饮食记录
+// 建立联合索引 (user_email, eaten_at)
+type MealRecord struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null" json:"updated_at"`
+	UserEmail string    `gorm:"not null;index:idx_email_eaten_at" json:"user_email"`
+	MealType  string    `gorm:"not null;type:enum('breakfast','lunch','dinner','snack')" json:"meal_type"`
+	EatenAt   time.Time `gorm:"not null;index:idx_email_eaten_at" json:"eaten_at"`
+
+	// 食物明细
+	Foods []MealFood `gorm:"type:json;serializer:json" json:"foods"`
+
+	// 碳水化合物总量(g)
+	Carbohydrate float32 `gorm:"not null" json:"carbohydrate"`
+
+	// 总热量(kcal)
+	Calories float32 `gorm:"not null" json:"calories"`
+
+	Notes string `json:"notes"`
+}
+
+type MealFood struct {
+	Name string `json:"name"`
+
+	// 食用量，如 "1碗"、"150g"
+	Amount string `json:"amount"`
+
+	Carbohydrate float32 `json:"carbohydrate"`
+	Calories     float32 `json:"calories"`
+}
+
+func (MealRecord) TableName() string {
+	return "meal_record"
+}
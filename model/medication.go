@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+const (
+	IntakeStatusTaken   = "taken"
+	IntakeStatusSkipped = "skipped"
+)
+
+// MedicationPlan 用药计划
+type MedicationPlan struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null" json:"updated_at"`
+	UserEmail string    `gorm:"not null;index:idx_email" json:"user_email"`
+	DrugName  string    `gorm:"not null" json:"drug_name"`
+	DrugType  string    `gorm:"not null;type:enum('oral','insulin','glp1','other')" json:"drug_type"`
+
+	// 单次剂量及单位，如 500 mg、8 U
+	Dose     float32 `gorm:"not null" json:"dose"`
+	DoseUnit string  `gorm:"not null" json:"dose_unit"`
+
+	// 每日服药时间点，格式为 HH:MM（UTC）
+	ScheduleTimes []string `gorm:"type:json;serializer:json" json:"schedule_times"`
+
+	StartDate time.Time `gorm:"not null" json:"start_date"`
+
+	// 停药日期，为空表示计划仍在执行
+	EndDate *time.Time `json:"end_date"`
+
+	Notes string `json:"notes"`
+}
+
+func (MedicationPlan) TableName() string {
+	return "medication_plan"
+}
+
+// MedicationIntake 服药/注射记录
+// 建立联合索引 (user_email, taken_at)
+type MedicationIntake struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null" json:"updated_at"`
+	UserEmail string    `gorm:"not null;index:idx_email_taken_at" json:"user_email"`
+
+	// 关联的用药计划，计划外用药时为 0
+	PlanID uint `gorm:"not null;index:idx_plan" json:"plan_id"`
+
+	DrugName string    `gorm:"not null" json:"drug_name"`
+	Dose     float32   `gorm:"not null" json:"dose"`
+	DoseUnit string    `gorm:"not null" json:"dose_unit"`
+	TakenAt  time.Time `gorm:"not null;index:idx_email_taken_at" json:"taken_at"`
+	Status   string    `gorm:"not null;type:enum('taken','skipped')" json:"status"`
+	Notes    string    `json:"notes"`
+}
+
+func (MedicationIntake) TableName() string {
+	return "medication_intake"
+}
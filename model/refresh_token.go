@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// RefreshToken 服务端存储的刷新令牌，仅保存令牌的 SHA-256 摘要。
+// 每次刷新时吊销旧令牌并签发新令牌
+type RefreshToken struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null" json:"updated_at"`
+	UserEmail string    `gorm:"not null;index:idx_email" json:"user_email"`
+	TokenHash string    `gorm:"not null;size:64;uniqueIndex:idx_token_hash" json:"-"`
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+	Revoked   bool      `gorm:"not null;default:false" json:"revoked"`
+}
+
+func (RefreshToken) TableName() string {
+	return "refresh_token"
+}
@@ -4,6 +4,28 @@ import (
 	"time"
 )
 
+const (
+	// 数据不足时不生成报告
+	InsufficientDataPolicySkip = "skip"
+
+	// 数据不足时生成模板化的提示报告，不调用 LLM 分析
+	InsufficientDataPolicyPlaceholder = "placeholder"
+)
+
+const (
+	// 患者，管理自己的健康数据，可授权看护人/医生查看
+	RolePatient = "patient"
+
+	// 家属看护人，经患者授权后只读查看患者数据
+	RoleCaregiver = "caregiver"
+
+	// 医生，经患者授权后只读查看患者数据
+	RoleClinician = "clinician"
+
+	// 管理员
+	RoleAdmin = "admin"
+)
+
 type User struct {
 	ID                             uint      `gorm:"primarykey" json:"id"`
 	CreatedAt                      time.Time `gorm:"not null" json:"created_at"`
@@ -12,6 +34,18 @@ type User struct {
 	Password                       string    `gorm:"not null" json:"-"`
 	Avatar                         string    `gorm:"not null" json:"avatar"`
 	EnableWeeklyReportNotification bool      `gorm:"not null" json:"enable_weekly_report_notification"`
+
+	// 邮箱是否已通过验证码验证，未验证的用户不发送邮件通知
+	EmailVerified bool `gorm:"not null;default:false" json:"email_verified"`
+
+	// 被管理员禁用的用户不可登录和刷新令牌
+	Disabled bool `gorm:"not null;default:false" json:"disabled"`
+
+	// 用户角色，决定可访问的路由组
+	Role string `gorm:"not null;type:enum('patient','caregiver','clinician','admin');default:patient" json:"role"`
+
+	// 定时报告周期内数据不足时的处理方式
+	InsufficientDataPolicy string `gorm:"not null;type:enum('skip','placeholder');default:placeholder" json:"insufficient_data_policy"`
 }
 
 func (User) TableName() string {
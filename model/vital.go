@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// VitalRecord 体征记录，一次测量可只记录部分指标，未测量的指标为 nil
+type VitalRecord struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time `gorm:"not null" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"not null" json:"updated_at"`
+	UserEmail  string    `gorm:"not null;index:idx_email_measured_at" json:"user_email"`
+	MeasuredAt time.Time `gorm:"not null;index:idx_email_measured_at" json:"measured_at"`
+
+	// 体重(kg)
+	Weight *float32 `json:"weight"`
+
+	// 收缩压/舒张压(mmHg)
+	Systolic  *int `json:"systolic"`
+	Diastolic *int `json:"diastolic"`
+
+	// 心率(次/分)
+	HeartRate *int `json:"heart_rate"`
+
+	// 腰围(cm)
+	Waist *float32 `json:"waist"`
+
+	Notes string `json:"notes"`
+}
+
+func (VitalRecord) TableName() string {
+	return "vital_record"
+}
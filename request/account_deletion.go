@@ -0,0 +1,6 @@
+package request
+
+// CreateAccountDeletionRequest 申请注销账号，需再次输入密码确认
+type CreateAccountDeletionRequest struct {
+	Password string `json:"password" binding:"required"`
+}
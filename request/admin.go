@@ -0,0 +1,26 @@
+package request
+
+type UpdateUserDisabledRequest struct {
+	Disabled bool `json:"disabled"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=patient caregiver clinician admin"`
+}
+
+// RegenerateHealthReportRequest 重新生成用户 date 所在周期的定时报告
+type RegenerateHealthReportRequest struct {
+	Email      string `json:"email" binding:"required,email"`
+	PeriodType string `json:"period_type" binding:"required,oneof=weekly monthly quarterly"`
+
+	// 周期内的任意日期，格式为 YYYY-MM-DD
+	Date string `json:"date" binding:"required,datetime=2006-01-02"`
+}
+
+// BroadcastSystemMessageRequest 向用户群发系统消息，emails 和 role 均为空时发送给所有未禁用的用户
+type BroadcastSystemMessageRequest struct {
+	Title   string   `json:"title" binding:"required,max=255"`
+	Content string   `json:"content" binding:"required"`
+	Role    string   `json:"role" binding:"omitempty,oneof=patient caregiver clinician admin"`
+	Emails  []string `json:"emails" binding:"omitempty,dive,email"`
+}
@@ -0,0 +1,12 @@
+package request
+
+type AlertRuleRequest struct {
+	Type             string  `json:"type" binding:"required,oneof=below above rising fasting_above"`
+	Threshold        float32 `json:"threshold" binding:"gte=0,lte=50"`
+	ConsecutiveCount int     `json:"consecutive_count" binding:"omitempty,gte=2,lte=10"`
+	CooldownMinutes  int     `json:"cooldown_minutes" binding:"gte=0,lte=1440"`
+	NotifyEmail      bool    `json:"notify_email"`
+
+	// 未填写时默认启用
+	Enabled *bool `json:"enabled"`
+}
@@ -2,7 +2,13 @@ package request
 
 type UserRegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required,password"`
+
+	// 通过 type 为 register 的 /user/code 请求发送的验证码
+	Code string `json:"code" binding:"required"`
+
+	// 注册角色，默认为患者；医生和管理员角色不可自行注册
+	Role string `json:"role" binding:"omitempty,oneof=patient caregiver"`
 }
 
 type UserLoginRequest struct {
@@ -12,6 +18,33 @@ type UserLoginRequest struct {
 	Type     string `json:"type" binding:"required"`
 }
 
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,password"`
+}
+
+// ResetPasswordRequest 通过邮箱验证码重置密码，验证码由 /user/code 发送
+type ResetPasswordRequest struct {
+	Email       string `json:"email" binding:"required,email"`
+	Code        string `json:"code" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,password"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
 type SendEmailCodeRequest struct {
 	Email string `json:"email" binding:"required,email"`
+
+	// 验证码用途，register 用于注册，仅可发送至未注册的邮箱；为空时仅可发送至已注册的邮箱
+	Type string `json:"type" binding:"omitempty,oneof=register"`
+}
+
+type VerifyEmailRequest struct {
+	Code string `json:"code" binding:"required"`
 }
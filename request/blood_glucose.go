@@ -2,8 +2,22 @@ package request
 
 import "time"
 
-type CreateBloodGlucoseRecordRequest struct {
+type BloodGlucoseRecordRequest struct {
 	Value        float32   `json:"value" binding:"required,gte=1,lte=50"`
 	MeasuredAt   time.Time `json:"measured_at" binding:"required"`
-	DiningStatus string    `json:"dining_status" binding:"required"`
+	DiningStatus string    `json:"dining_status" binding:"required,oneof=fasting before_breakfast after_breakfast before_lunch after_lunch before_dinner after_dinner bedtime random"`
+	Notes        string    `json:"notes"`
+}
+
+type BatchCreateBloodGlucoseRecordsRequest struct {
+	// 不校验单条记录，由接口逐条校验并返回结果
+	Records []BloodGlucoseRecordRequest `json:"records" binding:"required,min=1,max=500"`
+}
+
+type ImportCGMDataRequest struct {
+	// 已上传至 OSS 的 CGM 导出文件名
+	FileName string `json:"file_name" binding:"required"`
+
+	// CSV 中时间戳所在的时区，默认为 UTC
+	Timezone string `json:"timezone"`
 }
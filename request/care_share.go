@@ -0,0 +1,15 @@
+package request
+
+import "time"
+
+type CreateCareShareRequest struct {
+	// 被授权人需已注册为看护人或医生
+	GranteeEmail string     `json:"grantee_email" binding:"required,email"`
+	Scopes       []string   `json:"scopes" binding:"required,min=1,dive,oneof=glucose exercise profile report"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+}
+
+type UpdateCareShareRequest struct {
+	Scopes    []string   `json:"scopes" binding:"required,min=1,dive,oneof=glucose exercise profile report"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
@@ -1,17 +1,24 @@
 package request
 
+// HealthProfileRequest 创建或更新健康档案，更新时仅修改请求中包含的字段
 type HealthProfileRequest struct {
-	Gender            string  `json:"gender"`
-	Age               int     `json:"age"`
-	Height            float32 `json:"height"`
-	Weight            float32 `json:"weight"`
-	DietaryPreference string  `json:"dietary_preference"`
-	SmokingStatus     bool    `json:"smoking_status"`
-	ActivityLevel     string  `json:"activity_level"`
-	DiabetesType      string  `json:"diabetes_type"`
-	DiagnosisYear     int     `json:"diagnosis_year"`
-	TherapyMode       string  `json:"therapy_mode"`
-	Medication        string  `json:"medication"`
-	Allergies         string  `json:"allergies"`
-	Complications     string  `json:"complications"`
+	Gender            *string  `json:"gender"`
+	Age               *int     `json:"age"`
+	Height            *float32 `json:"height"`
+	Weight            *float32 `json:"weight"`
+	DietaryPreference *string  `json:"dietary_preference"`
+	SmokingStatus     *bool    `json:"smoking_status"`
+	ActivityLevel     *string  `json:"activity_level"`
+	DiabetesType      *string  `json:"diabetes_type"`
+	DiagnosisYear     *int     `json:"diagnosis_year"`
+	TherapyMode       *string  `json:"therapy_mode"`
+	Medication        *string  `json:"medication"`
+	Allergies         *string  `json:"allergies"`
+	Complications     *string  `json:"complications"`
+
+	// 血糖目标范围，为 0 时恢复默认值
+	GlucoseVeryLow  *float32 `json:"glucose_very_low" binding:"omitempty,eq=0|gte=1,lte=50"`
+	GlucoseLow      *float32 `json:"glucose_low" binding:"omitempty,eq=0|gte=1,lte=50"`
+	GlucoseHigh     *float32 `json:"glucose_high" binding:"omitempty,eq=0|gte=1,lte=50"`
+	GlucoseVeryHigh *float32 `json:"glucose_very_high" binding:"omitempty,eq=0|gte=1,lte=50"`
 }
@@ -3,3 +3,14 @@ package request
 type UpdateUserEnableNotificationRequest struct {
 	EnableWeeklyReportNotification bool `json:"enable_weekly_report_notification"`
 }
+
+// UpdateInsufficientDataPolicyRequest 设置定时报告数据不足时的处理方式
+type UpdateInsufficientDataPolicyRequest struct {
+	Policy string `json:"policy" binding:"required,oneof=skip placeholder"`
+}
+
+// GenerateHealthReportRequest 按需生成自定义周期的健康报告
+type GenerateHealthReportRequest struct {
+	Start string `json:"start" binding:"required"`
+	End   string `json:"end" binding:"required"`
+}
@@ -0,0 +1,15 @@
+package request
+
+import "time"
+
+type LabResultRequest struct {
+	Kind     string    `json:"kind" binding:"required,oneof=hba1c total_cholesterol ldl hdl triglycerides creatinine egfr uacr"`
+	Value    float32   `json:"value" binding:"required,gt=0"`
+	TestedAt time.Time `json:"tested_at" binding:"required"`
+
+	// 检验报告上的参考范围，可选
+	ReferenceLow  *float32 `json:"reference_low" binding:"omitempty,gte=0"`
+	ReferenceHigh *float32 `json:"reference_high" binding:"omitempty,gte=0"`
+
+	Notes string `json:"notes"`
+}
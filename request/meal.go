@@ -0,0 +1,19 @@
+package request
+
+import "time"
+
+type MealRecordRequest struct {
+	MealType     string            `json:"meal_type" binding:"required,oneof=breakfast lunch dinner snack"`
+	EatenAt      time.Time         `json:"eaten_at" binding:"required"`
+	Foods        []MealFoodRequest `json:"foods" binding:"required,min=1,dive"`
+	Carbohydrate float32           `json:"carbohydrate" binding:"gte=0"`
+	Calories     float32           `json:"calories" binding:"gte=0"`
+	Notes        string            `json:"notes"`
+}
+
+type MealFoodRequest struct {
+	Name         string  `json:"name" binding:"required"`
+	Amount       string  `json:"amount"`
+	Carbohydrate float32 `json:"carbohydrate" binding:"gte=0"`
+	Calories     float32 `json:"calories" binding:"gte=0"`
+}
@@ -0,0 +1,24 @@
+package request
+
+import "time"
+
+type MedicationPlanRequest struct {
+	DrugName      string     `json:"drug_name" binding:"required"`
+	DrugType      string     `json:"drug_type" binding:"required,oneof=oral insulin glp1 other"`
+	Dose          float32    `json:"dose" binding:"required,gt=0"`
+	DoseUnit      string     `json:"dose_unit" binding:"required"`
+	ScheduleTimes []string   `json:"schedule_times" binding:"required,min=1,dive,datetime=15:04"`
+	StartDate     time.Time  `json:"start_date" binding:"required"`
+	EndDate       *time.Time `json:"end_date"`
+	Notes         string     `json:"notes"`
+}
+
+type MedicationIntakeRequest struct {
+	PlanID   uint      `json:"plan_id"`
+	DrugName string    `json:"drug_name"`
+	Dose     float32   `json:"dose" binding:"gte=0"`
+	DoseUnit string    `json:"dose_unit"`
+	TakenAt  time.Time `json:"taken_at" binding:"required"`
+	Status   string    `json:"status" binding:"required,oneof=taken skipped"`
+	Notes    string    `json:"notes"`
+}
@@ -1,5 +1,7 @@
 package request
 
+import "time"
+
 // OSSAuthRequest 用于生成 Object Key
 type OSSAuthRequest struct {
 	Namespace       string `json:"namespace"`
@@ -7,4 +9,7 @@ type OSSAuthRequest struct {
 	SessionID       string `json:"session_id"`
 	FileName        string `json:"file_name"`
 	UseCustomDomain bool   `json:"use_custom_domain"`
+
+	// 预签名 URL 的有效期，为 0 时使用默认有效期
+	Expires time.Duration `json:"-"`
 }
@@ -0,0 +1,42 @@
+package request
+
+import (
+	"unicode"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	passwordMinLength = 8
+	passwordMaxLength = 64
+
+	// bcrypt 仅使用密码的前 72 字节，超出部分会被忽略
+	passwordMaxBytes = 72
+)
+
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterValidation("password", validatePassword)
+	}
+}
+
+// 密码长度为 8-64 位且不超过 72 字节，同时包含字母和数字
+func validatePassword(fl validator.FieldLevel) bool {
+	password := fl.Field().String()
+	length := len([]rune(password))
+	if length < passwordMinLength || length > passwordMaxLength || len(password) > passwordMaxBytes {
+		return false
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	return hasLetter && hasDigit
+}
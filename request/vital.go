@@ -0,0 +1,13 @@
+package request
+
+import "time"
+
+type VitalRecordRequest struct {
+	MeasuredAt time.Time `json:"measured_at" binding:"required"`
+	Weight     *float32  `json:"weight" binding:"omitempty,gte=10,lte=500"`
+	Systolic   *int      `json:"systolic" binding:"omitempty,gte=40,lte=300"`
+	Diastolic  *int      `json:"diastolic" binding:"omitempty,gte=20,lte=200"`
+	HeartRate  *int      `json:"heart_rate" binding:"omitempty,gte=20,lte=250"`
+	Waist      *float32  `json:"waist" binding:"omitempty,gte=30,lte=300"`
+	Notes      string    `json:"notes"`
+}
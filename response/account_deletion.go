@@ -0,0 +1,27 @@
+package response
+
+import "time"
+
+type AccountDeletionResponse struct {
+	ID          uint      `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	Status      string    `json:"status"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+}
+
+type GetAccountDeletionJobsResponse struct {
+	Total int64                        `json:"total"`
+	Jobs  []AccountDeletionJobResponse `json:"jobs"`
+}
+
+type AccountDeletionJobResponse struct {
+	ID          uint       `json:"id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	UserEmail   string     `json:"user_email"`
+	Status      string     `json:"status"`
+	ScheduledAt time.Time  `json:"scheduled_at"`
+	Attempts    int        `json:"attempts"`
+	LastError   string     `json:"last_error"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
@@ -0,0 +1,26 @@
+package response
+
+import "time"
+
+type GetUsersResponse struct {
+	Total int64          `json:"total"`
+	Users []UserResponse `json:"users"`
+}
+
+type UserResponse struct {
+	ID                             uint      `json:"id"`
+	CreatedAt                      time.Time `json:"created_at"`
+	Email                          string    `json:"email"`
+	Role                           string    `json:"role"`
+	EmailVerified                  bool      `json:"email_verified"`
+	Disabled                       bool      `json:"disabled"`
+	EnableWeeklyReportNotification bool      `json:"enable_weekly_report_notification"`
+}
+
+type ReprocessKnowledgeMetadataResponse struct {
+	Count int `json:"count"`
+}
+
+type BroadcastSystemMessageResponse struct {
+	Count int `json:"count"`
+}
@@ -0,0 +1,11 @@
+package response
+
+type GetAlertRulesResponse struct {
+	ID               uint    `json:"id"`
+	Type             string  `json:"type"`
+	Threshold        float32 `json:"threshold"`
+	ConsecutiveCount int     `json:"consecutive_count"`
+	CooldownMinutes  int     `json:"cooldown_minutes"`
+	NotifyEmail      bool    `json:"notify_email"`
+	Enabled          bool    `json:"enabled"`
+}
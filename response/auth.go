@@ -4,5 +4,20 @@ type UserAuthResponse struct {
 	Email                          string `json:"email"`
 	Avatar                         string `json:"avatar"`
 	EnableWeeklyReportNotification bool   `json:"enable_weekly_report_notification"`
+	InsufficientDataPolicy         string `json:"insufficient_data_policy"`
+	EmailVerified                  bool   `json:"email_verified"`
+	Role                           string `json:"role"`
 	Token                          string `json:"token"`
+	RefreshToken                   string `json:"refresh_token"`
+
+	// 访问令牌有效期(秒)
+	ExpiresIn int `json:"expires_in"`
+}
+
+type TokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+
+	// 访问令牌有效期(秒)
+	ExpiresIn int `json:"expires_in"`
 }
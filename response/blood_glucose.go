@@ -3,7 +3,24 @@ package response
 import "time"
 
 type GetBloodGlucoseRecordsResponse struct {
+	ID           uint      `json:"id"`
 	Value        float32   `json:"value"`
 	MeasuredAt   time.Time `json:"measured_at"`
 	DiningStatus string    `json:"dining_status"`
+	Notes        string    `json:"notes"`
+	Source       string    `json:"source"`
+}
+
+type BatchCreateBloodGlucoseRecordsResponse struct {
+	Created int                                 `json:"created"`
+	Failed  int                                 `json:"failed"`
+	Results []BatchCreateBloodGlucoseItemResult `json:"results"`
+}
+
+// BatchCreateBloodGlucoseItemResult 批量录入中单条记录的处理结果，Index 对应请求中的下标
+type BatchCreateBloodGlucoseItemResult struct {
+	Index   int    `json:"index"`
+	Success bool   `json:"success"`
+	ID      uint   `json:"id,omitempty"`
+	Error   string `json:"error,omitempty"`
 }
@@ -0,0 +1,30 @@
+package response
+
+import "time"
+
+type CareShareResponse struct {
+	ID           uint       `json:"id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	PatientEmail string     `json:"patient_email"`
+	GranteeEmail string     `json:"grantee_email"`
+	Scopes       []string   `json:"scopes"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	Active       bool       `json:"active"`
+}
+
+type GetAccessAuditLogsResponse struct {
+	Total int64                    `json:"total"`
+	Logs  []AccessAuditLogResponse `json:"logs"`
+}
+
+type AccessAuditLogResponse struct {
+	ID         uint      `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	ActorEmail string    `json:"actor_email"`
+	ActorRole  string    `json:"actor_role"`
+	Scope      string    `json:"scope"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	IP         string    `json:"ip"`
+	StatusCode int       `json:"status_code"`
+}
@@ -0,0 +1,16 @@
+package response
+
+import "time"
+
+type CreateDataExportResponse struct {
+	ID uint `json:"id"`
+}
+
+type GetDataExportJobsResponse struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error"`
+	FileName  string    `json:"file_name"`
+}
@@ -1,5 +1,10 @@
 package response
 
+import (
+	"diabetes-agent-server/model"
+	"time"
+)
+
 type GetHealthProfileResponse struct {
 	Gender            string  `json:"gender"`
 	Age               int     `json:"age"`
@@ -14,4 +19,27 @@ type GetHealthProfileResponse struct {
 	Medication        string  `json:"medication"`
 	Allergies         string  `json:"allergies"`
 	Complications     string  `json:"complications"`
+	GlucoseVeryLow    float32 `json:"glucose_very_low"`
+	GlucoseLow        float32 `json:"glucose_low"`
+	GlucoseHigh       float32 `json:"glucose_high"`
+	GlucoseVeryHigh   float32 `json:"glucose_very_high"`
+}
+
+type GetHealthProfileHistoryResponse struct {
+	Version   int                         `json:"version"`
+	ChangedAt time.Time                   `json:"changed_at"`
+	Changes   []model.HealthProfileChange `json:"changes"`
+}
+
+// HealthProfileChangeImpact 治疗相关的档案变更及变更前后的平均血糖
+type HealthProfileChangeImpact struct {
+	ChangedAt time.Time                   `json:"changed_at"`
+	Changes   []model.HealthProfileChange `json:"changes"`
+
+	// 变更前/后的平均血糖(mmol/L)，对应时间段内无记录时为 null
+	AvgGlucoseBefore *float32 `json:"avg_glucose_before"`
+	AvgGlucoseAfter  *float32 `json:"avg_glucose_after"`
+
+	ReadingsBefore int `json:"readings_before"`
+	ReadingsAfter  int `json:"readings_after"`
 }
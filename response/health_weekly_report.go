@@ -1,7 +1,39 @@
 package response
 
+import "time"
+
 type GetHealthWeeklyReportsResponse struct {
-	StartAt  string `json:"start_at"`
-	EndAt    string `json:"end_at"`
-	FileName string `json:"file_name"`
+	StartAt     string `json:"start_at"`
+	EndAt       string `json:"end_at"`
+	FileName    string `json:"file_name"`
+	PDFFileName string `json:"pdf_file_name"`
+}
+
+type GetHealthReportsResponse struct {
+	ID          uint      `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	PeriodType  string    `json:"period_type"`
+	StartAt     time.Time `json:"start_at"`
+	EndAt       time.Time `json:"end_at"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error"`
+	FileName    string    `json:"file_name"`
+	PDFFileName string    `json:"pdf_file_name"`
+}
+
+type GenerateHealthReportResponse struct {
+	ID uint `json:"id"`
+}
+
+type GetHealthReportJobsResponse struct {
+	ID         uint      `json:"id"`
+	PeriodType string    `json:"period_type"`
+	StartAt    time.Time `json:"start_at"`
+	EndAt      time.Time `json:"end_at"`
+	Status     string    `json:"status"`
+	Attempts   int       `json:"attempts"`
+	LastError  string    `json:"last_error"`
+	Decision   string    `json:"decision"`
+	ReportID   *uint     `json:"report_id"`
+	UpdatedAt  time.Time `json:"updated_at"`
 }
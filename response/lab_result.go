@@ -0,0 +1,43 @@
+package response
+
+import "time"
+
+const (
+	LabFlagNormal = "normal"
+	LabFlagLow    = "low"
+	LabFlagHigh   = "high"
+)
+
+type GetLabResultsResponse struct {
+	ID            uint      `json:"id"`
+	Kind          string    `json:"kind"`
+	Name          string    `json:"name"`
+	Value         float32   `json:"value"`
+	Unit          string    `json:"unit"`
+	ReferenceLow  *float32  `json:"reference_low"`
+	ReferenceHigh *float32  `json:"reference_high"`
+	TestedAt      time.Time `json:"tested_at"`
+	Notes         string    `json:"notes"`
+
+	// 相对参考范围的判定结果：normal/low/high
+	Flag string `json:"flag"`
+}
+
+type GetLabResultTrendResponse struct {
+	Kind   string          `json:"kind"`
+	Name   string          `json:"name"`
+	Unit   string          `json:"unit"`
+	Points []LabTrendPoint `json:"points"`
+
+	// 最新一次检验结果，无记录时为 null
+	Latest *float32 `json:"latest"`
+
+	// 最新一次相对上一次检验的变化量，记录不足两条时为 null
+	Change *float32 `json:"change"`
+}
+
+type LabTrendPoint struct {
+	TestedAt time.Time `json:"tested_at"`
+	Value    float32   `json:"value"`
+	Flag     string    `json:"flag"`
+}
@@ -0,0 +1,11 @@
+package response
+
+type GetLLMUsageResponse struct {
+	// 统计周期，按天为 2006-01-02，按月为 2006-01
+	Period           string  `json:"period"`
+	Calls            int     `json:"calls"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	Cost             float64 `json:"cost"`
+}
@@ -0,0 +1,25 @@
+package response
+
+import (
+	"diabetes-agent-server/model"
+	"time"
+)
+
+type GetMealRecordsResponse struct {
+	ID           uint             `json:"id"`
+	MealType     string           `json:"meal_type"`
+	EatenAt      time.Time        `json:"eaten_at"`
+	Foods        []model.MealFood `json:"foods"`
+	Carbohydrate float32          `json:"carbohydrate"`
+	Calories     float32          `json:"calories"`
+	Notes        string           `json:"notes"`
+
+	// 餐前血糖，无匹配记录时为 null
+	PreGlucose *float32 `json:"pre_glucose"`
+
+	// 餐后血糖，无匹配记录时为 null
+	PostGlucose *float32 `json:"post_glucose"`
+
+	// 餐后血糖升幅，餐前/餐后血糖任一缺失时为 null
+	GlucoseRise *float32 `json:"glucose_rise"`
+}
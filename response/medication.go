@@ -0,0 +1,26 @@
+package response
+
+import "time"
+
+type GetMedicationPlansResponse struct {
+	ID            uint       `json:"id"`
+	DrugName      string     `json:"drug_name"`
+	DrugType      string     `json:"drug_type"`
+	Dose          float32    `json:"dose"`
+	DoseUnit      string     `json:"dose_unit"`
+	ScheduleTimes []string   `gorm:"serializer:json" json:"schedule_times"`
+	StartDate     time.Time  `json:"start_date"`
+	EndDate       *time.Time `json:"end_date"`
+	Notes         string     `json:"notes"`
+}
+
+type GetMedicationIntakesResponse struct {
+	ID       uint      `json:"id"`
+	PlanID   uint      `json:"plan_id"`
+	DrugName string    `json:"drug_name"`
+	Dose     float32   `json:"dose"`
+	DoseUnit string    `json:"dose_unit"`
+	TakenAt  time.Time `json:"taken_at"`
+	Status   string    `json:"status"`
+	Notes    string    `json:"notes"`
+}
@@ -0,0 +1,18 @@
+package response
+
+import "time"
+
+type GetVitalRecordsResponse struct {
+	ID         uint      `json:"id"`
+	MeasuredAt time.Time `json:"measured_at"`
+	Weight     *float32  `json:"weight"`
+
+	// 由体重和健康档案中的身高计算，缺少体重或身高时为 null
+	BMI *float32 `json:"bmi"`
+
+	Systolic  *int     `json:"systolic"`
+	Diastolic *int     `json:"diastolic"`
+	HeartRate *int     `json:"heart_rate"`
+	Waist     *float32 `json:"waist"`
+	Notes     string   `json:"notes"`
+}
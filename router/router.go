@@ -1,14 +1,22 @@
 package router
 
 import (
+	"diabetes-agent-server/config"
 	"diabetes-agent-server/controller"
 	"diabetes-agent-server/middleware"
+	"diabetes-agent-server/model"
+	"fmt"
 
 	"github.com/gin-gonic/gin"
 )
 
 func Register() *gin.Engine {
 	r := gin.Default()
+
+	// 仅信任配置的反向代理转发的客户端 IP，避免伪造 X-Forwarded-For 绕过按 IP 的登录限制
+	if err := r.SetTrustedProxies(config.Cfg.Server.TrustedProxies); err != nil {
+		panic(fmt.Sprintf("Failed to set trusted proxies: %v", err))
+	}
 	r.Use(middleware.CORSMiddleware())
 
 	api := r.Group("/api")
@@ -18,47 +26,189 @@ func Register() *gin.Engine {
 			public.POST("/register", controller.UserRegister)
 			public.POST("/login", controller.UserLogin)
 			public.POST("/code", controller.SendVerificationCode)
+			public.POST("/refresh", controller.RefreshToken)
+			public.POST("/password/reset", controller.ResetPassword)
 		}
 
 		protected := api.Group("")
 		protected.Use(middleware.AuthMiddleware())
 		{
+			protected.POST("/user/logout", controller.Logout)
+			protected.POST("/user/logout-all", controller.LogoutAll)
+			protected.PUT("/user/password", controller.ChangePassword)
+			protected.POST("/user/email/verify", controller.VerifyEmail)
+
 			protected.POST("/session", controller.CreateSession)
 			protected.GET("/sessions", controller.GetSessions)
 			protected.DELETE("/session/:id", controller.DeleteSession)
 			protected.GET("/session/:id/messages", controller.GetSessionMessages)
 			protected.PUT("/session/:id/title", controller.UpdateSessionTitle)
 
-			protected.POST("/chat", controller.AgentChat)
+			protected.POST("/chat",
+				middleware.SSERateLimitMiddleware(middleware.RateLimitGroupChat),
+				controller.AgentChat,
+			)
 
-			protected.POST("/voice-recognition", controller.ChatVoiceRecognition)
+			protected.POST("/voice-recognition",
+				middleware.RateLimitMiddleware(middleware.RateLimitGroupVoice),
+				controller.ChatVoiceRecognition,
+			)
 
 			protected.GET("/oss/policy-token", controller.GetPolicyToken)
 			protected.GET("/oss/presigned-url", controller.GetPresignedURL)
 
 			protected.GET("/kb/metadata", controller.GetKnowledgeMetadata)
-			protected.POST("/kb/metadata", controller.UploadKnowledgeMetadata)
+			protected.POST("/kb/metadata",
+				middleware.RateLimitMiddleware(middleware.RateLimitGroupKBUpload),
+				controller.UploadKnowledgeMetadata,
+			)
 			protected.DELETE("/kb/metadata", controller.DeleteKnowledgeMetadata)
 			protected.GET("/kb/metadata/search", controller.SearchKnowledgeMetadata)
 
 			protected.POST("/blood-glucose/record", controller.CreateBloodGlucoseRecord)
+			protected.PUT("/blood-glucose/record/:id", controller.UpdateBloodGlucoseRecord)
+			protected.DELETE("/blood-glucose/record/:id", controller.DeleteBloodGlucoseRecord)
 			protected.GET("/blood-glucose/records", controller.GetBloodGlucoseRecords)
+			protected.POST("/blood-glucose/records/batch", controller.BatchCreateBloodGlucoseRecords)
+			protected.GET("/blood-glucose/analytics", controller.GetBloodGlucoseAnalytics)
+			protected.POST("/blood-glucose/cgm-import", controller.ImportCGMData)
+
+			protected.GET("/alert/rules", controller.GetAlertRules)
+			protected.POST("/alert/rule", controller.CreateAlertRule)
+			protected.PUT("/alert/rule/:id", controller.UpdateAlertRule)
+			protected.DELETE("/alert/rule/:id", controller.DeleteAlertRule)
+
+			protected.GET("/lab/results", controller.GetLabResults)
+			protected.GET("/lab/results/latest", controller.GetLatestLabResults)
+			protected.GET("/lab/results/trend", controller.GetLabResultTrend)
+			protected.POST("/lab/result", controller.CreateLabResult)
+			protected.PUT("/lab/result/:id", controller.UpdateLabResult)
+			protected.DELETE("/lab/result/:id", controller.DeleteLabResult)
 
 			protected.GET("/health-profile", controller.GetHealthProfile)
 			protected.POST("/health-profile", controller.CreateHealthProfile)
 			protected.PUT("/health-profile", controller.UpdateHealthProfile)
+			protected.GET("/health-profile/history", controller.GetHealthProfileHistory)
 
 			protected.GET("/exercise/records", controller.GetExerciseRecords)
 			protected.POST("/exercise/record", controller.CreateExerciseRecord)
 			protected.DELETE("/exercise/record/:id", controller.DeleteExerciseRecord)
 
+			protected.GET("/vital/records", controller.GetVitalRecords)
+			protected.GET("/vital/stats", controller.GetVitalStats)
+			protected.POST("/vital/record", controller.CreateVitalRecord)
+			protected.DELETE("/vital/record/:id", controller.DeleteVitalRecord)
+
+			protected.GET("/meal/records", controller.GetMealRecords)
+			protected.POST("/meal/record", controller.CreateMealRecord)
+			protected.DELETE("/meal/record/:id", controller.DeleteMealRecord)
+
+			protected.GET("/medication/plans", controller.GetMedicationPlans)
+			protected.POST("/medication/plan", controller.CreateMedicationPlan)
+			protected.PUT("/medication/plan/:id", controller.UpdateMedicationPlan)
+			protected.DELETE("/medication/plan/:id", controller.DeleteMedicationPlan)
+			protected.GET("/medication/intakes", controller.GetMedicationIntakes)
+			protected.POST("/medication/intake", controller.CreateMedicationIntake)
+			protected.DELETE("/medication/intake/:id", controller.DeleteMedicationIntake)
+			protected.GET("/medication/adherence", controller.GetMedicationAdherence)
+
 			protected.GET("/health-weekly-reports", controller.GetHealthWeeklyReports)
 			protected.PUT("/health-weekly-reports/notification", controller.UpdateUserEnableNotification)
+			protected.GET("/health-reports", controller.GetHealthReports)
+			protected.POST("/health-report",
+				middleware.RateLimitMiddleware(middleware.RateLimitGroupReport),
+				controller.GenerateHealthReport,
+			)
+			protected.GET("/health-report/jobs", controller.GetHealthReportJobs)
+			protected.POST("/health-report/job/:id/rerun", controller.RerunHealthReportJob)
+			protected.PUT("/health-reports/insufficient-data-policy", controller.UpdateInsufficientDataPolicy)
 
 			protected.GET("/system-messages", controller.GetSystemMessages)
 			protected.PUT("/system-message/:id/read", controller.UpdateSystemMessageAsRead)
 			protected.DELETE("/system-message/:id", controller.DeleteSystemMessage)
 			protected.GET("/system-messages/unread/count", controller.GetUnreadSystemMessageCount)
+
+			protected.GET("/llm-usage", controller.GetLLMUsage)
+
+			protected.POST("/user/data-export",
+				middleware.RateLimitMiddleware(middleware.RateLimitGroupDataExport),
+				controller.CreateDataExport,
+			)
+			protected.GET("/user/data-exports", controller.GetDataExports)
+			protected.GET("/user/data-export/:id/presigned-url", controller.GetDataExportURL)
+
+			protected.POST("/user/account-deletion", controller.CreateAccountDeletion)
+			protected.GET("/user/account-deletion", controller.GetAccountDeletion)
+			protected.DELETE("/user/account-deletion/:id", controller.CancelAccountDeletion)
+
+			// 患者管理对看护人/医生的数据授权
+			patientOnly := middleware.RoleMiddleware(model.RolePatient)
+			protected.GET("/care/shares", patientOnly, controller.GetCareShares)
+			protected.POST("/care/share", patientOnly, controller.CreateCareShare)
+			protected.PUT("/care/share/:id", patientOnly, controller.UpdateCareShare)
+			protected.DELETE("/care/share/:id", patientOnly, controller.DeleteCareShare)
+			protected.GET("/care/access-logs", patientOnly, controller.GetCareAccessLogs)
+		}
+
+		admin := api.Group("/admin")
+		admin.Use(
+			middleware.AuthMiddleware(),
+			middleware.RoleMiddleware(model.RoleAdmin),
+		)
+		{
+			admin.GET("/users", controller.GetUsers)
+			admin.PUT("/user/:email/disabled", controller.UpdateUserDisabled)
+			admin.PUT("/user/:email/role", controller.UpdateUserRole)
+			admin.GET("/user/:email/llm-usage", controller.GetUserLLMUsage)
+
+			admin.GET("/kb/metadata/failed", controller.GetFailedKnowledgeMetadata)
+			admin.POST("/kb/metadata/:id/reprocess", controller.ReprocessKnowledgeMetadata)
+			admin.POST("/kb/metadata/failed/reprocess", controller.ReprocessFailedKnowledgeMetadata)
+
+			admin.POST("/health-report/regenerate", controller.RegenerateHealthReport)
+
+			admin.POST("/system-message/broadcast", controller.BroadcastSystemMessage)
+
+			admin.GET("/account-deletions", controller.GetAccountDeletionJobs)
+			admin.GET("/account-deletion/:id", controller.GetAccountDeletionJob)
+		}
+
+		// 看护人/医生只读访问已授权患者的数据，每次访问记录审计日志
+		portal := api.Group("/portal")
+		portal.Use(
+			middleware.AuthMiddleware(),
+			middleware.RoleMiddleware(model.RoleCaregiver, model.RoleClinician),
+		)
+		{
+			portal.GET("/patients", controller.GetCarePatients)
+
+			patient := portal.Group("/patient/:email")
+			{
+				patient.GET("/blood-glucose/records",
+					middleware.CareAccessMiddleware(model.CareScopeGlucose),
+					controller.GetPatientBloodGlucoseRecords,
+				)
+				patient.GET("/blood-glucose/analytics",
+					middleware.CareAccessMiddleware(model.CareScopeGlucose),
+					controller.GetPatientBloodGlucoseAnalytics,
+				)
+				patient.GET("/exercise/records",
+					middleware.CareAccessMiddleware(model.CareScopeExercise),
+					controller.GetPatientExerciseRecords,
+				)
+				patient.GET("/health-profile",
+					middleware.CareAccessMiddleware(model.CareScopeProfile),
+					controller.GetPatientHealthProfile,
+				)
+				patient.GET("/health-reports",
+					middleware.CareAccessMiddleware(model.CareScopeReport),
+					controller.GetPatientHealthReports,
+				)
+				patient.GET("/health-report/:id/presigned-url",
+					middleware.CareAccessMiddleware(model.CareScopeReport),
+					controller.GetPatientHealthReportURL,
+				)
+			}
 		}
 	}
 
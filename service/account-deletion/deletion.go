@@ -0,0 +1,356 @@
+package accountdeletion
+
+import (
+	"context"
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/service/auth"
+	"diabetes-agent-server/service/chat"
+	"diabetes-agent-server/service/knowledge-base/etl"
+	ossauth "diabetes-agent-server/service/oss-auth"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/apache/rocketmq-client-go/v2/primitive"
+	"github.com/go-co-op/gocron"
+)
+
+const (
+	// 冷静期，申请注销后到期才执行删除，期间可撤销
+	GracePeriod = 7 * 24 * time.Hour
+
+	// 执行中的任务超过该时长未更新视为中断，可被重新领取
+	staleJobTimeout = 30 * time.Minute
+
+	// 任务最多执行次数，包括 MQ 重试，达到上限后不再投递，需人工处理
+	maxJobAttempts = 10
+)
+
+// 按前缀删除的 OSS 对象，路径格式均为 {prefix}/{email}/...
+var ossPrefixes = []string{
+	ossauth.OSSKeyPrefixKnowledgeBase,
+	ossauth.OSSKeyPrefixUpload,
+	ossauth.OSSKeyPrefixHealthWeeklyReport,
+	ossauth.OSSKeyPrefixCGMImport,
+	ossauth.OSSKeyPrefixDataExport,
+}
+
+// ErrResidualData 删除后复查仍有残留数据
+var ErrResidualData = errors.New("residual data remains after deletion")
+
+// JobSender 投递注销任务消息，由调用方注入，避免与 mq 包循环依赖
+type JobSender func(ctx context.Context, msg DeletionMessage) error
+
+// DeletionMessage 账号注销任务消息
+type DeletionMessage struct {
+	JobID uint `json:"job_id"`
+}
+
+// RequestDeletion 校验密码后创建注销任务，冷静期结束后执行删除。已有冷静期内的任务时直接返回该任务
+func RequestDeletion(ctx context.Context, email, password string) (*model.AccountDeletionJob, error) {
+	if err := auth.VerifyPassword(email, password); err != nil {
+		return nil, err
+	}
+
+	existing, err := dao.GetPendingAccountDeletionJob(email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending account deletion job: %v", err)
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	job := model.AccountDeletionJob{
+		UserEmail:   email,
+		Status:      model.AccountDeletionStatusPending,
+		ScheduledAt: time.Now().Add(GracePeriod),
+	}
+	if err := dao.CreateAccountDeletionJob(&job); err != nil {
+		return nil, fmt.Errorf("failed to create account deletion job: %v", err)
+	}
+
+	notify(ctx, email, "账号注销申请已提交", []string{
+		fmt.Sprintf("您已申请注销账号，账号及所有数据将于 %s 后永久删除且无法恢复。",
+			job.ScheduledAt.Format("2006-01-02 15:04")),
+		"在此之前您可以正常使用账号，并可随时撤销注销申请。如非本人操作，请立即撤销申请并修改密码。",
+	})
+	return &job, nil
+}
+
+// CancelDeletion 撤销冷静期内的注销任务，返回 false 表示任务不存在或已开始执行
+func CancelDeletion(ctx context.Context, email string, id uint) (bool, error) {
+	canceled, err := dao.CancelAccountDeletionJob(email, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel account deletion job: %v", err)
+	}
+	if !canceled {
+		return false, nil
+	}
+
+	notify(ctx, email, "账号注销申请已撤销", []string{
+		"您的账号注销申请已撤销，账号及数据将继续保留。",
+	})
+	return true, nil
+}
+
+// SetupAccountDeletionScheduler 每小时投递冷静期已结束、执行失败及执行超时的注销任务，服务启动时立即执行一次
+func SetupAccountDeletionScheduler(send JobSender) {
+	s := gocron.NewScheduler(time.UTC)
+
+	_, err := s.Every(1).Hour().Do(enqueueDueJobs, send)
+	if err != nil {
+		slog.Error("Failed to schedule account deletion task", "err", err)
+		return
+	}
+
+	s.StartAsync()
+}
+
+// 投递失败的任务保持原状态，下次执行时重新投递
+func enqueueDueJobs(send JobSender) {
+	ctx := context.Background()
+	jobs, err := dao.GetDueAccountDeletionJobs(time.Now(), time.Now().Add(-staleJobTimeout), maxJobAttempts)
+	if err != nil {
+		slog.Error("Failed to get due account deletion jobs", "err", err)
+		return
+	}
+
+	for _, job := range jobs {
+		if err := send(ctx, DeletionMessage{JobID: job.ID}); err != nil {
+			slog.Error("Failed to send account deletion job message",
+				"job_id", job.ID,
+				"err", err,
+			)
+		}
+	}
+}
+
+// HandleDeletionMessage 删除用户的所有数据并复查残留，失败时记录原因和已完成部分的报告，返回错误由 MQ 重试。
+// 各步骤均可重复执行，任务通过条件更新领取，重复投递的消息不会并发执行
+func HandleDeletionMessage(ctx context.Context, msg *primitive.MessageExt) error {
+	var deletionMessage DeletionMessage
+	if err := json.Unmarshal(msg.Body, &deletionMessage); err != nil {
+		return fmt.Errorf("failed to unmarshal message body: %v", err)
+	}
+
+	job, err := dao.GetAccountDeletionJobByID(deletionMessage.JobID)
+	if err != nil {
+		return fmt.Errorf("failed to get account deletion job: %v", err)
+	}
+	if job == nil {
+		return nil
+	}
+
+	claimed, err := dao.ClaimAccountDeletionJob(job.ID, time.Now(), time.Now().Add(-staleJobTimeout), maxJobAttempts)
+	if err != nil {
+		return fmt.Errorf("failed to claim account deletion job: %v", err)
+	}
+	if !claimed {
+		slog.Info("account deletion job not claimable",
+			"job_id", job.ID,
+			"status", job.Status,
+			"attempts", job.Attempts,
+		)
+		return nil
+	}
+
+	// 重试时用户记录可能已删除，此时不再发送完成通知
+	user, err := dao.GetUserByEmail(job.UserEmail)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %v", err)
+	}
+
+	// 用户记录删除后同一邮箱可能已重新注册，此时终止任务，避免删除新账号的数据
+	if user != nil && user.CreatedAt.After(job.CreatedAt) {
+		slog.Warn("account re-registered after deletion request, abort account deletion job",
+			"job_id", job.ID,
+			"email", job.UserEmail,
+		)
+		if err := dao.AbortAccountDeletionJob(job.ID, "account re-registered after deletion request"); err != nil {
+			return fmt.Errorf("failed to abort account deletion job: %v", err)
+		}
+		return nil
+	}
+
+	report := job.Report
+	if report == nil {
+		report = &model.AccountDeletionReport{
+			StartedAt: time.Now(),
+		}
+	}
+
+	if err := runDeletionJob(ctx, job.UserEmail, user, report); err != nil {
+		if err := dao.FailAccountDeletionJob(job.ID, err.Error(), report); err != nil {
+			slog.Error("Failed to update account deletion job status", "err", err)
+		}
+
+		// 领取时已累加执行次数
+		if job.Attempts+1 >= maxJobAttempts {
+			slog.Error("Account deletion job reached max attempts, manual intervention required",
+				"job_id", job.ID,
+				"email", job.UserEmail,
+				"attempts", job.Attempts+1,
+				"err", err,
+			)
+		}
+		return fmt.Errorf("failed to run account deletion job %d: %v", job.ID, err)
+	}
+
+	if err := dao.CompleteAccountDeletionJob(job.ID, report); err != nil {
+		return fmt.Errorf("failed to complete account deletion job: %v", err)
+	}
+
+	slog.Info("account deleted",
+		"job_id", job.ID,
+		"email", job.UserEmail,
+	)
+	return nil
+}
+
+// 依次吊销令牌、删除知识文件及向量、OSS 对象、数据库记录和 Redis key，最后复查残留数据。
+// 重试时在已有报告上累加删除数量，user 为 nil 表示用户记录已在之前的执行中删除
+func runDeletionJob(ctx context.Context, email string, user *model.User, report *model.AccountDeletionReport) error {
+	if report.Tables == nil {
+		report.Tables = make(map[string]int64)
+	}
+	if report.Objects == nil {
+		report.Objects = make(map[string]int)
+	}
+
+	// 先使用户退出所有设备，避免删除过程中写入新数据
+	if err := auth.LogoutAll(ctx, email); err != nil {
+		return fmt.Errorf("failed to logout user: %v", err)
+	}
+
+	if err := deleteKnowledgeBase(ctx, email, report); err != nil {
+		return err
+	}
+
+	count, err := chat.DeleteUploadedFiles(ctx, email, "")
+	report.Objects[ossauth.OSSKeyPrefixUpload] += count
+	if err != nil {
+		return err
+	}
+
+	for _, prefix := range ossPrefixes {
+		if prefix == ossauth.OSSKeyPrefixUpload {
+			continue
+		}
+		count, err := ossauth.DeleteObjectsByPrefix(ctx, userObjectPrefix(prefix, email))
+		report.Objects[prefix] += count
+		if err != nil {
+			return fmt.Errorf("failed to delete %s objects: %v", prefix, err)
+		}
+	}
+
+	deleted, err := dao.DeleteUserData(email)
+	if err != nil {
+		return fmt.Errorf("failed to delete user data: %v", err)
+	}
+	for table, rows := range deleted {
+		report.Tables[table] += rows
+	}
+
+	keys, err := dao.DeleteUserRedisKeys(ctx, email)
+	report.RedisKeys += keys
+	if err != nil {
+		return fmt.Errorf("failed to delete redis keys: %v", err)
+	}
+
+	if err := verifyDeletion(ctx, email, report); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	report.FinishedAt = &now
+
+	// 用户记录已删除，仅通过邮件通知
+	if user != nil && user.EmailVerified {
+		err := sendNotification(email, NotificationData{
+			Title:      "账号已注销",
+			Paragraphs: []string{"您的账号已注销，账号及所有数据已永久删除。感谢您的使用。"},
+		})
+		if err != nil {
+			slog.Error("Failed to send account deletion notification",
+				"email", email,
+				"err", err,
+			)
+		}
+	}
+	return nil
+}
+
+// 按知识文件元数据删除 OSS 对象和向量，再按 user_email 清理残留向量。
+// 单个文件删除失败不中断任务，残留数据由后续按前缀删除和复查兜底
+func deleteKnowledgeBase(ctx context.Context, email string, report *model.AccountDeletionReport) error {
+	files, err := dao.GetUserRecords[model.KnowledgeMetadata](email)
+	if err != nil {
+		return fmt.Errorf("failed to get knowledge metadata: %v", err)
+	}
+
+	for _, file := range files {
+		if err := etl.DeleteKnowledgeFile(ctx, file.FileType, file.ObjectName); err != nil {
+			slog.Warn("Failed to delete knowledge file",
+				"object_name", file.ObjectName,
+				"err", err,
+			)
+			continue
+		}
+		report.KnowledgeFiles++
+	}
+
+	vectors, err := etl.DeleteUserVectorStore(ctx, email)
+	if err != nil {
+		return fmt.Errorf("failed to delete user vector store: %v", err)
+	}
+	report.Vectors += vectors
+	return nil
+}
+
+// 复查数据库、OSS、Milvus 和 Redis 中的残留数据，结果写入报告，存在残留时返回 ErrResidualData
+func verifyDeletion(ctx context.Context, email string, report *model.AccountDeletionReport) error {
+	remaining := make(map[string]int64)
+
+	counts, err := dao.CountUserData(email)
+	if err != nil {
+		return fmt.Errorf("failed to count user data: %v", err)
+	}
+	for table, count := range counts {
+		remaining["table:"+table] = count
+	}
+
+	for _, prefix := range ossPrefixes {
+		count, err := ossauth.CountObjectsByPrefix(ctx, userObjectPrefix(prefix, email))
+		if err != nil {
+			return fmt.Errorf("failed to count %s objects: %v", prefix, err)
+		}
+		remaining["oss:"+prefix] = int64(count)
+	}
+
+	vectors, err := etl.CountUserVectors(ctx, email)
+	if err != nil {
+		return fmt.Errorf("failed to count user vectors: %v", err)
+	}
+	remaining["milvus"] = vectors
+
+	keys, err := dao.CountUserRedisKeys(ctx, email)
+	if err != nil {
+		return fmt.Errorf("failed to count redis keys: %v", err)
+	}
+	remaining["redis"] = keys
+
+	report.Remaining = remaining
+	for name, count := range remaining {
+		if count > 0 {
+			return fmt.Errorf("%w: %s", ErrResidualData, name)
+		}
+	}
+	return nil
+}
+
+func userObjectPrefix(prefix, email string) string {
+	return fmt.Sprintf("%s/%s/", prefix, email)
+}
@@ -0,0 +1,73 @@
+package accountdeletion
+
+import (
+	"context"
+	"diabetes-agent-server/config"
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/service/email"
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"net/smtp"
+	"strings"
+)
+
+//go:embed notification.html
+var notificationTemplate string
+
+type NotificationData struct {
+	Title      string
+	Paragraphs []string
+}
+
+// 通过系统消息和邮件通知用户，仅向已验证的邮箱发送邮件，通知失败不影响任务结果
+func notify(ctx context.Context, toEmail string, title string, paragraphs []string) {
+	if err := dao.CreateSystemMessage(ctx, toEmail, title, strings.Join(paragraphs, "")); err != nil {
+		slog.Error("Failed to save system message", "err", err)
+	}
+
+	user, err := dao.GetUserByEmail(toEmail)
+	if err != nil {
+		slog.Error("Failed to get user", "email", toEmail, "err", err)
+		return
+	}
+	if user == nil || !user.EmailVerified {
+		return
+	}
+
+	data := NotificationData{
+		Title:      title,
+		Paragraphs: paragraphs,
+	}
+	if err := sendNotification(toEmail, data); err != nil {
+		slog.Error("Failed to send account deletion notification",
+			"email", toEmail,
+			"err", err,
+		)
+	}
+}
+
+func sendNotification(toEmail string, data NotificationData) error {
+	cfg := config.Cfg.Email
+	message, err := buildNotificationMessage(cfg.FromEmail, toEmail, data)
+	if err != nil {
+		return err
+	}
+
+	auth := smtp.PlainAuth(
+		"",
+		cfg.FromEmail,
+		cfg.Password,
+		cfg.Host,
+	)
+	return email.Send(fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
+		auth,
+		cfg.FromEmail,
+		[]string{toEmail},
+		[]byte(message),
+	)
+}
+
+func buildNotificationMessage(fromEmail string, toEmail string, data NotificationData) (string, error) {
+	return email.BuildHTMLMessage(fromEmail, toEmail, data.Title, notificationTemplate, data)
+}
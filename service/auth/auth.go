@@ -27,6 +27,9 @@ const (
 	loginTypePassword = "password"
 	loginTypeCode     = "code"
 
+	// 注册时发送验证码
+	codeTypeRegister = "register"
+
 	// 验证码的随机数源
 	codeNumbers = "0123456789"
 
@@ -55,15 +58,29 @@ func UserRegister(req request.UserRegisterRequest) (*model.User, error) {
 		return nil, fmt.Errorf("%s is used", req.Email)
 	}
 
+	// 校验验证码，确认用户拥有该邮箱
+	if err := verifyCode(req.Email, req.Code); err != nil {
+		return nil, fmt.Errorf("invalid code: %v", err)
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, err
 	}
 
+	role := req.Role
+	if role == "" {
+		role = model.RolePatient
+	}
+
 	user := model.User{
 		Email:    req.Email,
 		Password: string(hashedPassword),
 		Avatar:   "https://api.dicebear.com/7.x/avataaars/svg?seed=" + generateAvatarSeed(req.Email),
+
+		InsufficientDataPolicy: model.InsufficientDataPolicyPlaceholder,
+		EmailVerified:          true,
+		Role:                   role,
 	}
 	if err := dao.DB.Create(&user).Error; err != nil {
 		return nil, err
@@ -78,29 +95,56 @@ func generateAvatarSeed(email string) string {
 	return fmt.Sprintf("%x", hash)
 }
 
-func UserLogin(req request.UserLoginRequest) (*model.User, error) {
+// UserLogin 用户登录，按邮箱和客户端 IP 统计失败次数，失败过多时返回 LoginLockedError
+func UserLogin(req request.UserLoginRequest, ip string) (*model.User, error) {
+	ctx := context.Background()
+	if err := checkLoginLock(ctx, req.Email, ip); err != nil {
+		return nil, err
+	}
+
 	// 检查用户是否存在
 	user, err := dao.GetUserByEmail(req.Email)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user %s: %v", req.Email, err)
 	}
 	if user == nil {
+		recordLoginFailure(ctx, req.Email, ip, false)
 		return nil, fmt.Errorf("%s is not registered", req.Email)
 	}
 
 	switch req.Type {
 	case loginTypePassword:
 		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+			recordLoginFailure(ctx, req.Email, ip, true)
 			return nil, fmt.Errorf("invalid password: %v", err)
 		}
 	case loginTypeCode:
 		if err := verifyCode(req.Email, req.Code); err != nil {
+			recordLoginFailure(ctx, req.Email, ip, true)
 			return nil, fmt.Errorf("invalid code: %v", err)
 		}
+
+		// 验证码登录同时证明了邮箱归属
+		if !user.EmailVerified {
+			if err := dao.MarkUserEmailVerified(user.Email); err != nil {
+				slog.Error("Failed to mark email verified",
+					"email", user.Email,
+					"err", err,
+				)
+			} else {
+				user.EmailVerified = true
+			}
+		}
 	default:
 		return nil, fmt.Errorf("invalid login type: %s", req.Type)
 	}
 
+	resetLoginFailures(ctx, req.Email)
+
+	// 凭证校验通过后再检查禁用状态，避免泄露账号状态
+	if user.Disabled {
+		return nil, ErrUserDisabled
+	}
 	return user, nil
 }
 
@@ -124,24 +168,39 @@ func verifyCode(email, code string) error {
 		return fmt.Errorf("failed to get verification code: %v", err)
 	}
 	if storedCode != code {
+		if recordCodeFailure(ctx, email) {
+			return fmt.Errorf("too many wrong codes, verification code invalidated")
+		}
 		return fmt.Errorf("verification code mismatch")
 	}
 
 	// 校验成功后删除 key，防止重复使用
-	if _, err := dao.RedisClient.Del(ctx, key).Result(); err != nil {
+	failuresKey := fmt.Sprintf(constants.KeyVerificationCodeFailures, email)
+	if _, err := dao.RedisClient.Del(ctx, key, failuresKey).Result(); err != nil {
 		slog.Error("error deleting verification code", "err", err)
 	}
 
 	return nil
 }
 
+// VerifyEmail 通过验证码验证已注册用户的邮箱
+func VerifyEmail(email, code string) error {
+	if err := verifyCode(email, code); err != nil {
+		return fmt.Errorf("invalid code: %v", err)
+	}
+	return dao.MarkUserEmailVerified(email)
+}
+
 func SendVerificationCode(req request.SendEmailCodeRequest) error {
-	// 检查用户是否存在
+	// 注册验证码仅发送至未注册的邮箱，其余验证码仅发送至已注册的邮箱
 	user, err := dao.GetUserByEmail(req.Email)
 	if err != nil {
 		return fmt.Errorf("failed to get user %s: %v", req.Email, err)
 	}
-	if user == nil {
+	if req.Type == codeTypeRegister && user != nil {
+		return fmt.Errorf("%s is used", req.Email)
+	}
+	if req.Type != codeTypeRegister && user == nil {
 		return fmt.Errorf("%s is not registered", req.Email)
 	}
 
@@ -165,12 +224,13 @@ func SendVerificationCode(req request.SendEmailCodeRequest) error {
 		}
 	}
 
-	// 存储验证码到 Redis，设置过期时间
+	// 存储验证码到 Redis，设置过期时间，并清除旧验证码的错误次数
 	code := generateCode()
 	err = dao.RedisClient.Set(ctx, key, code, codeExpiration).Err()
 	if err != nil {
 		return fmt.Errorf("failed to save verification code: %v", err)
 	}
+	dao.RedisClient.Del(ctx, fmt.Sprintf(constants.KeyVerificationCodeFailures, req.Email))
 
 	if err := sendEmail(req.Email, code); err != nil {
 		return fmt.Errorf("failed to send email: %v", err)
@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"diabetes-agent-server/constants"
+	"diabetes-agent-server/dao"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	// 统计登录失败次数的时间窗口
+	loginFailureWindow = 15 * time.Minute
+
+	// 单个邮箱、单个 IP 连续登录失败达到该次数后临时锁定
+	maxLoginFailures   = 5
+	maxIPLoginFailures = 20
+
+	// 临时锁定时长
+	loginLockoutDuration = 15 * time.Minute
+
+	// 失败次数达到该值后，每次失败需等待的时间翻倍，最长为 maxLoginDelay
+	progressiveDelayStart = 3
+	baseLoginDelay        = 1 * time.Second
+	maxLoginDelay         = 1 * time.Minute
+
+	// 同一验证码错误达到该次数后作废，需重新发送
+	maxCodeFailures = 5
+)
+
+// LoginLockedError 登录失败次数过多，需等待 RetryAfter 后再次尝试
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// 检查邮箱和 IP 是否处于登录锁定或等待期
+func checkLoginLock(ctx context.Context, email, ip string) error {
+	var retryAfter time.Duration
+	for _, key := range []string{
+		fmt.Sprintf(constants.KeyLoginLock, email),
+		fmt.Sprintf(constants.KeyIPLoginLock, ip),
+	} {
+		ttl, err := dao.RedisClient.PTTL(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("failed to check login lock: %v", err)
+		}
+		retryAfter = max(retryAfter, ttl)
+	}
+
+	if retryAfter > 0 {
+		return &LoginLockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// 记录登录失败，按失败次数设置等待期，邮箱达到锁定次数时通过系统消息提醒用户
+func recordLoginFailure(ctx context.Context, email, ip string, registered bool) {
+	failures, err := incrFailures(ctx, fmt.Sprintf(constants.KeyLoginFailures, email), loginFailureWindow)
+	if err != nil {
+		slog.Error("Failed to record login failure", "email", email, "err", err)
+	} else {
+		lock(ctx, fmt.Sprintf(constants.KeyLoginLock, email), loginDelay(failures, maxLoginFailures))
+		if failures == maxLoginFailures && registered {
+			notifySuspiciousLogin(ctx, email, ip, failures)
+		}
+	}
+
+	ipFailures, err := incrFailures(ctx, fmt.Sprintf(constants.KeyIPLoginFailures, ip), loginFailureWindow)
+	if err != nil {
+		slog.Error("Failed to record login failure", "ip", ip, "err", err)
+		return
+	}
+	lock(ctx, fmt.Sprintf(constants.KeyIPLoginLock, ip), loginDelay(ipFailures, maxIPLoginFailures))
+}
+
+// 登录成功后清除邮箱的失败计数，IP 计数保留至窗口结束，避免攻击者用自己的账号重置计数
+func resetLoginFailures(ctx context.Context, email string) {
+	err := dao.RedisClient.Del(ctx,
+		fmt.Sprintf(constants.KeyLoginFailures, email),
+		fmt.Sprintf(constants.KeyLoginLock, email),
+	).Err()
+	if err != nil {
+		slog.Error("Failed to reset login failures", "email", email, "err", err)
+	}
+}
+
+// 根据失败次数计算等待时长，达到上限时锁定
+func loginDelay(failures int64, maxFailures int64) time.Duration {
+	if failures >= maxFailures {
+		return loginLockoutDuration
+	}
+	if failures < progressiveDelayStart {
+		return 0
+	}
+	return min(baseLoginDelay<<(failures-progressiveDelayStart), maxLoginDelay)
+}
+
+func incrFailures(ctx context.Context, key string, window time.Duration) (int64, error) {
+	failures, err := dao.RedisClient.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if failures == 1 {
+		dao.RedisClient.Expire(ctx, key, window)
+	}
+	return failures, nil
+}
+
+func lock(ctx context.Context, key string, duration time.Duration) {
+	if duration <= 0 {
+		return
+	}
+	if err := dao.RedisClient.Set(ctx, key, 1, duration).Err(); err != nil {
+		slog.Error("Failed to set login lock", "key", key, "err", err)
+	}
+}
+
+func notifySuspiciousLogin(ctx context.Context, email, ip string, failures int64) {
+	content := fmt.Sprintf("检测到您的账号于 %s 连续 %d 次登录失败(IP：%s)，已临时锁定 %d 分钟。如非本人操作，请及时修改密码。",
+		time.Now().Format("2006-01-02 15:04"), failures, ip, int(loginLockoutDuration.Minutes()))
+	if err := dao.CreateSystemMessage(ctx, email, "异常登录提醒", content); err != nil {
+		slog.Error("Failed to save suspicious login message", "email", email, "err", err)
+	}
+}
+
+// 记录验证码错误，错误次数过多时作废验证码，返回验证码是否已作废
+func recordCodeFailure(ctx context.Context, email string) bool {
+	key := fmt.Sprintf(constants.KeyVerificationCodeFailures, email)
+	failures, err := incrFailures(ctx, key, codeExpiration)
+	if err != nil {
+		slog.Error("Failed to record verification code failure", "email", email, "err", err)
+		return false
+	}
+	if failures < maxCodeFailures {
+		return false
+	}
+
+	err = dao.RedisClient.Del(ctx, key, fmt.Sprintf(constants.KeyVerificationCode, email)).Err()
+	if err != nil {
+		slog.Error("Failed to invalidate verification code", "email", email, "err", err)
+	}
+	return true
+}
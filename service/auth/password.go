@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/request"
+	"diabetes-agent-server/response"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrIncorrectPassword 修改密码时原密码错误
+	ErrIncorrectPassword = errors.New("incorrect password")
+
+	// ErrInvalidCode 重置密码时验证码无效
+	ErrInvalidCode = errors.New("invalid verification code")
+)
+
+// ChangePassword 校验原密码后修改密码，并使其他设备退出登录，返回当前设备的新令牌
+func ChangePassword(ctx context.Context, email string, req request.ChangePasswordRequest) (*response.TokenResponse, error) {
+	user, err := dao.GetUserByEmail(email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user %s: %v", email, err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("%s is not registered", email)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.OldPassword)); err != nil {
+		return nil, ErrIncorrectPassword
+	}
+
+	if err := updatePassword(ctx, email, req.NewPassword); err != nil {
+		return nil, err
+	}
+	return IssueTokens(email)
+}
+
+// ResetPassword 通过邮箱验证码重置密码，并使所有设备退出登录
+func ResetPassword(ctx context.Context, req request.ResetPasswordRequest) error {
+	user, err := dao.GetUserByEmail(req.Email)
+	if err != nil {
+		return fmt.Errorf("failed to get user %s: %v", req.Email, err)
+	}
+	if user == nil {
+		return fmt.Errorf("%s is not registered", req.Email)
+	}
+
+	if err := verifyCode(req.Email, req.Code); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCode, err)
+	}
+
+	if !user.EmailVerified {
+		if err := dao.MarkUserEmailVerified(req.Email); err != nil {
+			return fmt.Errorf("failed to mark email verified: %v", err)
+		}
+	}
+
+	return updatePassword(ctx, req.Email, req.NewPassword)
+}
+
+// VerifyPassword 校验用户密码，用于敏感操作的二次确认
+func VerifyPassword(email, password string) error {
+	user, err := dao.GetUserByEmail(email)
+	if err != nil {
+		return fmt.Errorf("failed to get user %s: %v", email, err)
+	}
+	if user == nil {
+		return fmt.Errorf("%s is not registered", email)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return ErrIncorrectPassword
+	}
+	return nil
+}
+
+// 更新密码并吊销用户的所有令牌
+func updatePassword(ctx context.Context, email, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if err := dao.UpdateUserPassword(email, string(hashedPassword)); err != nil {
+		return fmt.Errorf("failed to update password: %v", err)
+	}
+
+	if err := LogoutAll(ctx, email); err != nil {
+		return fmt.Errorf("failed to revoke tokens: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"diabetes-agent-server/config"
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/middleware"
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/response"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// 刷新令牌的默认有效期
+const defaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+var (
+	// ErrInvalidRefreshToken 刷新令牌不存在、已过期或已被吊销
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+	// ErrUserDisabled 用户已被管理员禁用
+	ErrUserDisabled = errors.New("user is disabled")
+)
+
+func refreshTokenTTL() time.Duration {
+	if days := config.Cfg.JWT.RefreshTokenDays; days > 0 {
+		return time.Duration(days) * 24 * time.Hour
+	}
+	return defaultRefreshTokenTTL
+}
+
+// IssueTokens 签发访问令牌和刷新令牌，访问令牌携带用户当前的角色
+func IssueTokens(email string) (*response.TokenResponse, error) {
+	user, err := dao.GetUserByEmail(email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user %s: %v", email, err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("%s is not registered", email)
+	}
+	if user.Disabled {
+		return nil, ErrUserDisabled
+	}
+
+	accessToken, err := middleware.GenerateToken(email, user.Role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %v", err)
+	}
+
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %v", err)
+	}
+	refreshToken := hex.EncodeToString(bytes)
+
+	err = dao.CreateRefreshToken(&model.RefreshToken{
+		UserEmail: email,
+		TokenHash: hashRefreshToken(refreshToken),
+		ExpiresAt: time.Now().Add(refreshTokenTTL()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save refresh token: %v", err)
+	}
+
+	return &response.TokenResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(middleware.AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+// RefreshTokens 使用刷新令牌换取新的令牌，旧刷新令牌随即失效。
+// 已吊销的刷新令牌被再次使用时视为令牌泄露，吊销该用户的所有令牌
+func RefreshTokens(ctx context.Context, refreshToken string) (*response.TokenResponse, error) {
+	token, err := dao.GetRefreshTokenByHash(hashRefreshToken(refreshToken))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %v", err)
+	}
+	if token == nil || time.Now().After(token.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if token.Revoked {
+		slog.Warn("revoked refresh token reused, revoke all tokens", "email", token.UserEmail)
+		if err := LogoutAll(ctx, token.UserEmail); err != nil {
+			slog.Error("Failed to revoke all tokens",
+				"email", token.UserEmail,
+				"err", err,
+			)
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// 并发刷新时只有一个请求能吊销旧令牌
+	ok, err := dao.RevokeRefreshToken(token.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke refresh token: %v", err)
+	}
+	if !ok {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return IssueTokens(token.UserEmail)
+}
+
+// Logout 吊销当前访问令牌，传入刷新令牌时一并吊销
+func Logout(ctx context.Context, email, jti string, expiresAt time.Time, refreshToken string) error {
+	if err := middleware.RevokeToken(ctx, jti, expiresAt); err != nil {
+		return fmt.Errorf("failed to revoke access token: %v", err)
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	token, err := dao.GetRefreshTokenByHash(hashRefreshToken(refreshToken))
+	if err != nil {
+		return fmt.Errorf("failed to get refresh token: %v", err)
+	}
+	if token == nil || token.UserEmail != email {
+		return nil
+	}
+	if _, err := dao.RevokeRefreshToken(token.ID); err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %v", err)
+	}
+	return nil
+}
+
+// LogoutAll 吊销用户在所有设备上的访问令牌和刷新令牌
+func LogoutAll(ctx context.Context, email string) error {
+	if err := dao.RevokeUserRefreshTokens(email); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %v", err)
+	}
+	if err := middleware.RevokeAllTokens(ctx, email); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %v", err)
+	}
+	return nil
+}
+
+func hashRefreshToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
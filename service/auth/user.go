@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"context"
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/middleware"
+	"fmt"
+)
+
+// SetUserDisabled 禁用或启用用户，禁用时吊销用户的所有令牌使其立即退出登录
+func SetUserDisabled(ctx context.Context, email string, disabled bool) error {
+	if err := dao.UpdateUserDisabled(email, disabled); err != nil {
+		return fmt.Errorf("failed to update user disabled: %v", err)
+	}
+	if !disabled {
+		return nil
+	}
+	return LogoutAll(ctx, email)
+}
+
+// SetUserRole 修改用户角色。访问令牌中携带角色，因此吊销用户的访问令牌，
+// 保留刷新令牌，客户端刷新后即获得携带新角色的令牌
+func SetUserRole(ctx context.Context, email, role string) error {
+	if err := dao.UpdateUserRole(email, role); err != nil {
+		return fmt.Errorf("failed to update user role: %v", err)
+	}
+	if err := middleware.RevokeAllTokens(ctx, email); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package cgmimport
+
+import (
+	"bytes"
+	"context"
+	"diabetes-agent-server/config"
+	"diabetes-agent-server/constants"
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/model"
+	glucosealert "diabetes-agent-server/service/glucose-alert"
+	"diabetes-agent-server/utils"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss"
+	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss/credentials"
+	"github.com/apache/rocketmq-client-go/v2/primitive"
+)
+
+const (
+	insertBatchSize = 500
+
+	// 每写入该数量的批次发送一次进度消息
+	progressBatchInterval = 10
+
+	// 系统消息中展示的错误行数上限
+	maxReportedErrors = 5
+
+	messageTitle = "CGM 数据导入"
+)
+
+type ImportMessage struct {
+	Email      string `json:"email"`
+	FileName   string `json:"file_name"`
+	ObjectName string `json:"object_name"`
+
+	// CSV 中时间戳所在的时区
+	Timezone string `json:"timezone"`
+}
+
+// ImportResult CGM 数据导入结果
+type ImportResult struct {
+	Imported   int
+	Duplicated int
+	Errors     []string
+
+	// 新增的血糖记录
+	Records []model.BloodGlucoseRecord
+}
+
+// NewImportHandler 返回 CGM 导入消息的处理函数，导入完成后通过 send 为新增记录投递提醒评估任务
+func NewImportHandler(send glucosealert.Sender) func(ctx context.Context, msg *primitive.MessageExt) error {
+	return func(ctx context.Context, msg *primitive.MessageExt) error {
+		return handleImportMessage(ctx, msg, send)
+	}
+}
+
+// 从 OSS 读取 CGM 导出文件，按测量时间去重后批量写入血糖记录，通过系统消息通知导入进度和结果。
+// 文件格式错误时不重试，读取或写入失败时返回错误由 MQ 重试，最后一次重试仍失败时通知用户
+func handleImportMessage(ctx context.Context, msg *primitive.MessageExt, send glucosealert.Sender) error {
+	var importMessage ImportMessage
+	if err := json.Unmarshal(msg.Body, &importMessage); err != nil {
+		return fmt.Errorf("failed to unmarshal message body: %v", err)
+	}
+
+	loc, err := time.LoadLocation(importMessage.Timezone)
+	if err != nil {
+		notify(ctx, importMessage, fmt.Sprintf("无效的时区: %s", importMessage.Timezone))
+		return nil
+	}
+
+	// 重试时不重复发送开始消息
+	if msg.ReconsumeTimes == 0 {
+		notify(ctx, importMessage, "开始导入 CGM 数据，完成后将通知您导入结果。")
+	}
+
+	lastAttempt := msg.ReconsumeTimes >= constants.MQMaxReconsumeTimes
+	object, err := getObjectFromOSS(ctx, importMessage.ObjectName)
+	if err != nil {
+		if lastAttempt {
+			notify(ctx, importMessage, "文件读取失败，请稍后重新上传。")
+		}
+		return fmt.Errorf("failed to get object from oss: %v", err)
+	}
+
+	readings, parseErrors, err := parseCSV(object, loc)
+	if err != nil {
+		notify(ctx, importMessage, fmt.Sprintf("文件解析失败: %v", err))
+		return nil
+	}
+	notify(ctx, importMessage, fmt.Sprintf("文件解析完成：有效读数 %d 条，解析失败 %d 条，正在写入。",
+		len(readings), len(parseErrors)))
+
+	result, err := saveReadings(importMessage.Email, readings, func(saved, total int) {
+		notify(ctx, importMessage, fmt.Sprintf("正在写入：已完成 %d/%d 条。", saved, total))
+	})
+	if err != nil {
+		if lastAttempt {
+			notify(ctx, importMessage, "数据写入失败，已写入的读数会保留，请稍后重新导入，重复的读数将自动跳过。")
+		}
+		return fmt.Errorf("failed to save readings: %v", err)
+	}
+	result.Errors = parseErrors
+
+	slog.Info("cgm data imported",
+		"email", importMessage.Email,
+		"object_name", importMessage.ObjectName,
+		"imported", result.Imported,
+		"duplicated", result.Duplicated,
+		"errors", len(result.Errors),
+	)
+
+	notify(ctx, importMessage, buildResultContent(result))
+	glucosealert.SendAlertMessages(ctx, send, result.Records)
+	return nil
+}
+
+func parseCSV(object []byte, loc *time.Location) ([]Reading, []string, error) {
+	reader := csv.NewReader(bytes.NewReader(object))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parser, headerIdx, err := findParser(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	readings := make([]Reading, 0, len(rows))
+	var parseErrors []string
+	for i := headerIdx + 1; i < len(rows); i++ {
+		reading, err := parser.ParseRow(rows[i], loc)
+		if err != nil {
+			parseErrors = append(parseErrors, fmt.Sprintf("第 %d 行: %v", i+1, err))
+			continue
+		}
+		if reading != nil {
+			readings = append(readings, *reading)
+		}
+	}
+
+	return readings, parseErrors, nil
+}
+
+// 按测量时间去重，跳过文件内重复及已存在的读数，分批写入并通过 onProgress 报告写入进度
+func saveReadings(email string, readings []Reading, onProgress func(saved, total int)) (*ImportResult, error) {
+	result := &ImportResult{}
+	if len(readings) == 0 {
+		return result, nil
+	}
+
+	start, end := readings[0].MeasuredAt, readings[0].MeasuredAt
+	for _, r := range readings {
+		if r.MeasuredAt.Before(start) {
+			start = r.MeasuredAt
+		}
+		if r.MeasuredAt.After(end) {
+			end = r.MeasuredAt
+		}
+	}
+
+	existing, err := dao.GetBloodGlucoseMeasuredTimes(email, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int64]bool, len(existing)+len(readings))
+	for _, t := range existing {
+		seen[t.Unix()] = true
+	}
+
+	records := make([]model.BloodGlucoseRecord, 0, len(readings))
+	for _, r := range readings {
+		if seen[r.MeasuredAt.Unix()] {
+			result.Duplicated++
+			continue
+		}
+		seen[r.MeasuredAt.Unix()] = true
+
+		records = append(records, model.BloodGlucoseRecord{
+			UserEmail:    email,
+			Value:        r.Value,
+			MeasuredAt:   r.MeasuredAt,
+			DiningStatus: "random",
+			Source:       model.GlucoseSourceCGM,
+		})
+	}
+
+	for i := 0; i < len(records); i += insertBatchSize {
+		end := min(i+insertBatchSize, len(records))
+		if err := dao.DB.Create(records[i:end]).Error; err != nil {
+			return nil, err
+		}
+
+		batch := i/insertBatchSize + 1
+		if batch%progressBatchInterval == 0 && end < len(records) {
+			onProgress(end, len(records))
+		}
+	}
+	result.Imported = len(records)
+	result.Records = records
+
+	return result, nil
+}
+
+func buildResultContent(result *ImportResult) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("您的 CGM 数据已导入完成：新增 %d 条，重复跳过 %d 条，解析失败 %d 条。",
+		result.Imported, result.Duplicated, len(result.Errors)))
+
+	if len(result.Errors) > 0 {
+		content.WriteString("\n失败详情：")
+		for i, e := range result.Errors {
+			if i >= maxReportedErrors {
+				content.WriteString(fmt.Sprintf("\n... 等 %d 条", len(result.Errors)))
+				break
+			}
+			content.WriteString("\n" + e)
+		}
+	}
+
+	return content.String()
+}
+
+// 通过系统消息通知用户导入结果
+func notify(ctx context.Context, importMessage ImportMessage, content string) {
+	content = fmt.Sprintf("[%s] %s", importMessage.FileName, content)
+	if err := dao.CreateSystemMessage(ctx, importMessage.Email, messageTitle, content); err != nil {
+		slog.Error("Failed to save system message", "err", err)
+	}
+}
+
+func getObjectFromOSS(ctx context.Context, objectName string) ([]byte, error) {
+	cfg := oss.NewConfig().
+		WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+			config.Cfg.OSS.AccessKeyID,
+			config.Cfg.OSS.AccessKeySecret,
+		)).
+		WithRegion(config.Cfg.OSS.Region).
+		WithHttpClient(utils.GlobalHTTPClient)
+	client := oss.NewClient(cfg)
+
+	result, err := client.GetObject(ctx, &oss.GetObjectRequest{
+		Bucket: oss.Ptr(config.Cfg.OSS.BucketName),
+		Key:    oss.Ptr(objectName),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer result.Body.Close()
+
+	data, err := io.ReadAll(result.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object: %v", err)
+	}
+
+	return data, nil
+}
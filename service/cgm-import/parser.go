@@ -0,0 +1,200 @@
+package cgmimport
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// mg/dL 转换为 mmol/L 的系数
+	mgdlToMmol = 1 / 18.018
+
+	// CGM 超出量程时导出 "Low"/"High"，按量程边界记录(mmol/L)
+	lowReadingValue  = 2.2
+	highReadingValue = 22.2
+
+	// 在文件前若干行中查找表头，兼容表头前带有元数据行的导出格式
+	maxHeaderSearchLines = 5
+)
+
+// 常见 CGM 导出文件的时间格式
+var timestampLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006/01/02 15:04",
+	"02-01-2006 15:04",
+	"01-02-2006 03:04 PM",
+}
+
+// Reading CGM 血糖读数
+type Reading struct {
+	MeasuredAt time.Time
+	Value      float32
+}
+
+// CSVParser CGM 导出文件解析器
+type CSVParser interface {
+	// 根据表头判断是否支持该导出格式
+	CanParse(header []string) bool
+
+	// 解析一行数据，非血糖读数行返回 nil
+	ParseRow(row []string, loc *time.Location) (*Reading, error)
+}
+
+// CGM 导出文件解析器注册表
+var csvParsers = []func(header []string) CSVParser{
+	newLibreParser,
+	newDexcomParser,
+}
+
+// LibreParser 解析 FreeStyle Libre (LibreView) 导出文件
+type LibreParser struct {
+	timestampIdx  int
+	recordTypeIdx int
+	historicIdx   int
+	scanIdx       int
+	isMgdl        bool
+}
+
+func newLibreParser(header []string) CSVParser {
+	p := &LibreParser{
+		timestampIdx:  findColumn(header, "Device Timestamp"),
+		recordTypeIdx: findColumn(header, "Record Type"),
+		historicIdx:   findColumn(header, "Historic Glucose"),
+		scanIdx:       findColumn(header, "Scan Glucose"),
+	}
+	if p.historicIdx >= 0 {
+		p.isMgdl = strings.Contains(header[p.historicIdx], "mg/dL")
+	}
+	return p
+}
+
+func (p *LibreParser) CanParse(header []string) bool {
+	return p.timestampIdx >= 0 && p.recordTypeIdx >= 0 && p.historicIdx >= 0
+}
+
+func (p *LibreParser) ParseRow(row []string, loc *time.Location) (*Reading, error) {
+	// Record Type 为 0 表示自动记录的历史读数，为 1 表示手动扫描读数，其余为备注、胰岛素等事件
+	var valueIdx int
+	switch cell(row, p.recordTypeIdx) {
+	case "0":
+		valueIdx = p.historicIdx
+	case "1":
+		valueIdx = p.scanIdx
+	default:
+		return nil, nil
+	}
+
+	return parseReading(cell(row, p.timestampIdx), cell(row, valueIdx), p.isMgdl, loc)
+}
+
+// DexcomParser 解析 Dexcom Clarity 导出文件
+type DexcomParser struct {
+	timestampIdx int
+	eventTypeIdx int
+	glucoseIdx   int
+	isMgdl       bool
+}
+
+func newDexcomParser(header []string) CSVParser {
+	p := &DexcomParser{
+		timestampIdx: findColumn(header, "Timestamp"),
+		eventTypeIdx: findColumn(header, "Event Type"),
+		glucoseIdx:   findColumn(header, "Glucose Value"),
+	}
+	if p.glucoseIdx >= 0 {
+		p.isMgdl = strings.Contains(header[p.glucoseIdx], "mg/dL")
+	}
+	return p
+}
+
+func (p *DexcomParser) CanParse(header []string) bool {
+	return p.timestampIdx >= 0 && p.eventTypeIdx >= 0 && p.glucoseIdx >= 0
+}
+
+func (p *DexcomParser) ParseRow(row []string, loc *time.Location) (*Reading, error) {
+	// 仅 EGV (Estimated Glucose Value) 事件为血糖读数
+	if cell(row, p.eventTypeIdx) != "EGV" {
+		return nil, nil
+	}
+	return parseReading(cell(row, p.timestampIdx), cell(row, p.glucoseIdx), p.isMgdl, loc)
+}
+
+// findParser 在文件前若干行中查找表头并匹配解析器，返回解析器和表头所在行号
+func findParser(rows [][]string) (CSVParser, int, error) {
+	for i := 0; i < len(rows) && i < maxHeaderSearchLines; i++ {
+		for _, newParser := range csvParsers {
+			parser := newParser(rows[i])
+			if parser.CanParse(rows[i]) {
+				return parser, i, nil
+			}
+		}
+	}
+	return nil, 0, fmt.Errorf("unsupported cgm export format")
+}
+
+func parseReading(timestampStr, valueStr string, isMgdl bool, loc *time.Location) (*Reading, error) {
+	if valueStr == "" {
+		return nil, nil
+	}
+
+	measuredAt, err := parseTimestamp(timestampStr, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	var value float32
+	switch strings.ToLower(valueStr) {
+	case "low":
+		value = lowReadingValue
+	case "high":
+		value = highReadingValue
+	default:
+		v, err := strconv.ParseFloat(valueStr, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glucose value: %s", valueStr)
+		}
+		if isMgdl {
+			v *= mgdlToMmol
+		}
+		value = float32(v)
+	}
+
+	if value < lowReadingValue || value > highReadingValue {
+		return nil, fmt.Errorf("glucose value out of range: %s", valueStr)
+	}
+
+	return &Reading{
+		MeasuredAt: measuredAt.UTC(),
+		Value:      value,
+	}, nil
+}
+
+func parseTimestamp(s string, loc *time.Location) (time.Time, error) {
+	for _, layout := range timestampLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp: %s", s)
+}
+
+// 按列名前缀查找列索引，兼容带单位或格式说明的列名，如 "Glucose Value (mg/dL)"
+func findColumn(header []string, name string) int {
+	for i, col := range header {
+		if strings.HasPrefix(strings.TrimSpace(col), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func cell(row []string, idx int) string {
+	if idx < 0 || idx >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[idx])
+}
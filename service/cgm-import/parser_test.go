@@ -0,0 +1,233 @@
+package cgmimport
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	libreHeader     = []string{"Device", "Serial Number", "Device Timestamp", "Record Type", "Historic Glucose mmol/L", "Scan Glucose mmol/L"}
+	libreMgdlHeader = []string{"Device", "Serial Number", "Device Timestamp", "Record Type", "Historic Glucose mg/dL", "Scan Glucose mg/dL"}
+	dexcomHeader    = []string{"Index", "Timestamp (YYYY-MM-DDThh:mm:ss)", "Event Type", "Event Subtype", "Glucose Value (mg/dL)"}
+)
+
+func TestFindParser(t *testing.T) {
+	tests := []struct {
+		name          string
+		rows          [][]string
+		wantType      string
+		wantHeaderIdx int
+		wantErr       bool
+	}{
+		{name: "libre", rows: [][]string{libreHeader}, wantType: "*cgmimport.LibreParser"},
+		{
+			name:          "libre with metadata rows",
+			rows:          [][]string{{"Glucose Data", "Generated on", "2024-01-01"}, libreHeader},
+			wantType:      "*cgmimport.LibreParser",
+			wantHeaderIdx: 1,
+		},
+		{name: "dexcom", rows: [][]string{dexcomHeader}, wantType: "*cgmimport.DexcomParser"},
+		{name: "unknown header", rows: [][]string{{"time", "value"}}, wantErr: true},
+		{
+			name:    "header beyond search lines",
+			rows:    [][]string{{"a"}, {"b"}, {"c"}, {"d"}, {"e"}, dexcomHeader},
+			wantErr: true,
+		},
+		{name: "empty file", rows: nil, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, headerIdx, err := findParser(tt.rows)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("findParser() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("findParser() error = %v", err)
+			}
+			if got := reflect.TypeOf(parser).String(); got != tt.wantType {
+				t.Errorf("parser = %s, want %s", got, tt.wantType)
+			}
+			if headerIdx != tt.wantHeaderIdx {
+				t.Errorf("headerIdx = %d, want %d", headerIdx, tt.wantHeaderIdx)
+			}
+		})
+	}
+}
+
+func TestLibreParserParseRow(t *testing.T) {
+	shanghai := time.FixedZone("UTC+8", 8*60*60)
+
+	tests := []struct {
+		name    string
+		header  []string
+		row     []string
+		loc     *time.Location
+		want    *Reading
+		wantErr bool
+	}{
+		{
+			name:   "historic reading",
+			header: libreHeader,
+			row:    []string{"FreeStyle LibreLink", "X", "2024-01-02 08:15", "0", "6.5", ""},
+			loc:    time.UTC,
+			want:   &Reading{MeasuredAt: time.Date(2024, 1, 2, 8, 15, 0, 0, time.UTC), Value: 6.5},
+		},
+		{
+			name:   "scan reading",
+			header: libreHeader,
+			row:    []string{"FreeStyle LibreLink", "X", "02-01-2024 08:15", "1", "", "7.1"},
+			loc:    time.UTC,
+			want:   &Reading{MeasuredAt: time.Date(2024, 1, 2, 8, 15, 0, 0, time.UTC), Value: 7.1},
+		},
+		{
+			name:   "converted to utc",
+			header: libreHeader,
+			row:    []string{"FreeStyle LibreLink", "X", "2024-01-02 08:15", "0", "6.5", ""},
+			loc:    shanghai,
+			want:   &Reading{MeasuredAt: time.Date(2024, 1, 2, 0, 15, 0, 0, time.UTC), Value: 6.5},
+		},
+		{
+			name:   "mg/dL",
+			header: libreMgdlHeader,
+			row:    []string{"FreeStyle LibreLink", "X", "2024-01-02 08:15", "0", "180.18", ""},
+			loc:    time.UTC,
+			want:   &Reading{MeasuredAt: time.Date(2024, 1, 2, 8, 15, 0, 0, time.UTC), Value: 10},
+		},
+		{
+			name:   "low",
+			header: libreHeader,
+			row:    []string{"FreeStyle LibreLink", "X", "2024-01-02 08:15", "0", "Low", ""},
+			loc:    time.UTC,
+			want:   &Reading{MeasuredAt: time.Date(2024, 1, 2, 8, 15, 0, 0, time.UTC), Value: lowReadingValue},
+		},
+		{
+			name:   "high",
+			header: libreHeader,
+			row:    []string{"FreeStyle LibreLink", "X", "2024-01-02 08:15", "1", "", "HIGH"},
+			loc:    time.UTC,
+			want:   &Reading{MeasuredAt: time.Date(2024, 1, 2, 8, 15, 0, 0, time.UTC), Value: highReadingValue},
+		},
+		{
+			name:   "other record type",
+			header: libreHeader,
+			row:    []string{"FreeStyle LibreLink", "X", "2024-01-02 08:15", "6", "", ""},
+			loc:    time.UTC,
+		},
+		{
+			name:   "empty value",
+			header: libreHeader,
+			row:    []string{"FreeStyle LibreLink", "X", "2024-01-02 08:15", "0", "", ""},
+			loc:    time.UTC,
+		},
+		{
+			name:   "short row",
+			header: libreHeader,
+			row:    []string{"FreeStyle LibreLink", "X", "2024-01-02 08:15", "0"},
+			loc:    time.UTC,
+		},
+		{
+			name:    "invalid value",
+			header:  libreHeader,
+			row:     []string{"FreeStyle LibreLink", "X", "2024-01-02 08:15", "0", "abc", ""},
+			loc:     time.UTC,
+			wantErr: true,
+		},
+		{
+			name:    "value out of range",
+			header:  libreHeader,
+			row:     []string{"FreeStyle LibreLink", "X", "2024-01-02 08:15", "0", "35", ""},
+			loc:     time.UTC,
+			wantErr: true,
+		},
+		{
+			name:    "invalid timestamp",
+			header:  libreHeader,
+			row:     []string{"FreeStyle LibreLink", "X", "2024.01.02 08:15", "0", "6.5", ""},
+			loc:     time.UTC,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newLibreParser(tt.header).ParseRow(tt.row, tt.loc)
+			assertReading(t, got, err, tt.want, tt.wantErr)
+		})
+	}
+}
+
+func TestDexcomParserParseRow(t *testing.T) {
+	tests := []struct {
+		name    string
+		row     []string
+		want    *Reading
+		wantErr bool
+	}{
+		{
+			name: "egv",
+			row:  []string{"1", "2024-01-02T08:15:00", "EGV", "", "126"},
+			want: &Reading{MeasuredAt: time.Date(2024, 1, 2, 8, 15, 0, 0, time.UTC), Value: float32(126 * mgdlToMmol)},
+		},
+		{
+			name: "low",
+			row:  []string{"2", "2024-01-02T08:20:00", "EGV", "", "Low"},
+			want: &Reading{MeasuredAt: time.Date(2024, 1, 2, 8, 20, 0, 0, time.UTC), Value: lowReadingValue},
+		},
+		{
+			name: "other event",
+			row:  []string{"3", "2024-01-02T08:25:00", "Insulin", "Fast-Acting", ""},
+		},
+		{
+			name: "device metadata row",
+			row:  []string{"4", "", "Device", "", ""},
+		},
+		{
+			name:    "below range",
+			row:     []string{"5", "2024-01-02T08:30:00", "EGV", "", "20"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid timestamp",
+			row:     []string{"6", "not a time", "EGV", "", "126"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newDexcomParser(dexcomHeader).ParseRow(tt.row, time.UTC)
+			assertReading(t, got, err, tt.want, tt.wantErr)
+		})
+	}
+}
+
+func assertReading(t *testing.T, got *Reading, err error, want *Reading, wantErr bool) {
+	t.Helper()
+	if wantErr {
+		if err == nil {
+			t.Fatalf("ParseRow() error = nil, want error")
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("ParseRow() error = %v", err)
+	}
+	if want == nil {
+		if got != nil {
+			t.Fatalf("ParseRow() = %+v, want nil", got)
+		}
+		return
+	}
+	if got == nil {
+		t.Fatalf("ParseRow() = nil, want %+v", want)
+	}
+	if !got.MeasuredAt.Equal(want.MeasuredAt) || got.MeasuredAt.Location() != time.UTC {
+		t.Errorf("MeasuredAt = %v, want %v", got.MeasuredAt, want.MeasuredAt)
+	}
+	if math.Abs(float64(got.Value-want.Value)) > 1e-4 {
+		t.Errorf("Value = %v, want %v", got.Value, want.Value)
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"diabetes-agent-server/dao"
 	"diabetes-agent-server/model"
 	"diabetes-agent-server/request"
+	"diabetes-agent-server/response"
 	knowledgebase "diabetes-agent-server/service/knowledge-base"
+	llmprovider "diabetes-agent-server/service/llm-provider"
 	"diabetes-agent-server/utils"
 	_ "embed"
 	"encoding/json"
@@ -23,16 +25,21 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/tmc/langchaingo/agents"
 	"github.com/tmc/langchaingo/chains"
-	"github.com/tmc/langchaingo/llms/openai"
 	"github.com/tmc/langchaingo/memory"
 	"github.com/tmc/langchaingo/tools"
 )
 
-const methodToolCompleted = "tool_completed"
+const (
+	methodToolCompleted = "tool_completed"
 
-// Agent 对话服务的 HTTP 客户端，配置 300s 超时时间处理流式输出
-var httpClient = utils.NewHTTPClient(
-	utils.WithTimeout(300 * time.Second),
+	// 注入对话上下文的治疗方案变更的回溯时长
+	profileChangeLookback = 90 * 24 * time.Hour
+
+	// 注入对话上下文的餐后血糖飙升记录的回溯时长
+	mealSpikeLookback = 7 * 24 * time.Hour
+
+	// 注入对话上下文的用药依从性的统计时长
+	medicationAdherenceLookback = 7 * 24 * time.Hour
 )
 
 var (
@@ -61,14 +68,9 @@ type Agent struct {
 }
 
 func NewAgent(req request.ChatRequest, c *gin.Context) (*Agent, error) {
-	llm, err := openai.New(
-		openai.WithModel(req.AgentConfig.Model),
-		openai.WithToken(config.Cfg.Model.APIKey),
-		openai.WithBaseURL(config.Cfg.Model.BaseURL),
-		openai.WithHTTPClient(httpClient),
-	)
+	llm, err := llmprovider.ChatModel(req.AgentConfig.Model)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create llm client: %v", err)
+		return nil, fmt.Errorf("failed to create llm client: %w", err)
 	}
 
 	mcpClient, err := createMCPClient(c)
@@ -125,10 +127,11 @@ func (a *Agent) Call(ctx context.Context, req request.ChatRequest, c *gin.Contex
 		slog.Error("Failed to filter query", "err", err)
 	}
 
-	// 引入上传文件和知识库检索结果
-	if len(req.UploadedFiles) > 0 || req.EnableKnowledgeBaseRetrieval {
-		req.Query = a.buildUserContext(ctx, req, c)
-	}
+	// 记录本次对话中 LLM 调用所属的用户和会话
+	ctx = llmprovider.WithUsageScope(ctx, c.GetString("email"), req.SessionID)
+
+	// 引入用户健康数据、上传文件和知识库检索结果
+	req.Query = a.buildUserContext(ctx, req, c)
 
 	// 存储聊天信息的上下文，避免请求被取消时保存失败
 	saveCtx := context.Background()
@@ -304,13 +307,13 @@ func registerMCPNotificationHandler(ctx context.Context, mcpClient *client.Clien
 	})
 }
 
+// 构建用户上下文，健康数据始终注入，上传文件和知识库检索结果按请求注入。
+// 无任何上下文时返回原始 query，持久化时会从中提取原始 query
 func (a *Agent) buildUserContext(ctx context.Context, req request.ChatRequest, c *gin.Context) string {
 	email := c.GetString("email")
 
 	var userContext strings.Builder
-	userContext.WriteString("User Question:\n")
-	userContext.WriteString(req.Query + "\n\n")
-	userContext.WriteString("User Context:\n")
+	writeHealthContext(&userContext, email)
 
 	if len(req.UploadedFiles) > 0 {
 		utils.SendSSEMessage(c, utils.EventFileParseStart, nil)
@@ -334,5 +337,59 @@ func (a *Agent) buildUserContext(ctx context.Context, req request.ChatRequest, c
 		utils.SendSSEMessage(c, utils.EventKBRetrievalDone, nil)
 	}
 
-	return userContext.String()
+	if userContext.Len() == 0 {
+		return req.Query
+	}
+	return "User Question:\n" + req.Query + "\n\nUser Context:\n" + userContext.String()
+}
+
+// 注入用户的健康数据，使 Agent 在普通对话中也能引用
+func writeHealthContext(userContext *strings.Builder, email string) {
+	labResults, err := dao.GetLatestLabResults(email)
+	if err != nil {
+		slog.Error("Failed to get latest lab results", "email", email, "err", err)
+	}
+	if len(labResults) > 0 {
+		labResultsJSON, _ := json.Marshal(labResults)
+		userContext.WriteString("Latest Lab Results:\n")
+		userContext.WriteString(string(labResultsJSON) + "\n\n")
+	}
+
+	now := time.Now().UTC()
+	profileChanges, err := dao.GetHealthProfileChangeImpacts(email, now.Add(-profileChangeLookback))
+	if err != nil {
+		slog.Error("Failed to get health profile changes", "email", email, "err", err)
+	}
+	if len(profileChanges) > 0 {
+		profileChangesJSON, _ := json.Marshal(profileChanges)
+		userContext.WriteString("Recent Treatment Changes (with average glucose before/after):\n")
+		userContext.WriteString(string(profileChangesJSON) + "\n\n")
+	}
+
+	meals, err := dao.GetMealRecords(email, now.Add(-mealSpikeLookback), now)
+	if err != nil {
+		slog.Error("Failed to get meal records", "email", email, "err", err)
+	}
+	var spikes []response.GetMealRecordsResponse
+	for _, meal := range meals {
+		if meal.GlucoseRise != nil && *meal.GlucoseRise >= dao.GlucoseSpikeThreshold {
+			spikes = append(spikes, meal)
+		}
+	}
+	if len(spikes) > 0 {
+		spikesJSON, _ := json.Marshal(spikes)
+		userContext.WriteString(fmt.Sprintf("Recent Meal Glucose Spikes (post-meal rise >= %.1f mmol/L in the last 7 days):\n",
+			dao.GlucoseSpikeThreshold))
+		userContext.WriteString(string(spikesJSON) + "\n\n")
+	}
+
+	adherence, err := dao.GetMedicationAdherenceStats(email, now.Add(-medicationAdherenceLookback), now)
+	if err != nil {
+		slog.Error("Failed to get medication adherence stats", "email", email, "err", err)
+	}
+	if adherence != nil && len(adherence.Plans) > 0 {
+		adherenceJSON, _ := json.Marshal(adherence)
+		userContext.WriteString("Medication Adherence (last 7 days):\n")
+		userContext.WriteString(string(adherenceJSON) + "\n\n")
+	}
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"diabetes-agent-server/config"
 	"diabetes-agent-server/request"
+	llmprovider "diabetes-agent-server/service/llm-provider"
 	ossauth "diabetes-agent-server/service/oss-auth"
 	"diabetes-agent-server/utils"
 	"encoding/json"
@@ -16,11 +17,8 @@ import (
 	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss/credentials"
 	"github.com/apache/rocketmq-client-go/v2/primitive"
 	"github.com/tmc/langchaingo/llms"
-	"github.com/tmc/langchaingo/llms/openai"
 )
 
-const modelNameVLM = "qwen3-vl-flash"
-
 var (
 	imageExtensions = []string{".png", ".jpg", ".jpeg", ".gif", ".webp"}
 	docExtensions   = []string{".doc", ".docx", ".pdf", ".xls", ".xlsx", ".txt", ".md"}
@@ -94,12 +92,7 @@ func handleChatFiles(ctx context.Context, req request.ChatRequest, email string)
 
 // 调用视觉理解模型生成图片的内容摘要
 func handleImages(ctx context.Context, urls []string) (string, error) {
-	vlm, err := openai.New(
-		openai.WithModel(modelNameVLM),
-		openai.WithToken(config.Cfg.Model.APIKey),
-		openai.WithBaseURL(config.Cfg.Model.BaseURL),
-		openai.WithHTTPClient(utils.GlobalHTTPClient),
-	)
+	vlm, err := llmprovider.Model(llmprovider.TaskVision)
 	if err != nil {
 		return "", fmt.Errorf("failed to create vlm client: %w", err)
 	}
@@ -198,50 +191,32 @@ func HandleDeleteUploadedFilesMessage(ctx context.Context, msg *primitive.Messag
 		return fmt.Errorf("failed to unmarshal message: %w", err)
 	}
 
-	cfg := &oss.Config{
-		Region: oss.Ptr(config.Cfg.OSS.Region),
-		CredentialsProvider: credentials.NewStaticCredentialsProvider(
-			config.Cfg.OSS.AccessKeyID,
-			config.Cfg.OSS.AccessKeySecret,
-		),
-		HttpClient: utils.GlobalHTTPClient,
-	}
-	client := oss.NewClient(cfg)
+	_, err := DeleteUploadedFiles(ctx, message.Email, message.SessionID)
+	return err
+}
 
+// DeleteUploadedFiles 删除用户在指定会话中上传的文件，sessionID 为空时删除用户的所有聊天文件，返回删除的文件数
+func DeleteUploadedFiles(ctx context.Context, email, sessionID string) (int, error) {
 	// 构造对象前缀，过滤出当前会话的文件
-	prefix := strings.Join([]string{ossauth.OSSKeyPrefixUpload, message.Email, message.SessionID}, "/")
-	result, err := client.ListObjectsV2(ctx, &oss.ListObjectsV2Request{
-		Bucket: oss.Ptr(config.Cfg.OSS.BucketName),
-		Prefix: oss.Ptr(prefix),
-	})
-	if err != nil {
-		return fmt.Errorf("failed to list objects: %w", err)
+	prefix := strings.Join([]string{ossauth.OSSKeyPrefixUpload, email, ""}, "/")
+	if sessionID != "" {
+		prefix += sessionID + "/"
 	}
 
-	if result.KeyCount == 0 {
-		slog.Warn("no objects found")
-		return nil
-	}
-
-	deleteObjects := make([]oss.DeleteObject, 0, result.KeyCount)
-	for i := 0; i < result.KeyCount; i++ {
-		deleteObjects = append(deleteObjects, oss.DeleteObject{
-			Key: result.Contents[i].Key,
-		})
+	count, err := ossauth.DeleteObjectsByPrefix(ctx, prefix)
+	if err != nil {
+		return count, fmt.Errorf("failed to delete uploaded files: %w", err)
 	}
 
-	_, err = client.DeleteMultipleObjects(ctx, &oss.DeleteMultipleObjectsRequest{
-		Bucket:  oss.Ptr(config.Cfg.OSS.BucketName),
-		Objects: deleteObjects,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to delete objects: %w", err)
+	if count == 0 {
+		slog.Warn("no objects found", "object_prefix", prefix)
+		return 0, nil
 	}
 
 	slog.Info("deleted uploaded files",
 		"object_prefix", prefix,
-		"count", result.KeyCount,
+		"count", count,
 	)
 
-	return nil
+	return count, nil
 }
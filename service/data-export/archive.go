@@ -0,0 +1,136 @@
+package dataexport
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// 写入 CSV 文件开头的 UTF-8 BOM，使 Excel 正确识别中文
+const utf8BOM = "\ufeff"
+
+// 以这些字符开头的单元格会被 Excel 等表格软件当作公式执行
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// 将同一份数据分别写入 ZIP 中的 {name}.json 和 {name}.csv
+func writeDataset[T any](zw *zip.Writer, name string, records []T) error {
+	if records == nil {
+		records = []T{}
+	}
+
+	jsonFile, err := zw.Create(name + ".json")
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(jsonFile)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(records); err != nil {
+		return fmt.Errorf("failed to write %s.json: %v", name, err)
+	}
+
+	csvFile, err := zw.Create(name + ".csv")
+	if err != nil {
+		return err
+	}
+	if err := writeCSV(csvFile, records); err != nil {
+		return fmt.Errorf("failed to write %s.csv: %v", name, err)
+	}
+	return nil
+}
+
+// 按结构体字段的 json 标签生成表头，每条记录一行，嵌套结构以 JSON 字符串写入单元格
+func writeCSV[T any](w io.Writer, records []T) error {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return err
+	}
+
+	fields, header := csvColumns(reflect.TypeFor[T]())
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		v := reflect.ValueOf(record)
+		row := make([]string, 0, len(fields))
+		for _, index := range fields {
+			cell, err := formatCSVValue(v.Field(index))
+			if err != nil {
+				return err
+			}
+			row = append(row, cell)
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// 返回参与导出的字段下标和列名，跳过未导出字段和 json 标签为 "-" 的字段
+func csvColumns(t reflect.Type) ([]int, []string) {
+	var fields []int
+	var header []string
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fields = append(fields, i)
+		header = append(header, name)
+	}
+	return fields, header
+}
+
+func formatCSVValue(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339), nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return escapeCSVFormula(v.String()), nil
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprint(v.Interface()), nil
+	default:
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
+
+// 为可能被解析为公式的文本单元格添加单引号前缀，防止 CSV 注入
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
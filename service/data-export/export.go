@@ -0,0 +1,356 @@
+package dataexport
+
+import (
+	"archive/zip"
+	"context"
+	"diabetes-agent-server/config"
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/request"
+	"diabetes-agent-server/service/email"
+	ossauth "diabetes-agent-server/service/oss-auth"
+	"diabetes-agent-server/utils"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/smtp"
+	"os"
+	"path"
+	"time"
+
+	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss"
+	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss/credentials"
+	"github.com/apache/rocketmq-client-go/v2/primitive"
+)
+
+const (
+	// 执行中的任务超过该时长未更新视为中断，可被重新领取
+	staleJobTimeout = 30 * time.Minute
+
+	// 通知中下载链接的有效期，过期后可在导出记录中重新获取
+	DownloadURLExpires = 24 * time.Hour
+)
+
+//go:embed notification.html
+var notificationTemplate string
+
+// ExportMessage 账号数据导出任务消息，任务记录由接口预先创建
+type ExportMessage struct {
+	JobID uint `json:"job_id"`
+}
+
+type NotificationData struct {
+	DownloadURL string
+	ExpireHours int
+}
+
+// 聊天消息的导出字段，不包含关联的思考步骤和工具调用结果，二者单独导出
+type chatMessage struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	SessionID string    `json:"session_id"`
+	Role      string    `json:"role"`
+	Content   string    `json:"content"`
+	Summary   string    `json:"summary"`
+}
+
+func (chatMessage) TableName() string {
+	return "chat_message"
+}
+
+// HandleExportMessage 导出用户的所有数据，失败时记录原因并返回错误由 MQ 重试。
+// 任务通过条件更新领取，重复投递的消息不会重复导出
+func HandleExportMessage(ctx context.Context, msg *primitive.MessageExt) error {
+	var exportMessage ExportMessage
+	if err := json.Unmarshal(msg.Body, &exportMessage); err != nil {
+		return fmt.Errorf("failed to unmarshal message body: %v", err)
+	}
+
+	job, err := dao.GetDataExportJobByID(exportMessage.JobID)
+	if err != nil {
+		return fmt.Errorf("failed to get data export job: %v", err)
+	}
+	if job == nil {
+		return nil
+	}
+
+	claimed, err := dao.ClaimDataExportJob(job.ID, time.Now().Add(-staleJobTimeout))
+	if err != nil {
+		return fmt.Errorf("failed to claim data export job: %v", err)
+	}
+	if !claimed {
+		slog.Info("data export job already claimed", "job_id", job.ID, "status", job.Status)
+		return nil
+	}
+
+	if err := runExportJob(ctx, job); err != nil {
+		if err := dao.FailDataExportJob(job.ID, err.Error()); err != nil {
+			slog.Error("Failed to update data export job status", "err", err)
+		}
+		return fmt.Errorf("failed to run data export job %d: %v", job.ID, err)
+	}
+	return nil
+}
+
+// 打包用户数据上传到 OSS，完成后通过系统消息和邮件发送下载链接
+func runExportJob(ctx context.Context, job *model.DataExportJob) error {
+	client := newOSSClient()
+
+	// ZIP 先写入临时文件再上传，避免导出数据较多时占用大量内存
+	archive, err := os.CreateTemp("", "data-export-*.zip")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	if err := buildArchive(ctx, client, job.UserEmail, archive); err != nil {
+		return err
+	}
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek temp file: %v", err)
+	}
+
+	fileName := fmt.Sprintf("data-export-%s.zip", time.Now().Format("20060102150405"))
+	objectName, err := ossauth.GenerateKey(request.OSSAuthRequest{
+		Namespace: ossauth.OSSKeyPrefixDataExport,
+		Email:     job.UserEmail,
+		FileName:  fileName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate oss key: %v", err)
+	}
+
+	_, err = client.PutObject(ctx, &oss.PutObjectRequest{
+		Bucket:      oss.Ptr(config.Cfg.OSS.BucketName),
+		Key:         oss.Ptr(objectName),
+		Body:        archive,
+		ContentType: oss.Ptr("application/zip"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload data export: %v", err)
+	}
+
+	if err := dao.CompleteDataExportJob(job.ID, fileName, objectName); err != nil {
+		return fmt.Errorf("failed to complete data export job: %v", err)
+	}
+
+	notify(ctx, job.UserEmail, fileName)
+	return nil
+}
+
+// 将用户的所有数据打包为 ZIP 写入 w，每类数据同时导出 JSON 和 CSV，已生成的健康报告文件放在 reports 目录下
+func buildArchive(ctx context.Context, client *oss.Client, email string, w io.Writer) error {
+	user, err := dao.GetUserByEmail(email)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %v", err)
+	}
+	if user == nil {
+		return fmt.Errorf("%s is not registered", email)
+	}
+
+	sessions, err := dao.GetUserRecords[model.Session](email)
+	if err != nil {
+		return fmt.Errorf("failed to get sessions: %v", err)
+	}
+	sessionIDs := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		sessionIDs = append(sessionIDs, session.SessionID)
+	}
+
+	reports, err := dao.GetUserRecords[model.HealthWeeklyReport](email)
+	if err != nil {
+		return fmt.Errorf("failed to get health reports: %v", err)
+	}
+
+	zw := zip.NewWriter(w)
+
+	datasets := []func() error{
+		func() error { return writeDataset(zw, "user", []model.User{*user}) },
+		func() error { return exportUserRecords[model.HealthProfile](zw, "health_profile", email) },
+		func() error {
+			return exportUserRecords[model.HealthProfileHistory](zw, "health_profile_history", email)
+		},
+		func() error { return exportUserRecords[model.BloodGlucoseRecord](zw, "blood_glucose_records", email) },
+		func() error { return exportUserRecords[model.ExerciseRecord](zw, "exercise_records", email) },
+		func() error { return exportUserRecords[model.MealRecord](zw, "meal_records", email) },
+		func() error { return exportUserRecords[model.VitalRecord](zw, "vital_records", email) },
+		func() error { return exportUserRecords[model.LabResult](zw, "lab_results", email) },
+		func() error { return exportUserRecords[model.MedicationPlan](zw, "medication_plans", email) },
+		func() error { return exportUserRecords[model.MedicationIntake](zw, "medication_intakes", email) },
+		func() error { return exportUserRecords[model.AlertRule](zw, "alert_rules", email) },
+		func() error { return writeDataset(zw, "chat_sessions", sessions) },
+		func() error { return exportSessionRecords[chatMessage](zw, "chat_messages", sessionIDs) },
+		func() error {
+			return exportSessionRecords[model.InterMediateSteps](zw, "chat_intermediate_steps", sessionIDs)
+		},
+		func() error {
+			return exportSessionRecords[model.ToolCallResults](zw, "chat_tool_call_results", sessionIDs)
+		},
+		func() error {
+			return exportSessionRecords[model.ChatUploadedFile](zw, "chat_uploaded_files", sessionIDs)
+		},
+		func() error { return exportUserRecords[model.KnowledgeMetadata](zw, "knowledge_metadata", email) },
+		func() error { return writeDataset(zw, "health_reports", reports) },
+		func() error { return exportUserRecords[model.SystemMessage](zw, "system_messages", email) },
+		func() error { return exportUserRecords[model.LLMUsage](zw, "llm_usage", email) },
+		func() error {
+			shares, err := dao.GetCareSharesByPatient(email)
+			if err != nil {
+				return fmt.Errorf("failed to get care shares: %v", err)
+			}
+			return writeDataset(zw, "care_shares", shares)
+		},
+		func() error {
+			logs, err := dao.GetAllAccessAuditLogs(email)
+			if err != nil {
+				return fmt.Errorf("failed to get care access logs: %v", err)
+			}
+			return writeDataset(zw, "care_access_logs", logs)
+		},
+	}
+	for _, write := range datasets {
+		if err := write(); err != nil {
+			return err
+		}
+	}
+
+	for _, report := range reports {
+		if report.Status != model.ReportStatusCompleted {
+			continue
+		}
+		for _, objectName := range []string{report.ObjectName, report.PDFObjectName} {
+			if objectName == "" {
+				continue
+			}
+			if err := copyObject(ctx, client, zw, objectName, "reports/"+path.Base(objectName)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to close zip writer: %v", err)
+	}
+	return nil
+}
+
+func exportUserRecords[T any](zw *zip.Writer, name, email string) error {
+	records, err := dao.GetUserRecords[T](email)
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %v", name, err)
+	}
+	return writeDataset(zw, name, records)
+}
+
+func exportSessionRecords[T any](zw *zip.Writer, name string, sessionIDs []string) error {
+	records, err := dao.GetSessionRecords[T](sessionIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %v", name, err)
+	}
+	return writeDataset(zw, name, records)
+}
+
+// 将 OSS 上的文件写入 ZIP
+func copyObject(ctx context.Context, client *oss.Client, zw *zip.Writer, objectName, entryName string) error {
+	result, err := client.GetObject(ctx, &oss.GetObjectRequest{
+		Bucket: oss.Ptr(config.Cfg.OSS.BucketName),
+		Key:    oss.Ptr(objectName),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get object %s from oss: %v", objectName, err)
+	}
+	defer result.Body.Close()
+
+	w, err := zw.Create(entryName)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, result.Body); err != nil {
+		return fmt.Errorf("failed to write %s: %v", entryName, err)
+	}
+	return nil
+}
+
+func newOSSClient() *oss.Client {
+	cfg := oss.NewConfig().
+		WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+			config.Cfg.OSS.AccessKeyID,
+			config.Cfg.OSS.AccessKeySecret,
+		)).
+		WithRegion(config.Cfg.OSS.Region).
+		WithHttpClient(utils.GlobalHTTPClient)
+	return oss.NewClient(cfg)
+}
+
+// 通过系统消息和邮件发送下载链接，通知失败不影响任务结果
+func notify(ctx context.Context, toEmail, fileName string) {
+	url, err := ossauth.GeneratePresignedURL(request.OSSAuthRequest{
+		Namespace: ossauth.OSSKeyPrefixDataExport,
+		Email:     toEmail,
+		FileName:  fileName,
+		Expires:   DownloadURLExpires,
+	})
+	if err != nil {
+		slog.Error("Failed to generate data export url",
+			"email", toEmail,
+			"err", err,
+		)
+		return
+	}
+
+	data := NotificationData{
+		DownloadURL: url,
+		ExpireHours: int(DownloadURLExpires.Hours()),
+	}
+
+	content := fmt.Sprintf("您的账号数据已导出完成，下载链接 %d 小时内有效：%s 。链接过期后可在数据导出记录中重新获取。",
+		data.ExpireHours, url)
+	if err := dao.CreateSystemMessage(ctx, toEmail, "数据导出完成", content); err != nil {
+		slog.Error("Failed to save system message", "err", err)
+	}
+
+	if err := sendNotification(toEmail, data); err != nil {
+		slog.Error("Failed to send data export notification",
+			"email", toEmail,
+			"err", err,
+		)
+	}
+}
+
+func sendNotification(toEmail string, data NotificationData) error {
+	user, err := dao.GetUserByEmail(toEmail)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %v", err)
+	}
+
+	// 未验证邮箱的用户不发送邮件
+	if user == nil || !user.EmailVerified {
+		return nil
+	}
+
+	cfg := config.Cfg.Email
+	message, err := buildNotificationMessage(cfg.FromEmail, toEmail, data)
+	if err != nil {
+		return err
+	}
+
+	auth := smtp.PlainAuth(
+		"",
+		cfg.FromEmail,
+		cfg.Password,
+		cfg.Host,
+	)
+	return email.Send(fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
+		auth,
+		cfg.FromEmail,
+		[]string{toEmail},
+		[]byte(message),
+	)
+}
+
+func buildNotificationMessage(fromEmail string, toEmail string, data NotificationData) (string, error) {
+	return email.BuildHTMLMessage(fromEmail, toEmail, "您的账号数据已导出完成", notificationTemplate, data)
+}
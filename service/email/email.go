@@ -1,11 +1,19 @@
 package email
 
 import (
+	"bytes"
 	"crypto/tls"
+	"fmt"
+	"html/template"
+	"mime"
 	"net"
 	"net/smtp"
+	"strings"
 )
 
+// 发件人显示名称
+const senderName = "Diabetes Agent"
+
 func Send(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
 	host, _, _ := net.SplitHostPort(addr)
 
@@ -55,3 +63,28 @@ func Send(addr string, auth smtp.Auth, from string, to []string, msg []byte) err
 
 	return w.Close()
 }
+
+// BuildHTMLMessage 使用 HTML 模板渲染邮件正文并生成完整的邮件消息，
+// 模板数据按 HTML 转义，主题和发件人名称按 RFC 2047 编码以支持中文
+func BuildHTMLMessage(from, to, subject, tmpl string, data any) (string, error) {
+	t, err := template.New("email").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %v", err)
+	}
+
+	var body bytes.Buffer
+	if err := t.Execute(&body, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %v", err)
+	}
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("From: %s <%s>\r\n", mime.BEncoding.Encode("UTF-8", senderName), from))
+	content.WriteString(fmt.Sprintf("To: %s\r\n", to))
+	content.WriteString(fmt.Sprintf("Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", subject)))
+	content.WriteString("MIME-Version: 1.0\r\n")
+	content.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	content.WriteString("\r\n")
+	content.WriteString(body.String())
+
+	return content.String(), nil
+}
@@ -0,0 +1,202 @@
+package glucosealert
+
+import (
+	"context"
+	"diabetes-agent-server/config"
+	"diabetes-agent-server/constants"
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/model"
+	"diabetes-agent-server/service/email"
+	glucoseanalytics "diabetes-agent-server/service/glucose-analytics"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/smtp"
+	"time"
+
+	"github.com/apache/rocketmq-client-go/v2/primitive"
+)
+
+// MaxAlertDelay 测量时间早于该时长的记录（如补录的历史数据）不触发提醒
+const MaxAlertDelay = 6 * time.Hour
+
+//go:embed notification.html
+var notificationTemplate string
+
+type AlertMessage struct {
+	Email    string `json:"email"`
+	RecordID uint   `json:"record_id"`
+}
+
+// Sender 投递提醒评估任务消息，由调用方注入，避免与 mq 包循环依赖
+type Sender func(ctx context.Context, msg AlertMessage) error
+
+// SendAlertMessages 为测量时间在提醒时效内的记录投递提醒评估任务，更早的历史数据不触发提醒。
+// 投递失败仅记录日志，不影响记录写入，重复提醒由规则冷却期避免
+func SendAlertMessages(ctx context.Context, send Sender, records []model.BloodGlucoseRecord) {
+	for _, record := range records {
+		if time.Since(record.MeasuredAt) > MaxAlertDelay {
+			continue
+		}
+		err := send(ctx, AlertMessage{
+			Email:    record.UserEmail,
+			RecordID: record.ID,
+		})
+		if err != nil {
+			slog.Error("Failed to send glucose alert message",
+				"record_id", record.ID,
+				"err", err,
+			)
+		}
+	}
+}
+
+// HandleAlertMessage 对新增的血糖记录评估用户的提醒规则，
+// 规则触发时发送系统消息，并按规则配置发送邮件
+func HandleAlertMessage(ctx context.Context, msg *primitive.MessageExt) error {
+	var alertMessage AlertMessage
+	if err := json.Unmarshal(msg.Body, &alertMessage); err != nil {
+		return fmt.Errorf("failed to unmarshal message body: %v", err)
+	}
+
+	record, err := dao.GetBloodGlucoseRecord(alertMessage.Email, alertMessage.RecordID)
+	if err != nil {
+		return fmt.Errorf("failed to get blood glucose record: %v", err)
+	}
+	// 记录已被删除
+	if record == nil {
+		return nil
+	}
+
+	if time.Since(record.MeasuredAt) > MaxAlertDelay {
+		return nil
+	}
+
+	rules, err := loadRules(alertMessage.Email)
+	if err != nil {
+		return fmt.Errorf("failed to load alert rules: %v", err)
+	}
+
+	recent, err := loadRecentRecords(record, rules)
+	if err != nil {
+		return fmt.Errorf("failed to get recent blood glucose records: %v", err)
+	}
+
+	for _, rule := range rules {
+		alert := evaluate(rule, record, recent)
+		if alert == nil {
+			continue
+		}
+
+		fire, err := acquireCooldown(ctx, rule)
+		if err != nil {
+			return fmt.Errorf("failed to check alert cooldown: %v", err)
+		}
+		if !fire {
+			continue
+		}
+
+		slog.Info("glucose alert fired",
+			"email", alertMessage.Email,
+			"record_id", record.ID,
+			"rule_type", rule.Type,
+			"rule_id", rule.ID,
+		)
+
+		// 冷却期已占用，后续步骤失败时仅记录日志，避免重试导致重复提醒
+		if err := dao.CreateSystemMessage(ctx, alertMessage.Email, alert.Title, alert.Content); err != nil {
+			slog.Error("Failed to save system message", "err", err)
+		}
+
+		if rule.NotifyEmail && isEmailVerified(alertMessage.Email) {
+			if err := sendEmail(alertMessage.Email, alert); err != nil {
+				slog.Error("Failed to send alert email",
+					"email", alertMessage.Email,
+					"err", err,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// 未验证邮箱的用户不发送邮件提醒
+func isEmailVerified(email string) bool {
+	user, err := dao.GetUserByEmail(email)
+	if err != nil {
+		slog.Error("Failed to get user", "email", email, "err", err)
+		return false
+	}
+	return user != nil && user.EmailVerified
+}
+
+// 加载用户已启用的规则，用户未配置任何规则时使用默认规则
+func loadRules(email string) ([]model.AlertRule, error) {
+	count, err := dao.CountAlertRules(email)
+	if err != nil {
+		return nil, err
+	}
+
+	if count == 0 {
+		profile, err := dao.GetHealthProfile(email)
+		if err != nil {
+			return nil, err
+		}
+		return defaultRules(email, glucoseanalytics.BandsFromProfile(profile)), nil
+	}
+
+	return dao.GetEnabledAlertRules(email)
+}
+
+// 加载 rising 规则所需的最近读数
+func loadRecentRecords(record *model.BloodGlucoseRecord, rules []model.AlertRule) ([]model.BloodGlucoseRecord, error) {
+	limit := 0
+	for _, rule := range rules {
+		if rule.Type == model.AlertRuleTypeRising {
+			limit = max(limit, consecutiveCount(rule))
+		}
+	}
+	if limit == 0 {
+		return nil, nil
+	}
+
+	return dao.GetLatestBloodGlucoseRecords(record.UserEmail,
+		record.MeasuredAt.Add(-risingWindow), record.MeasuredAt, limit)
+}
+
+// 占用规则的冷却期，冷却期内已提醒过时返回 false
+func acquireCooldown(ctx context.Context, rule model.AlertRule) (bool, error) {
+	if cooldown(rule) <= 0 {
+		return true, nil
+	}
+
+	key := fmt.Sprintf(constants.KeyGlucoseAlertCooldown, rule.UserEmail, rule.Type, rule.ID)
+	return dao.RedisClient.SetNX(ctx, key, 1, cooldown(rule)).Result()
+}
+
+func sendEmail(toEmail string, alert *Alert) error {
+	cfg := config.Cfg.Email
+	message, err := buildAlertMessage(cfg.FromEmail, toEmail, alert)
+	if err != nil {
+		return err
+	}
+
+	auth := smtp.PlainAuth(
+		"",
+		cfg.FromEmail,
+		cfg.Password,
+		cfg.Host,
+	)
+	return email.Send(fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
+		auth,
+		cfg.FromEmail,
+		[]string{toEmail},
+		[]byte(message),
+	)
+}
+
+func buildAlertMessage(fromEmail, toEmail string, alert *Alert) (string, error) {
+	return email.BuildHTMLMessage(fromEmail, toEmail, alert.Title, notificationTemplate, alert)
+}
@@ -0,0 +1,131 @@
+package glucosealert
+
+import (
+	"diabetes-agent-server/model"
+	glucoseanalytics "diabetes-agent-server/service/glucose-analytics"
+	"fmt"
+	"time"
+)
+
+const (
+	// rising 规则默认的连续读数次数
+	defaultConsecutiveCount = 3
+
+	// 默认的提醒冷却期
+	defaultCooldownMinutes = 60
+
+	// rising 规则只考察该时间窗口内的读数
+	risingWindow = 6 * time.Hour
+
+	measuredAtLayout = "2006-01-02 15:04"
+)
+
+// 视为空腹血糖的用餐状态
+var fastingDiningStatuses = map[string]bool{
+	"fasting":          true,
+	"before_breakfast": true,
+}
+
+// Alert 规则触发后生成的提醒
+type Alert struct {
+	Rule    model.AlertRule
+	Title   string
+	Content string
+}
+
+// defaultRules 用户未配置任何规则时使用的默认规则，阈值取自健康档案中的血糖分级
+func defaultRules(email string, bands glucoseanalytics.Bands) []model.AlertRule {
+	return []model.AlertRule{
+		{
+			UserEmail:       email,
+			Type:            model.AlertRuleTypeBelow,
+			Threshold:       bands.Low,
+			CooldownMinutes: defaultCooldownMinutes,
+			Enabled:         true,
+		},
+		{
+			UserEmail:       email,
+			Type:            model.AlertRuleTypeAbove,
+			Threshold:       bands.VeryHigh,
+			CooldownMinutes: defaultCooldownMinutes,
+			Enabled:         true,
+		},
+	}
+}
+
+// evaluate 评估单条规则，recent 为新记录及其之前的读数，按测量时间升序排列且以新记录结尾
+func evaluate(rule model.AlertRule, record *model.BloodGlucoseRecord, recent []model.BloodGlucoseRecord) *Alert {
+	measuredAt := record.MeasuredAt.UTC().Format(measuredAtLayout)
+
+	switch rule.Type {
+	case model.AlertRuleTypeBelow:
+		if record.Value >= rule.Threshold {
+			return nil
+		}
+		return &Alert{
+			Rule:  rule,
+			Title: "低血糖提醒",
+			Content: fmt.Sprintf("您于 %s (UTC) 记录的血糖为 %.1f mmol/L，低于设定阈值 %.1f mmol/L。请立即补充 15g 左右的快速碳水化合物，15 分钟后复测。",
+				measuredAt, record.Value, rule.Threshold),
+		}
+
+	case model.AlertRuleTypeAbove:
+		if record.Value <= rule.Threshold {
+			return nil
+		}
+		return &Alert{
+			Rule:  rule,
+			Title: "高血糖提醒",
+			Content: fmt.Sprintf("您于 %s (UTC) 记录的血糖为 %.1f mmol/L，高于设定阈值 %.1f mmol/L。请注意补充水分，必要时检测酮体并咨询医生。",
+				measuredAt, record.Value, rule.Threshold),
+		}
+
+	case model.AlertRuleTypeFastingAbove:
+		if !fastingDiningStatuses[record.DiningStatus] || record.Value <= rule.Threshold {
+			return nil
+		}
+		return &Alert{
+			Rule:  rule,
+			Title: "空腹血糖偏高提醒",
+			Content: fmt.Sprintf("您于 %s (UTC) 记录的空腹血糖为 %.1f mmol/L，高于目标值 %.1f mmol/L。",
+				measuredAt, record.Value, rule.Threshold),
+		}
+
+	case model.AlertRuleTypeRising:
+		count := consecutiveCount(rule)
+		if len(recent) < count {
+			return nil
+		}
+
+		readings := recent[len(recent)-count:]
+		for i := 1; i < len(readings); i++ {
+			if readings[i].Value <= readings[i-1].Value {
+				return nil
+			}
+		}
+
+		rise := readings[len(readings)-1].Value - readings[0].Value
+		if rise < rule.Threshold {
+			return nil
+		}
+		return &Alert{
+			Rule:  rule,
+			Title: "血糖持续上升提醒",
+			Content: fmt.Sprintf("您最近 %d 次血糖读数持续上升，累计上升 %.1f mmol/L，最新读数为 %.1f mmol/L (%s UTC)。",
+				count, rise, record.Value, measuredAt),
+		}
+	}
+
+	return nil
+}
+
+func consecutiveCount(rule model.AlertRule) int {
+	if rule.ConsecutiveCount > 0 {
+		return rule.ConsecutiveCount
+	}
+	return defaultConsecutiveCount
+}
+
+func cooldown(rule model.AlertRule) time.Duration {
+	return time.Duration(rule.CooldownMinutes) * time.Minute
+}
@@ -0,0 +1,198 @@
+package glucosealert
+
+import (
+	"diabetes-agent-server/model"
+	glucoseanalytics "diabetes-agent-server/service/glucose-analytics"
+	"testing"
+	"time"
+)
+
+var testMeasuredAt = time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)
+
+func record(value float32, diningStatus string) model.BloodGlucoseRecord {
+	return model.BloodGlucoseRecord{
+		Value:        value,
+		MeasuredAt:   testMeasuredAt,
+		DiningStatus: diningStatus,
+	}
+}
+
+// 按时间升序生成读数，间隔 5 分钟，最后一条为新记录
+func recent(values ...float32) []model.BloodGlucoseRecord {
+	records := make([]model.BloodGlucoseRecord, len(values))
+	for i, v := range values {
+		records[i] = model.BloodGlucoseRecord{
+			Value:      v,
+			MeasuredAt: testMeasuredAt.Add(time.Duration(i-len(values)+1) * 5 * time.Minute),
+		}
+	}
+	return records
+}
+
+func TestEvaluate(t *testing.T) {
+	below := model.AlertRule{Type: model.AlertRuleTypeBelow, Threshold: 3.9}
+	above := model.AlertRule{Type: model.AlertRuleTypeAbove, Threshold: 13.9}
+	fastingAbove := model.AlertRule{Type: model.AlertRuleTypeFastingAbove, Threshold: 7}
+	rising := model.AlertRule{Type: model.AlertRuleTypeRising, Threshold: 2}
+	risingFour := model.AlertRule{Type: model.AlertRuleTypeRising, Threshold: 2, ConsecutiveCount: 4}
+
+	tests := []struct {
+		name      string
+		rule      model.AlertRule
+		record    model.BloodGlucoseRecord
+		recent    []model.BloodGlucoseRecord
+		wantTitle string
+	}{
+		{name: "below", rule: below, record: record(3.5, ""), wantTitle: "低血糖提醒"},
+		{name: "below at threshold", rule: below, record: record(3.9, "")},
+		{name: "above", rule: above, record: record(15, ""), wantTitle: "高血糖提醒"},
+		{name: "above at threshold", rule: above, record: record(13.9, "")},
+		{name: "fasting above", rule: fastingAbove, record: record(8, "fasting"), wantTitle: "空腹血糖偏高提醒"},
+		{name: "before breakfast above", rule: fastingAbove, record: record(8, "before_breakfast"), wantTitle: "空腹血糖偏高提醒"},
+		{name: "fasting at threshold", rule: fastingAbove, record: record(7, "fasting")},
+		{name: "not fasting", rule: fastingAbove, record: record(12, "after_lunch")},
+		{name: "no dining status", rule: fastingAbove, record: record(12, "")},
+		{
+			name:      "rising with default count",
+			rule:      rising,
+			record:    record(9, ""),
+			recent:    recent(5, 6, 7.5, 9),
+			wantTitle: "血糖持续上升提醒",
+		},
+		{
+			name:   "rising below threshold",
+			rule:   rising,
+			record: record(7.5, ""),
+			recent: recent(6, 7, 7.5),
+		},
+		{
+			name:      "rising equal to threshold",
+			rule:      rising,
+			record:    record(8, ""),
+			recent:    recent(6, 7, 8),
+			wantTitle: "血糖持续上升提醒",
+		},
+		{
+			name:   "not strictly rising",
+			rule:   rising,
+			record: record(9, ""),
+			recent: recent(6, 6, 9),
+		},
+		{
+			name:   "falling within window",
+			rule:   rising,
+			record: record(9, ""),
+			recent: recent(6, 8, 7, 9),
+		},
+		{
+			name:   "too few readings",
+			rule:   rising,
+			record: record(9, ""),
+			recent: recent(5, 9),
+		},
+		{
+			name:      "rising with custom count",
+			rule:      risingFour,
+			record:    record(9, ""),
+			recent:    recent(5, 6, 7, 9),
+			wantTitle: "血糖持续上升提醒",
+		},
+		{
+			name:   "custom count not reached",
+			rule:   risingFour,
+			record: record(9, ""),
+			recent: recent(8, 6, 7, 9),
+		},
+		{name: "unknown type", rule: model.AlertRule{Type: "unknown", Threshold: 1}, record: record(20, "")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alert := evaluate(tt.rule, &tt.record, tt.recent)
+			if tt.wantTitle == "" {
+				if alert != nil {
+					t.Fatalf("evaluate() = %+v, want nil", alert)
+				}
+				return
+			}
+			if alert == nil {
+				t.Fatalf("evaluate() = nil, want %q", tt.wantTitle)
+			}
+			if alert.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", alert.Title, tt.wantTitle)
+			}
+			if alert.Rule != tt.rule {
+				t.Errorf("Rule = %+v, want %+v", alert.Rule, tt.rule)
+			}
+			if alert.Content == "" {
+				t.Errorf("Content is empty")
+			}
+		})
+	}
+}
+
+func TestDefaultRules(t *testing.T) {
+	bands := glucoseanalytics.NewBands(3.5, 4.4, 8, 12)
+	rules := defaultRules("user@example.com", bands)
+
+	tests := []struct {
+		ruleType      string
+		wantThreshold float32
+	}{
+		{model.AlertRuleTypeBelow, bands.Low},
+		{model.AlertRuleTypeAbove, bands.VeryHigh},
+	}
+	if len(rules) != len(tests) {
+		t.Fatalf("len(rules) = %d, want %d", len(rules), len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.ruleType, func(t *testing.T) {
+			rule := rules[i]
+			if rule.Type != tt.ruleType || rule.Threshold != tt.wantThreshold {
+				t.Errorf("rule = %s/%.1f, want %s/%.1f", rule.Type, rule.Threshold, tt.ruleType, tt.wantThreshold)
+			}
+			if rule.UserEmail != "user@example.com" || !rule.Enabled {
+				t.Errorf("rule = %+v, want enabled rule of user@example.com", rule)
+			}
+			if cooldown(rule) != defaultCooldownMinutes*time.Minute {
+				t.Errorf("cooldown = %v, want %v", cooldown(rule), defaultCooldownMinutes*time.Minute)
+			}
+		})
+	}
+}
+
+func TestConsecutiveCount(t *testing.T) {
+	tests := []struct {
+		name  string
+		count int
+		want  int
+	}{
+		{name: "unset", count: 0, want: defaultConsecutiveCount},
+		{name: "negative", count: -1, want: defaultConsecutiveCount},
+		{name: "custom", count: 5, want: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := consecutiveCount(model.AlertRule{ConsecutiveCount: tt.count}); got != tt.want {
+				t.Errorf("consecutiveCount() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCooldown(t *testing.T) {
+	tests := []struct {
+		minutes int
+		want    time.Duration
+	}{
+		{0, 0},
+		{30, 30 * time.Minute},
+		{120, 2 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want.String(), func(t *testing.T) {
+			if got := cooldown(model.AlertRule{CooldownMinutes: tt.minutes}); got != tt.want {
+				t.Errorf("cooldown() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package glucoseanalytics
+
+import (
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/response"
+	"math"
+	"time"
+)
+
+const (
+	// 国际共识推荐的默认血糖分级阈值(mmol/L)
+	defaultVeryLow  = 3.0
+	defaultLow      = 3.9
+	defaultHigh     = 10.0
+	defaultVeryHigh = 13.9
+
+	// 单条读数代表的最长时长，用于兼容指尖血糖与 CGM 两类数据：
+	// CGM 读数按实际间隔加权，稀疏的指尖血糖读数权重相同
+	maxReadingDuration = 15 * time.Minute
+
+	// 相邻读数间隔超过该值时，视为不同的血糖事件
+	episodeGap = 2 * time.Hour
+
+	// mmol/L 转换为 mg/dL 的系数
+	mmolToMgdl = 18.018
+)
+
+// Bands 血糖分级阈值
+type Bands struct {
+	VeryLow  float32 `json:"very_low"`
+	Low      float32 `json:"low"`
+	High     float32 `json:"high"`
+	VeryHigh float32 `json:"very_high"`
+}
+
+// Analytics 血糖分析指标
+type Analytics struct {
+	Count int `json:"count"`
+
+	// 平均血糖(mmol/L)
+	Mean float64 `json:"mean"`
+
+	// 标准差(mmol/L)
+	SD float64 `json:"sd"`
+
+	// 变异系数(%)，>36% 提示血糖波动较大
+	CV float64 `json:"cv"`
+
+	// 血糖管理指标(%)，由平均血糖估算的 HbA1c
+	GMI float64 `json:"gmi"`
+
+	// 各区间时间占比(%)，五项之和为 100
+	TimeVeryLow  float64 `json:"time_very_low"`
+	TimeLow      float64 `json:"time_low"`
+	TimeInRange  float64 `json:"time_in_range"`
+	TimeHigh     float64 `json:"time_high"`
+	TimeVeryHigh float64 `json:"time_very_high"`
+
+	// 低于/高于目标范围的时间占比(%)
+	TimeBelowRange float64 `json:"time_below_range"`
+	TimeAboveRange float64 `json:"time_above_range"`
+
+	// 低血糖/高血糖事件数，连续的范围外读数计为一次事件
+	HypoEpisodes  int `json:"hypo_episodes"`
+	HyperEpisodes int `json:"hyper_episodes"`
+
+	Bands Bands `json:"bands"`
+}
+
+// GetAnalytics 获取用户指定时间范围内的血糖分析指标，分级阈值取自健康档案
+func GetAnalytics(email string, start, end time.Time) (*Analytics, error) {
+	records, err := dao.GetBloodGlucoseRecords(email, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := dao.GetHealthProfile(email)
+	if err != nil {
+		return nil, err
+	}
+
+	return Analyze(records, BandsFromProfile(profile)), nil
+}
+
+// BandsFromProfile 读取健康档案中的分级阈值，未设置的阈值使用默认值，
+// 合并后的阈值不满足 very_low < low < high < very_high 时全部使用默认值
+func BandsFromProfile(profile *response.GetHealthProfileResponse) Bands {
+	if profile == nil {
+		return defaultBands()
+	}
+
+	bands := NewBands(profile.GlucoseVeryLow, profile.GlucoseLow, profile.GlucoseHigh, profile.GlucoseVeryHigh)
+	if !bands.Valid() {
+		return defaultBands()
+	}
+	return bands
+}
+
+// NewBands 使用给定的分级阈值，不大于 0 的阈值使用默认值
+func NewBands(veryLow, low, high, veryHigh float32) Bands {
+	bands := defaultBands()
+	if veryLow > 0 {
+		bands.VeryLow = veryLow
+	}
+	if low > 0 {
+		bands.Low = low
+	}
+	if high > 0 {
+		bands.High = high
+	}
+	if veryHigh > 0 {
+		bands.VeryHigh = veryHigh
+	}
+	return bands
+}
+
+// Valid 判断分级阈值是否满足 very_low < low < high < very_high
+func (b Bands) Valid() bool {
+	return b.VeryLow < b.Low && b.Low < b.High && b.High < b.VeryHigh
+}
+
+func defaultBands() Bands {
+	return Bands{
+		VeryLow:  defaultVeryLow,
+		Low:      defaultLow,
+		High:     defaultHigh,
+		VeryHigh: defaultVeryHigh,
+	}
+}
+
+// Analyze 计算血糖分析指标，records 需按测量时间升序排列
+func Analyze(records []response.GetBloodGlucoseRecordsResponse, bands Bands) *Analytics {
+	analytics := Analytics{
+		Count: len(records),
+		Bands: bands,
+	}
+	if len(records) == 0 {
+		return &analytics
+	}
+
+	var sum float64
+	for _, r := range records {
+		sum += float64(r.Value)
+	}
+	analytics.Mean = sum / float64(len(records))
+
+	var squaredDiffSum float64
+	for _, r := range records {
+		diff := float64(r.Value) - analytics.Mean
+		squaredDiffSum += diff * diff
+	}
+	analytics.SD = math.Sqrt(squaredDiffSum / float64(len(records)))
+	analytics.CV = analytics.SD / analytics.Mean * 100
+
+	// GMI(%) = 3.31 + 0.02392 × 平均血糖(mg/dL)
+	analytics.GMI = 3.31 + 0.02392*analytics.Mean*mmolToMgdl
+
+	calcTimeInRanges(&analytics, records, bands)
+	analytics.HypoEpisodes = countEpisodes(records, func(v float32) bool { return v < bands.Low })
+	analytics.HyperEpisodes = countEpisodes(records, func(v float32) bool { return v > bands.High })
+
+	return &analytics
+}
+
+// 按读数代表的时长加权计算各区间的时间占比
+func calcTimeInRanges(analytics *Analytics, records []response.GetBloodGlucoseRecordsResponse, bands Bands) {
+	var veryLow, low, inRange, high, veryHigh, total time.Duration
+	for i, r := range records {
+		duration := maxReadingDuration
+		if i+1 < len(records) {
+			duration = min(records[i+1].MeasuredAt.Sub(r.MeasuredAt), maxReadingDuration)
+		}
+		// 同一时刻的重复读数至少计 1 分钟，避免权重为 0
+		duration = max(duration, time.Minute)
+
+		switch {
+		case r.Value < bands.VeryLow:
+			veryLow += duration
+		case r.Value < bands.Low:
+			low += duration
+		case r.Value <= bands.High:
+			inRange += duration
+		case r.Value <= bands.VeryHigh:
+			high += duration
+		default:
+			veryHigh += duration
+		}
+		total += duration
+	}
+
+	percent := func(d time.Duration) float64 {
+		return float64(d) / float64(total) * 100
+	}
+	analytics.TimeVeryLow = percent(veryLow)
+	analytics.TimeLow = percent(low)
+	analytics.TimeInRange = percent(inRange)
+	analytics.TimeHigh = percent(high)
+	analytics.TimeVeryHigh = percent(veryHigh)
+	analytics.TimeBelowRange = analytics.TimeVeryLow + analytics.TimeLow
+	analytics.TimeAboveRange = analytics.TimeHigh + analytics.TimeVeryHigh
+}
+
+// 统计满足条件的连续读数段数，读数间隔超过 episodeGap 时视为新的事件
+func countEpisodes(records []response.GetBloodGlucoseRecordsResponse, outOfRange func(float32) bool) int {
+	episodes := 0
+	inEpisode := false
+	for i, r := range records {
+		if !outOfRange(r.Value) {
+			inEpisode = false
+			continue
+		}
+
+		if inEpisode && r.MeasuredAt.Sub(records[i-1].MeasuredAt) > episodeGap {
+			inEpisode = false
+		}
+		if !inEpisode {
+			episodes++
+			inEpisode = true
+		}
+	}
+	return episodes
+}
@@ -0,0 +1,230 @@
+package glucoseanalytics
+
+import (
+	"diabetes-agent-server/response"
+	"math"
+	"testing"
+	"time"
+)
+
+var testStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// 按给定间隔(分钟)生成升序排列的读数
+func readings(interval int, values ...float32) []response.GetBloodGlucoseRecordsResponse {
+	records := make([]response.GetBloodGlucoseRecordsResponse, len(values))
+	for i, v := range values {
+		records[i] = response.GetBloodGlucoseRecordsResponse{
+			Value:      v,
+			MeasuredAt: testStart.Add(time.Duration(i*interval) * time.Minute),
+		}
+	}
+	return records
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []response.GetBloodGlucoseRecordsResponse
+		want    Analytics
+	}{
+		{
+			name:    "no records",
+			records: nil,
+			want:    Analytics{},
+		},
+		{
+			name:    "stable",
+			records: readings(5, 7, 7, 7),
+			want: Analytics{
+				Count: 3,
+				Mean:  7,
+				GMI:   3.31 + 0.02392*7*18.018,
+			},
+		},
+		{
+			name:    "variable",
+			records: readings(5, 4, 6, 8),
+			want: Analytics{
+				Count: 3,
+				Mean:  6,
+				SD:    math.Sqrt(8.0 / 3),
+				CV:    math.Sqrt(8.0/3) / 6 * 100,
+				GMI:   3.31 + 0.02392*6*18.018,
+			},
+		},
+		{
+			name:    "single reading",
+			records: readings(5, 8.6),
+			want: Analytics{
+				Count: 1,
+				Mean:  8.6,
+				GMI:   3.31 + 0.02392*8.6*18.018,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Analyze(tt.records, defaultBands())
+			if got.Count != tt.want.Count {
+				t.Errorf("Count = %d, want %d", got.Count, tt.want.Count)
+			}
+			if got.Bands != defaultBands() {
+				t.Errorf("Bands = %+v, want %+v", got.Bands, defaultBands())
+			}
+			assertFloat(t, "Mean", got.Mean, tt.want.Mean)
+			assertFloat(t, "SD", got.SD, tt.want.SD)
+			assertFloat(t, "CV", got.CV, tt.want.CV)
+			assertFloat(t, "GMI", got.GMI, tt.want.GMI)
+		})
+	}
+}
+
+func TestAnalyzeTimeInRanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []response.GetBloodGlucoseRecordsResponse
+
+		// 依次为 very_low、low、in_range、high、very_high 的时间占比(%)
+		want [5]float64
+	}{
+		{
+			name:    "all bands",
+			records: readings(5, 2.5, 3.5, 7, 12, 15),
+			// 最后一条读数计 15 分钟
+			want: [5]float64{5.0 / 35 * 100, 5.0 / 35 * 100, 5.0 / 35 * 100, 5.0 / 35 * 100, 15.0 / 35 * 100},
+		},
+		{
+			name:    "band boundaries",
+			records: readings(15, defaultVeryLow, defaultLow, defaultHigh, defaultVeryHigh),
+			want:    [5]float64{0, 25, 50, 25, 0},
+		},
+		{
+			name:    "sparse readings are capped at 15 minutes",
+			records: readings(240, 3.5, 7, 7, 7),
+			want:    [5]float64{0, 25, 75, 0, 0},
+		},
+		{
+			name:    "duplicate readings count at least 1 minute",
+			records: readings(0, 3.5, 7),
+			want:    [5]float64{0, 1.0 / 16 * 100, 15.0 / 16 * 100, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Analyze(tt.records, defaultBands())
+			assertFloat(t, "TimeVeryLow", got.TimeVeryLow, tt.want[0])
+			assertFloat(t, "TimeLow", got.TimeLow, tt.want[1])
+			assertFloat(t, "TimeInRange", got.TimeInRange, tt.want[2])
+			assertFloat(t, "TimeHigh", got.TimeHigh, tt.want[3])
+			assertFloat(t, "TimeVeryHigh", got.TimeVeryHigh, tt.want[4])
+			assertFloat(t, "TimeBelowRange", got.TimeBelowRange, tt.want[0]+tt.want[1])
+			assertFloat(t, "TimeAboveRange", got.TimeAboveRange, tt.want[3]+tt.want[4])
+		})
+	}
+}
+
+func TestAnalyzeEpisodes(t *testing.T) {
+	tests := []struct {
+		name      string
+		records   []response.GetBloodGlucoseRecordsResponse
+		wantHypo  int
+		wantHyper int
+	}{
+		{
+			name:    "in range",
+			records: readings(5, 5, 6, 7),
+		},
+		{
+			name:      "consecutive out-of-range readings are one episode",
+			records:   readings(5, 3.5, 3.2, 2.8, 7, 3.5, 11, 12),
+			wantHypo:  2,
+			wantHyper: 1,
+		},
+		{
+			name:      "gap longer than 2 hours starts a new episode",
+			records:   readings(150, 3.5, 3.5, 11, 11),
+			wantHypo:  2,
+			wantHyper: 2,
+		},
+		{
+			name:      "gap of exactly 2 hours continues the episode",
+			records:   readings(120, 3.5, 3.5, 11, 11),
+			wantHypo:  1,
+			wantHyper: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Analyze(tt.records, defaultBands())
+			if got.HypoEpisodes != tt.wantHypo {
+				t.Errorf("HypoEpisodes = %d, want %d", got.HypoEpisodes, tt.wantHypo)
+			}
+			if got.HyperEpisodes != tt.wantHyper {
+				t.Errorf("HyperEpisodes = %d, want %d", got.HyperEpisodes, tt.wantHyper)
+			}
+		})
+	}
+}
+
+func TestBandsFromProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile *response.GetHealthProfileResponse
+		want    Bands
+	}{
+		{
+			name:    "no profile",
+			profile: nil,
+			want:    defaultBands(),
+		},
+		{
+			name:    "unset bands",
+			profile: &response.GetHealthProfileResponse{},
+			want:    defaultBands(),
+		},
+		{
+			name: "custom bands",
+			profile: &response.GetHealthProfileResponse{
+				GlucoseVeryLow:  3.5,
+				GlucoseLow:      4.4,
+				GlucoseHigh:     7.8,
+				GlucoseVeryHigh: 11.1,
+			},
+			want: Bands{VeryLow: 3.5, Low: 4.4, High: 7.8, VeryHigh: 11.1},
+		},
+		{
+			name:    "partially set bands",
+			profile: &response.GetHealthProfileResponse{GlucoseHigh: 8.5},
+			want:    Bands{VeryLow: defaultVeryLow, Low: defaultLow, High: 8.5, VeryHigh: defaultVeryHigh},
+		},
+		{
+			name:    "merged bands out of order",
+			profile: &response.GetHealthProfileResponse{GlucoseHigh: 15},
+			want:    defaultBands(),
+		},
+		{
+			name: "equal bands",
+			profile: &response.GetHealthProfileResponse{
+				GlucoseVeryLow:  3.9,
+				GlucoseLow:      3.9,
+				GlucoseHigh:     10,
+				GlucoseVeryHigh: 13.9,
+			},
+			want: defaultBands(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BandsFromProfile(tt.profile); got != tt.want {
+				t.Errorf("BandsFromProfile() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func assertFloat(t *testing.T, field string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-4 {
+		t.Errorf("%s = %v, want %v", field, got, want)
+	}
+}
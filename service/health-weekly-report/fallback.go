@@ -0,0 +1,99 @@
+package healthweeklyreport
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// 目标范围内时间(TIR)的推荐目标(%)
+	targetTimeInRange = 70
+
+	// 低于目标范围时间(TBR)的上限(%)
+	maxTimeBelowRange = 4
+
+	// 变异系数(CV)的上限(%)，超过提示血糖波动较大
+	maxCV = 36
+
+	// 用药依从率的推荐下限
+	minAdherenceRate = 0.8
+
+	// 每周推荐的中等强度运动时长(分钟)
+	recommendedWeeklyExerciseMinutes = 150
+)
+
+// 兜底推荐食谱，LLM 输出无法通过校验时使用
+var defaultRecommendedMeals = []RecommendedMeal{
+	{Name: "燕麦粥", Description: "燕麦富含可溶性膳食纤维，升糖指数较低，有助于延缓餐后血糖上升，建议选择未加糖的燕麦片。"},
+	{Name: "清蒸鱼", Description: "鱼肉提供优质蛋白且脂肪含量较低，清蒸的烹饪方式少油少盐，适合作为正餐的蛋白质来源。"},
+	{Name: "凉拌菠菜", Description: "绿叶蔬菜热量低、膳食纤维丰富，先吃蔬菜再吃主食有助于平稳餐后血糖。"},
+}
+
+// 基于统计指标生成确定性的健康分析，LLM 输出多次修复仍未通过校验时作为兜底
+func ruleBasedAnalysis(data *UserHealthData) *HealthAnalysis {
+	return &HealthAnalysis{
+		BloodGlucoseAnalysis: ruleBasedGlucoseAnalysis(data),
+		ExerciseAnalysis:     ruleBasedExerciseAnalysis(data),
+		MealAnalysis:         ruleBasedMealAnalysis(data),
+		RecommendedMeals:     defaultRecommendedMeals,
+		Conclusion:           "本期报告根据您记录的数据自动生成。请继续坚持记录血糖、运动和饮食，如有血糖持续异常请及时咨询医生。",
+	}
+}
+
+func ruleBasedGlucoseAnalysis(data *UserHealthData) string {
+	stats := data.BloodGlucoseStats
+	if stats == nil || stats.Count == 0 {
+		return "本期暂无血糖记录。"
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("本期共记录血糖 %d 次，平均 %.1f mmol/L，最高 %.1f mmol/L，最低 %.1f mmol/L。",
+		stats.Count, stats.Avg, stats.Max, stats.Min))
+
+	if a := data.GlucoseAnalytics; a != nil && a.Count > 0 {
+		if a.TimeInRange >= targetTimeInRange {
+			b.WriteString(fmt.Sprintf("目标范围内时间为 %.0f%%，达到 %d%% 的推荐目标。", a.TimeInRange, targetTimeInRange))
+		} else {
+			b.WriteString(fmt.Sprintf("目标范围内时间为 %.0f%%，低于 %d%% 的推荐目标。", a.TimeInRange, targetTimeInRange))
+		}
+		if a.TimeBelowRange > maxTimeBelowRange {
+			b.WriteString(fmt.Sprintf("低于目标范围时间为 %.0f%%，超过 %d%% 的上限，请注意预防低血糖。", a.TimeBelowRange, maxTimeBelowRange))
+		}
+		if a.CV > maxCV {
+			b.WriteString(fmt.Sprintf("血糖变异系数为 %.1f%%，血糖波动较大。", a.CV))
+		}
+		if a.HypoEpisodes > 0 {
+			b.WriteString(fmt.Sprintf("共发生低血糖事件 %d 次。", a.HypoEpisodes))
+		}
+	}
+
+	if m := data.MedicationAdherence; m != nil && m.ExpectedDoses > 0 && m.AdherenceRate < minAdherenceRate {
+		b.WriteString(fmt.Sprintf("用药依从率为 %.0f%%，漏服可能影响血糖控制，建议按时用药。", m.AdherenceRate*100))
+	}
+
+	return b.String()
+}
+
+func ruleBasedExerciseAnalysis(data *UserHealthData) string {
+	stats := data.ExerciseStats
+	if stats == nil || stats.Count == 0 {
+		return fmt.Sprintf("本期暂无运动记录，建议每周进行至少 %d 分钟中等强度运动。", recommendedWeeklyExerciseMinutes)
+	}
+
+	return fmt.Sprintf("本期共运动 %d 次，累计 %d 分钟，平均每次 %.0f 分钟。建议每周进行至少 %d 分钟中等强度运动。",
+		stats.Count, stats.TotalMinutes, stats.AverageMinutes, recommendedWeeklyExerciseMinutes)
+}
+
+func ruleBasedMealAnalysis(data *UserHealthData) string {
+	stats := data.MealStats
+	if stats == nil || stats.Count == 0 {
+		return "本期暂无饮食记录。"
+	}
+
+	analysis := fmt.Sprintf("本期共记录进餐 %d 次，碳水摄入总量 %.0f g。", stats.Count, stats.TotalCarbohydrate)
+	if stats.SpikeCount > 0 {
+		analysis += fmt.Sprintf("其中 %d 次进餐后血糖明显升高，平均升幅 %.1f mmol/L，建议减少精制碳水并控制主食份量。",
+			stats.SpikeCount, stats.AvgGlucoseRise)
+	}
+	return analysis
+}
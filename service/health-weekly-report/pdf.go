@@ -0,0 +1,504 @@
+package healthweeklyreport
+
+import (
+	"bytes"
+	"diabetes-agent-server/config"
+	"diabetes-agent-server/response"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	pdfFontFamily = "report"
+
+	// A4 纵向页面边距及内容宽度(mm)
+	pdfMargin       = 15.0
+	pdfContentWidth = 210.0 - 2*pdfMargin
+
+	pdfChartHeight = 60.0
+	pdfLineHeight  = 6.0
+)
+
+// 血糖区间配色，与 HTML 报告一致
+var (
+	colorPrimary   = [3]int{102, 126, 234}
+	colorTargetBg  = [3]int{220, 252, 231}
+	colorVeryLow   = [3]int{153, 27, 27}
+	colorLow       = [3]int{239, 68, 68}
+	colorInRange   = [3]int{34, 197, 94}
+	colorHigh      = [3]int{245, 158, 11}
+	colorVeryHigh  = [3]int{180, 83, 9}
+	colorTableHead = [3]int{243, 244, 246}
+	colorText      = [3]int{55, 65, 81}
+)
+
+// dailyGlucose 按日汇总的血糖数据，避免 CGM 数据导致表格过长
+type dailyGlucose struct {
+	Date  string
+	Count int
+	Min   float32
+	Max   float32
+	Avg   float32
+}
+
+// 渲染健康报告的 PDF 版本，纯 Go 实现，不依赖浏览器。
+// 中文需要 TTF 字体，字体路径通过配置 report.pdf_font_path 指定
+func renderPDF(data *ReportData) ([]byte, error) {
+	font, err := os.ReadFile(config.Cfg.Report.PDFFontPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pdf font: %v", err)
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	pdf.AddUTF8FontFromBytes(pdfFontFamily, "", font)
+	pdf.AddUTF8FontFromBytes(pdfFontFamily, "B", font)
+	if err := pdf.Error(); err != nil {
+		return nil, fmt.Errorf("failed to load pdf font: %v", err)
+	}
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-10)
+		pdf.SetFont(pdfFontFamily, "", 8)
+		pdf.SetTextColor(156, 163, 175)
+		pdf.CellFormat(0, 5, fmt.Sprintf("%d", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	writePDFHeader(pdf, data)
+	if data.InsufficientData {
+		writePDFAnalysis(pdf, "温馨提示", "本期记录的数据较少，以下为简要报告。坚持记录血糖、运动和饮食，下期报告将为您提供更完整的健康分析。")
+	}
+	writePDFGlucoseSection(pdf, data)
+	writePDFExerciseSection(pdf, data)
+	writePDFMealSection(pdf, data)
+	writePDFVitalSection(pdf, data)
+	writePDFConclusion(pdf, data)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writePDFHeader(pdf *fpdf.Fpdf, data *ReportData) {
+	pdf.SetFillColor(colorPrimary[0], colorPrimary[1], colorPrimary[2])
+	pdf.Rect(pdfMargin, pdfMargin, pdfContentWidth, 24, "F")
+
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetFont(pdfFontFamily, "B", 20)
+	pdf.SetXY(pdfMargin, pdfMargin+3)
+	pdf.CellFormat(pdfContentWidth, 10, data.ReportTitle, "", 2, "C", false, 0, "")
+	pdf.SetFont(pdfFontFamily, "", 11)
+	pdf.CellFormat(pdfContentWidth, 8, data.ReportPeriod, "", 1, "C", false, 0, "")
+	pdf.Ln(8)
+}
+
+func writePDFGlucoseSection(pdf *fpdf.Fpdf, data *ReportData) {
+	writePDFSectionTitle(pdf, "血糖数据分析")
+
+	stats := data.BloodGlucoseStats
+	writePDFStats(pdf, [][2]string{
+		{"平均血糖值 (mmol/L)", fmt.Sprintf("%.1f", stats.Avg)},
+		{"最高血糖值 (mmol/L)", fmt.Sprintf("%.1f", stats.Max)},
+		{"最低血糖值 (mmol/L)", fmt.Sprintf("%.1f", stats.Min)},
+		{"测量次数", fmt.Sprintf("%d", stats.Count)},
+	})
+
+	analytics := data.GlucoseAnalytics
+	writePDFStats(pdf, [][2]string{
+		{fmt.Sprintf("目标范围内时间 (%.1f~%.1f)", analytics.Bands.Low, analytics.Bands.High), fmt.Sprintf("%.0f%%", analytics.TimeInRange)},
+		{"血糖管理指标 (GMI)", fmt.Sprintf("%.1f%%", analytics.GMI)},
+		{"血糖变异系数 (CV)", fmt.Sprintf("%.1f%%", analytics.CV)},
+		{"低血糖 / 高血糖事件数", fmt.Sprintf("%d / %d", analytics.HypoEpisodes, analytics.HyperEpisodes)},
+	})
+
+	if len(data.BloodGlucoseRecords) > 0 {
+		writePDFSubtitle(pdf, "血糖趋势")
+		writePDFGlucoseChart(pdf, data)
+
+		writePDFSubtitle(pdf, "目标范围内时间分布")
+		writePDFTimeInRangeBar(pdf, data)
+
+		writePDFSubtitle(pdf, "每日血糖汇总")
+		rows := make([][]string, 0)
+		for _, d := range summarizeDailyGlucose(data.BloodGlucoseRecords) {
+			rows = append(rows, []string{
+				d.Date,
+				fmt.Sprintf("%d", d.Count),
+				fmt.Sprintf("%.1f", d.Min),
+				fmt.Sprintf("%.1f", d.Max),
+				fmt.Sprintf("%.1f", d.Avg),
+			})
+		}
+		writePDFTable(pdf,
+			[]string{"日期", "测量次数", "最低 (mmol/L)", "最高 (mmol/L)", "平均 (mmol/L)"},
+			[]float64{40, 30, 35, 35, 40},
+			rows,
+		)
+	}
+
+	if data.MedicationAdherence != nil && len(data.MedicationAdherence.Plans) > 0 {
+		writePDFSubtitle(pdf, "用药依从性")
+		rows := make([][]string, 0, len(data.MedicationAdherence.Plans))
+		for _, p := range data.MedicationAdherence.Plans {
+			rows = append(rows, []string{
+				p.DrugName,
+				fmt.Sprintf("%g %s", p.Dose, p.DoseUnit),
+				fmt.Sprintf("%d", p.ExpectedDoses),
+				fmt.Sprintf("%d", p.TakenDoses),
+				fmt.Sprintf("%.0f%%", p.AdherenceRate*100),
+			})
+		}
+		writePDFTable(pdf,
+			[]string{"药品", "剂量", "应服次数", "实服次数", "依从率"},
+			[]float64{50, 35, 30, 30, 35},
+			rows,
+		)
+	}
+
+	writePDFAnalysis(pdf, "数据分析", data.HealthAnalysis.BloodGlucoseAnalysis)
+}
+
+// 绘制血糖折线图，浅绿色区域为目标范围
+func writePDFGlucoseChart(pdf *fpdf.Fpdf, data *ReportData) {
+	records := data.BloodGlucoseRecords
+	bands := data.GlucoseAnalytics.Bands
+
+	const axisWidth = 10.0
+	left := pdfMargin + axisWidth
+	width := pdfContentWidth - axisWidth
+	ensurePDFSpace(pdf, pdfChartHeight+10)
+	top := pdf.GetY()
+
+	// 纵轴范围覆盖目标范围及所有读数
+	minY, maxY := float64(bands.VeryLow)-1, float64(bands.VeryHigh)+1
+	for _, r := range records {
+		minY = min(minY, float64(r.Value)-1)
+		maxY = max(maxY, float64(r.Value)+1)
+	}
+	minY = max(minY, 0)
+
+	startAt := records[0].MeasuredAt
+	span := records[len(records)-1].MeasuredAt.Sub(startAt).Seconds()
+	x := func(t time.Time) float64 {
+		if span == 0 {
+			return left + width/2
+		}
+		return left + t.Sub(startAt).Seconds()/span*width
+	}
+	y := func(v float64) float64 {
+		return top + pdfChartHeight - (v-minY)/(maxY-minY)*pdfChartHeight
+	}
+
+	pdf.SetFillColor(colorTargetBg[0], colorTargetBg[1], colorTargetBg[2])
+	pdf.Rect(left, y(float64(bands.High)), width, y(float64(bands.Low))-y(float64(bands.High)), "F")
+
+	pdf.SetDrawColor(209, 213, 219)
+	pdf.SetLineWidth(0.2)
+	pdf.Rect(left, top, width, pdfChartHeight, "D")
+
+	pdf.SetFont(pdfFontFamily, "", 7)
+	pdf.SetTextColor(colorText[0], colorText[1], colorText[2])
+	for _, v := range []float32{bands.Low, bands.High} {
+		pdf.SetXY(pdfMargin, y(float64(v))-2)
+		pdf.CellFormat(axisWidth-1, 4, fmt.Sprintf("%.1f", v), "", 0, "R", false, 0, "")
+	}
+	pdf.SetXY(left, top+pdfChartHeight+1)
+	pdf.CellFormat(width/2, 4, startAt.Format("01-02"), "", 0, "L", false, 0, "")
+	pdf.CellFormat(width/2, 4, records[len(records)-1].MeasuredAt.Format("01-02"), "", 0, "R", false, 0, "")
+
+	pdf.SetDrawColor(colorPrimary[0], colorPrimary[1], colorPrimary[2])
+	pdf.SetLineWidth(0.3)
+	for i := 1; i < len(records); i++ {
+		pdf.Line(
+			x(records[i-1].MeasuredAt), y(float64(records[i-1].Value)),
+			x(records[i].MeasuredAt), y(float64(records[i].Value)),
+		)
+	}
+	// 读数较少时标出数据点
+	if len(records) <= 100 {
+		for _, r := range records {
+			c := glucoseColor(r.Value, bands.Low, bands.High)
+			pdf.SetFillColor(c[0], c[1], c[2])
+			pdf.Circle(x(r.MeasuredAt), y(float64(r.Value)), 0.8, "F")
+		}
+	}
+
+	pdf.SetLineWidth(0.2)
+	pdf.SetY(top + pdfChartHeight + 8)
+}
+
+// 绘制各血糖区间时间占比的堆叠条形图
+func writePDFTimeInRangeBar(pdf *fpdf.Fpdf, data *ReportData) {
+	analytics := data.GlucoseAnalytics
+	segments := []struct {
+		label   string
+		percent float64
+		color   [3]int
+	}{
+		{"极低", analytics.TimeVeryLow, colorVeryLow},
+		{"偏低", analytics.TimeLow, colorLow},
+		{"目标范围", analytics.TimeInRange, colorInRange},
+		{"偏高", analytics.TimeHigh, colorHigh},
+		{"极高", analytics.TimeVeryHigh, colorVeryHigh},
+	}
+
+	ensurePDFSpace(pdf, 20)
+	top := pdf.GetY()
+	x := pdfMargin
+	for _, s := range segments {
+		w := s.percent / 100 * pdfContentWidth
+		if w <= 0 {
+			continue
+		}
+		pdf.SetFillColor(s.color[0], s.color[1], s.color[2])
+		pdf.Rect(x, top, w, 8, "F")
+		x += w
+	}
+
+	pdf.SetY(top + 10)
+	pdf.SetFont(pdfFontFamily, "", 8)
+	for _, s := range segments {
+		pdf.SetFillColor(s.color[0], s.color[1], s.color[2])
+		pdf.Rect(pdf.GetX(), pdf.GetY()+1, 3, 3, "F")
+		pdf.SetX(pdf.GetX() + 4)
+		pdf.CellFormat(pdfContentWidth/5-4, 5, fmt.Sprintf("%s %.0f%%", s.label, s.percent), "", 0, "L", false, 0, "")
+	}
+	pdf.Ln(9)
+}
+
+func writePDFExerciseSection(pdf *fpdf.Fpdf, data *ReportData) {
+	writePDFSectionTitle(pdf, "运动数据分析")
+
+	stats := data.ExerciseStats
+	writePDFStats(pdf, [][2]string{
+		{"总运动时长 (分钟)", fmt.Sprintf("%d", stats.TotalMinutes)},
+		{"运动次数", fmt.Sprintf("%d", stats.Count)},
+		{"平均每次时长 (分钟)", fmt.Sprintf("%.0f", stats.AverageMinutes)},
+	})
+
+	if len(data.ExerciseRecords) > 0 {
+		writePDFSubtitle(pdf, "运动记录详情")
+		rows := make([][]string, 0, len(data.ExerciseRecords))
+		for _, r := range data.ExerciseRecords {
+			rows = append(rows, []string{
+				r.StartAt.Format("01-02 15:04"),
+				r.Type,
+				r.Name,
+				r.Intensity,
+				fmt.Sprintf("%d", r.Duration),
+			})
+		}
+		writePDFTable(pdf,
+			[]string{"开始时间", "类型", "名称", "强度", "时长 (分钟)"},
+			[]float64{35, 30, 55, 30, 30},
+			rows,
+		)
+	}
+
+	writePDFAnalysis(pdf, "运动总结", data.HealthAnalysis.ExerciseAnalysis)
+}
+
+func writePDFMealSection(pdf *fpdf.Fpdf, data *ReportData) {
+	writePDFSectionTitle(pdf, "饮食数据分析")
+
+	stats := data.MealStats
+	writePDFStats(pdf, [][2]string{
+		{"进餐记录次数", fmt.Sprintf("%d", stats.Count)},
+		{"碳水摄入总量 (g)", fmt.Sprintf("%.0f", stats.TotalCarbohydrate)},
+		{"平均餐后血糖升幅 (mmol/L)", fmt.Sprintf("%.1f", stats.AvgGlucoseRise)},
+		{"餐后血糖飙升次数", fmt.Sprintf("%d", stats.SpikeCount)},
+	})
+
+	writePDFAnalysis(pdf, "饮食分析", data.HealthAnalysis.MealAnalysis)
+
+	if len(data.HealthAnalysis.RecommendedMeals) > 0 {
+		writePDFSubtitle(pdf, "推荐食谱")
+		for _, meal := range data.HealthAnalysis.RecommendedMeals {
+			pdf.SetFont(pdfFontFamily, "B", 10)
+			pdf.MultiCell(pdfContentWidth, pdfLineHeight, meal.Name, "", "L", false)
+			pdf.SetFont(pdfFontFamily, "", 10)
+			pdf.MultiCell(pdfContentWidth, pdfLineHeight, meal.Description, "", "L", false)
+			pdf.Ln(1)
+		}
+		pdf.Ln(3)
+	}
+}
+
+func writePDFVitalSection(pdf *fpdf.Fpdf, data *ReportData) {
+	stats := data.VitalStats
+	if stats == nil || stats.Count == 0 {
+		return
+	}
+
+	writePDFSectionTitle(pdf, "体征数据")
+
+	items := [][2]string{
+		{"最近体重 (kg)", formatOptional(stats.LatestWeight, "%.1f")},
+		{"体重变化 (kg)", formatOptional(stats.WeightChange, "%+.1f")},
+		{"BMI", formatOptional(stats.LatestBMI, "%.1f")},
+	}
+	bloodPressure := "-"
+	if stats.AvgSystolic != nil && stats.AvgDiastolic != nil {
+		bloodPressure = fmt.Sprintf("%.0f/%.0f", *stats.AvgSystolic, *stats.AvgDiastolic)
+	}
+	items = append(items,
+		[2]string{"平均血压 (mmHg)", bloodPressure},
+		[2]string{"血压偏高次数", fmt.Sprintf("%d", stats.HighBloodPressureCount)},
+		[2]string{"平均心率 (次/分)", formatOptional(stats.AvgHeartRate, "%.0f")},
+	)
+	writePDFStats(pdf, items[:3])
+	writePDFStats(pdf, items[3:])
+}
+
+func writePDFConclusion(pdf *fpdf.Fpdf, data *ReportData) {
+	writePDFSectionTitle(pdf, "总结与建议")
+	pdf.SetFont(pdfFontFamily, "", 10)
+	pdf.SetTextColor(colorText[0], colorText[1], colorText[2])
+	pdf.MultiCell(pdfContentWidth, pdfLineHeight, data.HealthAnalysis.Conclusion, "", "L", false)
+}
+
+func writePDFSectionTitle(pdf *fpdf.Fpdf, title string) {
+	ensurePDFSpace(pdf, 30)
+	pdf.SetFont(pdfFontFamily, "B", 14)
+	pdf.SetTextColor(colorPrimary[0], colorPrimary[1], colorPrimary[2])
+	pdf.CellFormat(pdfContentWidth, 10, title, "B", 1, "L", false, 0, "")
+	pdf.Ln(3)
+}
+
+func writePDFSubtitle(pdf *fpdf.Fpdf, title string) {
+	ensurePDFSpace(pdf, 15)
+	pdf.SetFont(pdfFontFamily, "B", 11)
+	pdf.SetTextColor(75, 85, 99)
+	pdf.CellFormat(pdfContentWidth, 8, title, "", 1, "L", false, 0, "")
+}
+
+// 以卡片形式横向展示统计指标
+func writePDFStats(pdf *fpdf.Fpdf, items [][2]string) {
+	ensurePDFSpace(pdf, 20)
+	w := pdfContentWidth / float64(len(items))
+	top := pdf.GetY()
+
+	for i, item := range items {
+		left := pdfMargin + float64(i)*w
+		pdf.SetFillColor(colorTableHead[0], colorTableHead[1], colorTableHead[2])
+		pdf.Rect(left+1, top, w-2, 16, "F")
+
+		pdf.SetXY(left+1, top+2)
+		pdf.SetFont(pdfFontFamily, "B", 12)
+		pdf.SetTextColor(colorPrimary[0], colorPrimary[1], colorPrimary[2])
+		pdf.CellFormat(w-2, 6, item[1], "", 2, "C", false, 0, "")
+		pdf.SetFont(pdfFontFamily, "", 7)
+		pdf.SetTextColor(107, 114, 128)
+		pdf.CellFormat(w-2, 5, item[0], "", 0, "C", false, 0, "")
+	}
+	pdf.SetXY(pdfMargin, top+20)
+}
+
+func writePDFTable(pdf *fpdf.Fpdf, header []string, widths []float64, rows [][]string) {
+	writeHeader := func() {
+		pdf.SetFont(pdfFontFamily, "B", 9)
+		pdf.SetTextColor(colorText[0], colorText[1], colorText[2])
+		pdf.SetFillColor(colorTableHead[0], colorTableHead[1], colorTableHead[2])
+		pdf.SetDrawColor(229, 231, 235)
+		for i, h := range header {
+			pdf.CellFormat(widths[i], 7, h, "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont(pdfFontFamily, "", 9)
+	}
+
+	writeHeader()
+	_, pageHeight := pdf.GetPageSize()
+	for _, row := range rows {
+		// 换页时重复表头
+		if pdf.GetY()+7 > pageHeight-pdfMargin {
+			pdf.AddPage()
+			writeHeader()
+		}
+		for i, v := range row {
+			pdf.CellFormat(widths[i], 7, truncatePDFText(pdf, v, widths[i]-2), "1", 0, "C", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	pdf.Ln(4)
+}
+
+// 展示 LLM 生成的分析内容
+func writePDFAnalysis(pdf *fpdf.Fpdf, title, content string) {
+	if strings.TrimSpace(content) == "" {
+		return
+	}
+
+	ensurePDFSpace(pdf, 20)
+	pdf.SetFont(pdfFontFamily, "B", 10)
+	pdf.SetTextColor(colorText[0], colorText[1], colorText[2])
+	pdf.CellFormat(pdfContentWidth, pdfLineHeight, title+"：", "", 1, "L", false, 0, "")
+	pdf.SetFont(pdfFontFamily, "", 10)
+	pdf.MultiCell(pdfContentWidth, pdfLineHeight, content, "", "L", false)
+	pdf.Ln(4)
+}
+
+// 剩余空间不足时换页，避免标题与内容分离
+func ensurePDFSpace(pdf *fpdf.Fpdf, height float64) {
+	_, pageHeight := pdf.GetPageSize()
+	if pdf.GetY()+height > pageHeight-pdfMargin {
+		pdf.AddPage()
+	}
+}
+
+func truncatePDFText(pdf *fpdf.Fpdf, s string, width float64) string {
+	if pdf.GetStringWidth(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"…") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+func summarizeDailyGlucose(records []response.GetBloodGlucoseRecordsResponse) []dailyGlucose {
+	var days []dailyGlucose
+	var sum float32
+	for _, r := range records {
+		date := r.MeasuredAt.Format("2006-01-02")
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, dailyGlucose{Date: date, Min: r.Value, Max: r.Value})
+			sum = 0
+		}
+
+		d := &days[len(days)-1]
+		d.Count++
+		d.Min = min(d.Min, r.Value)
+		d.Max = max(d.Max, r.Value)
+		sum += r.Value
+		d.Avg = sum / float32(d.Count)
+	}
+	return days
+}
+
+func glucoseColor(value, low, high float32) [3]int {
+	switch {
+	case value < low:
+		return colorLow
+	case value > high:
+		return colorHigh
+	default:
+		return colorInRange
+	}
+}
+
+func formatOptional(v *float32, format string) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf(format, *v)
+}
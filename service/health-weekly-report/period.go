@@ -0,0 +1,70 @@
+package healthweeklyreport
+
+import (
+	"diabetes-agent-server/model"
+	"fmt"
+	"time"
+)
+
+// 各周期报告的标题
+var reportTitles = map[string]string{
+	model.ReportPeriodWeekly:    "健康周报",
+	model.ReportPeriodMonthly:   "健康月报",
+	model.ReportPeriodQuarterly: "健康季报",
+	model.ReportPeriodCustom:    "健康报告",
+}
+
+func reportTitle(periodType string) string {
+	if title, ok := reportTitles[periodType]; ok {
+		return title
+	}
+	return reportTitles[model.ReportPeriodCustom]
+}
+
+// PeriodRange 返回 date 所在的自然周/月/季度，用于重新生成指定周期的定时报告
+func PeriodRange(periodType string, date time.Time) (time.Time, time.Time, error) {
+	date = date.UTC()
+	firstOfMonth := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	switch periodType {
+	case model.ReportPeriodWeekly:
+		start, end := lastWeek(date.AddDate(0, 0, 7))
+		return start, end, nil
+	case model.ReportPeriodMonthly:
+		start, end := lastMonth(firstOfMonth.AddDate(0, 1, 0))
+		return start, end, nil
+	case model.ReportPeriodQuarterly:
+		start, end := lastQuarter(firstOfMonth.AddDate(0, 3, 0))
+		return start, end, nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid period type: %s", periodType)
+	}
+}
+
+// lastWeek 返回 now 所在周的上一个自然周(周一至周日)
+func lastWeek(now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	// 周日的 Weekday 为 0，按一周的第 7 天计算
+	weekday := (int(now.Weekday())+6)%7 + 1
+	thisMonday := time.Date(now.Year(), now.Month(), now.Day()-weekday+1, 0, 0, 0, 0, time.UTC)
+	return thisMonday.AddDate(0, 0, -7), thisMonday.Add(-time.Nanosecond)
+}
+
+// lastMonth 返回 now 所在月的上一个自然月
+func lastMonth(now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return thisMonth.AddDate(0, -1, 0), thisMonth.Add(-time.Nanosecond)
+}
+
+// lastQuarter 返回 now 所在季度的上一个自然季度
+func lastQuarter(now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	quarterStartMonth := time.Month((int(now.Month())-1)/3*3 + 1)
+	thisQuarter := time.Date(now.Year(), quarterStartMonth, 1, 0, 0, 0, 0, time.UTC)
+	return thisQuarter.AddDate(0, -3, 0), thisQuarter.Add(-time.Nanosecond)
+}
+
+// isQuarterStart 判断 now 是否在季度的第一个月
+func isQuarterStart(now time.Time) bool {
+	return (int(now.UTC().Month())-1)%3 == 0
+}
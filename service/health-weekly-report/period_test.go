@@ -0,0 +1,165 @@
+package healthweeklyreport
+
+import (
+	"diabetes-agent-server/model"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// 周期结束时间为下一周期开始前 1ns
+func endOf(next time.Time) time.Time {
+	return next.Add(-time.Nanosecond)
+}
+
+func TestLastWeek(t *testing.T) {
+	tests := []struct {
+		name      string
+		now       time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{name: "wednesday", now: time.Date(2024, 1, 10, 15, 30, 0, 0, time.UTC), wantStart: date(2024, 1, 1), wantEnd: endOf(date(2024, 1, 8))},
+		{name: "monday midnight", now: date(2024, 1, 8), wantStart: date(2024, 1, 1), wantEnd: endOf(date(2024, 1, 8))},
+		{name: "sunday", now: time.Date(2024, 1, 14, 23, 59, 0, 0, time.UTC), wantStart: date(2024, 1, 1), wantEnd: endOf(date(2024, 1, 8))},
+		{name: "across year", now: date(2024, 1, 3), wantStart: date(2023, 12, 25), wantEnd: endOf(date(2024, 1, 1))},
+		{
+			name:      "converted to utc",
+			now:       time.Date(2024, 1, 8, 2, 0, 0, 0, time.FixedZone("UTC+8", 8*60*60)),
+			wantStart: date(2023, 12, 25),
+			wantEnd:   endOf(date(2024, 1, 1)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := lastWeek(tt.now)
+			assertRange(t, start, end, tt.wantStart, tt.wantEnd)
+		})
+	}
+}
+
+func TestLastMonth(t *testing.T) {
+	tests := []struct {
+		name      string
+		now       time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{name: "mid month", now: time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC), wantStart: date(2024, 2, 1), wantEnd: endOf(date(2024, 3, 1))},
+		{name: "first day", now: date(2024, 5, 1), wantStart: date(2024, 4, 1), wantEnd: endOf(date(2024, 5, 1))},
+		{name: "across year", now: date(2024, 1, 31), wantStart: date(2023, 12, 1), wantEnd: endOf(date(2024, 1, 1))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := lastMonth(tt.now)
+			assertRange(t, start, end, tt.wantStart, tt.wantEnd)
+		})
+	}
+}
+
+func TestLastQuarter(t *testing.T) {
+	tests := []struct {
+		name      string
+		now       time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{name: "quarter start", now: date(2024, 4, 1), wantStart: date(2024, 1, 1), wantEnd: endOf(date(2024, 4, 1))},
+		{name: "quarter end", now: time.Date(2024, 6, 30, 23, 0, 0, 0, time.UTC), wantStart: date(2024, 1, 1), wantEnd: endOf(date(2024, 4, 1))},
+		{name: "across year", now: date(2024, 2, 15), wantStart: date(2023, 10, 1), wantEnd: endOf(date(2024, 1, 1))},
+		{name: "fourth quarter", now: date(2024, 11, 5), wantStart: date(2024, 7, 1), wantEnd: endOf(date(2024, 10, 1))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := lastQuarter(tt.now)
+			assertRange(t, start, end, tt.wantStart, tt.wantEnd)
+		})
+	}
+}
+
+func TestIsQuarterStart(t *testing.T) {
+	tests := []struct {
+		month time.Month
+		want  bool
+	}{
+		{time.January, true},
+		{time.February, false},
+		{time.March, false},
+		{time.April, true},
+		{time.July, true},
+		{time.September, false},
+		{time.October, true},
+		{time.December, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.month.String(), func(t *testing.T) {
+			if got := isQuarterStart(date(2024, tt.month, 1)); got != tt.want {
+				t.Errorf("isQuarterStart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeriodRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		periodType string
+		date       time.Time
+		wantStart  time.Time
+		wantEnd    time.Time
+		wantErr    bool
+	}{
+		{name: "weekly", periodType: model.ReportPeriodWeekly, date: date(2024, 1, 10), wantStart: date(2024, 1, 8), wantEnd: endOf(date(2024, 1, 15))},
+		{name: "weekly on sunday", periodType: model.ReportPeriodWeekly, date: date(2024, 1, 14), wantStart: date(2024, 1, 8), wantEnd: endOf(date(2024, 1, 15))},
+		{name: "monthly in leap year", periodType: model.ReportPeriodMonthly, date: date(2024, 2, 29), wantStart: date(2024, 2, 1), wantEnd: endOf(date(2024, 3, 1))},
+		{name: "monthly on 31st", periodType: model.ReportPeriodMonthly, date: date(2024, 1, 31), wantStart: date(2024, 1, 1), wantEnd: endOf(date(2024, 2, 1))},
+		{name: "quarterly", periodType: model.ReportPeriodQuarterly, date: date(2024, 11, 30), wantStart: date(2024, 10, 1), wantEnd: endOf(date(2025, 1, 1))},
+		{name: "custom", periodType: model.ReportPeriodCustom, date: date(2024, 1, 10), wantErr: true},
+		{name: "unknown", periodType: "yearly", date: date(2024, 1, 10), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := PeriodRange(tt.periodType, tt.date)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("PeriodRange() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PeriodRange() error = %v", err)
+			}
+			assertRange(t, start, end, tt.wantStart, tt.wantEnd)
+		})
+	}
+}
+
+func TestReportTitle(t *testing.T) {
+	tests := []struct {
+		periodType string
+		want       string
+	}{
+		{model.ReportPeriodWeekly, "健康周报"},
+		{model.ReportPeriodMonthly, "健康月报"},
+		{model.ReportPeriodQuarterly, "健康季报"},
+		{model.ReportPeriodCustom, "健康报告"},
+		{"", "健康报告"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.periodType, func(t *testing.T) {
+			if got := reportTitle(tt.periodType); got != tt.want {
+				t.Errorf("reportTitle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func assertRange(t *testing.T, start, end, wantStart, wantEnd time.Time) {
+	t.Helper()
+	if !start.Equal(wantStart) || !end.Equal(wantEnd) {
+		t.Errorf("range = [%v, %v], want [%v, %v]", start, end, wantStart, wantEnd)
+	}
+}
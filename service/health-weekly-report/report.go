@@ -4,17 +4,20 @@ import (
 	"bytes"
 	"context"
 	"diabetes-agent-server/config"
-	"diabetes-agent-server/constants"
 	"diabetes-agent-server/dao"
 	"diabetes-agent-server/model"
 	"diabetes-agent-server/request"
 	"diabetes-agent-server/response"
 	"diabetes-agent-server/service/email"
+	glucoseanalytics "diabetes-agent-server/service/glucose-analytics"
+	llmprovider "diabetes-agent-server/service/llm-provider"
 	ossauth "diabetes-agent-server/service/oss-auth"
+	structuredoutput "diabetes-agent-server/service/structured-output"
 	"diabetes-agent-server/utils"
 	_ "embed"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/smtp"
 	"strings"
@@ -23,13 +26,21 @@ import (
 
 	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss"
 	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss/credentials"
+	"github.com/apache/rocketmq-client-go/v2/primitive"
 	"github.com/go-co-op/gocron"
-	"github.com/tmc/langchaingo/llms"
-	"github.com/tmc/langchaingo/llms/openai"
 	"github.com/tmc/langchaingo/prompts"
 )
 
-const modelName = "deepseek-v3.1"
+const (
+	// 纳入报告的治疗方案变更的回溯时长
+	profileChangeLookback = 90 * 24 * time.Hour
+
+	// 执行中的任务超过该时长未更新视为中断，可被重新领取
+	staleJobTimeout = 30 * time.Minute
+
+	// StaleReportTimeout 等待生成或生成中的报告超过该时长未更新视为中断，可重新生成
+	StaleReportTimeout = 30 * time.Minute
+)
 
 var (
 	//go:embed prompts/report.txt
@@ -45,100 +56,370 @@ var (
 type UserHealthData struct {
 	BloodGlucoseRecords []response.GetBloodGlucoseRecordsResponse `json:"blood_glucose_records"`
 	BloodGlucoseStats   *dao.BloodGlucoseStats                    `json:"blood_glucose_stats"`
+	GlucoseAnalytics    *glucoseanalytics.Analytics               `json:"glucose_analytics"`
 	ExerciseRecords     []response.GetExerciseRecordsResponse     `json:"exercise_records"`
 	ExerciseStats       *dao.ExerciseStats                        `json:"exercise_stats"`
+	MealRecords         []response.GetMealRecordsResponse         `json:"meal_records"`
+	MealStats           *dao.MealStats                            `json:"meal_stats"`
+	MedicationAdherence *dao.MedicationAdherenceStats             `json:"medication_adherence"`
+	VitalStats          *dao.VitalStats                           `json:"vital_stats"`
+	LatestLabResults    []response.GetLabResultsResponse          `json:"latest_lab_results"`
+	ProfileChanges      []response.HealthProfileChangeImpact      `json:"profile_changes"`
 	HealthProfile       *response.GetHealthProfileResponse        `json:"health_profile"`
 }
 
-// HealthAnalysis LLM 对近一周健康数据的分析结果
+// HealthAnalysis LLM 对报告周期内健康数据的分析结果，schema 标签用于校验 LLM 输出
 type HealthAnalysis struct {
-	BloodGlucoseAnalysis string `json:"blood_glucose_analysis"`
-	ExerciseAnalysis     string `json:"exercise_analysis"`
-	RecommendedMeals     []struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-	} `json:"recommended_meals"`
-	Conclusion string `json:"conclusion"`
+	BloodGlucoseAnalysis string            `json:"blood_glucose_analysis" schema:"min=1"`
+	ExerciseAnalysis     string            `json:"exercise_analysis" schema:"min=1"`
+	MealAnalysis         string            `json:"meal_analysis" schema:"min=1"`
+	RecommendedMeals     []RecommendedMeal `json:"recommended_meals" schema:"min=3,max=5"`
+	Conclusion           string            `json:"conclusion" schema:"min=1"`
+}
+
+type RecommendedMeal struct {
+	Name        string `json:"name" schema:"min=1"`
+	Description string `json:"description" schema:"min=1,max=150"`
 }
 
-// ReportData 健康周报模板数据
+// ReportData 健康报告模板数据
 type ReportData struct {
+	ReportTitle         string
 	ReportPeriod        string
 	BloodGlucoseRecords []response.GetBloodGlucoseRecordsResponse
 	BloodGlucoseStats   *dao.BloodGlucoseStats
+	GlucoseAnalytics    *glucoseanalytics.Analytics
 	ExerciseRecords     []response.GetExerciseRecordsResponse
 	ExerciseStats       *dao.ExerciseStats
+	MealRecords         []response.GetMealRecordsResponse
+	MealStats           *dao.MealStats
+	MedicationAdherence *dao.MedicationAdherenceStats
+	VitalStats          *dao.VitalStats
 	HealthAnalysis      *HealthAnalysis
+
+	// 数据不足，报告中展示记录提醒
+	InsufficientData bool
 }
 
-// NotificationData 健康周报通知数据
+// NotificationData 健康报告通知数据
 type NotificationData struct {
+	ReportTitle  string
 	ReportPeriod string
 	ReportURL    string
 }
 
-func SetupHealthWeeklyReportScheduler() {
+// JobSender 投递报告任务消息，由调用方注入，避免与 mq 包循环依赖
+type JobSender func(ctx context.Context, msg ReportJobMessage) error
+
+// ReportJobMessage 定时报告任务消息
+type ReportJobMessage struct {
+	JobID uint `json:"job_id"`
+}
+
+func SetupHealthWeeklyReportScheduler(send JobSender) {
+	if config.Cfg.Report.PDFFontPath == "" {
+		slog.Warn("report.pdf_font_path is not configured, health reports will be generated without pdf version")
+	}
+
 	s := gocron.NewScheduler(time.UTC)
 
-	// 每周一 2:00 执行
-	_, err := s.Every(1).Monday().At("02:00").Do(GenerateWeeklyReports)
+	// 定时续跑消息丢失或执行中断的任务，服务启动时立即执行一次
+	_, err := s.Every(staleJobTimeout).Do(resumeReportJobs, send)
+	if err != nil {
+		slog.Error("Failed to schedule health report job resume task", "err", err)
+		return
+	}
+
+	// 每周一 2:00 生成周报
+	_, err = s.Every(1).Monday().At("02:00").Do(GenerateWeeklyReports, send)
+	if err != nil {
+		slog.Error("Failed to schedule health weekly report generation task", "err", err)
+		return
+	}
+
+	// 每月 1 日 3:00 生成月报
+	_, err = s.Every(1).Month(1).At("03:00").Do(GenerateMonthlyReports, send)
+	if err != nil {
+		slog.Error("Failed to schedule health monthly report generation task", "err", err)
+		return
+	}
+
+	// 每季度首月 1 日 4:00 生成季报
+	_, err = s.Every(1).Month(1).At("04:00").Do(GenerateQuarterlyReports, send)
 	if err != nil {
-		slog.Error("Failed to schedule health report generation task", "err", err)
+		slog.Error("Failed to schedule health quarterly report generation task", "err", err)
 		return
 	}
 
 	s.StartAsync()
 }
 
-func GenerateWeeklyReports() {
-	ctx := context.Background()
+func GenerateWeeklyReports(send JobSender) {
+	start, end := lastWeek(time.Now())
+	enqueueReportJobs(context.Background(), send, model.ReportPeriodWeekly, start, end)
+}
+
+func GenerateMonthlyReports(send JobSender) {
+	start, end := lastMonth(time.Now())
+	enqueueReportJobs(context.Background(), send, model.ReportPeriodMonthly, start, end)
+}
+
+func GenerateQuarterlyReports(send JobSender) {
+	now := time.Now()
+	if !isQuarterStart(now) {
+		return
+	}
+
+	start, end := lastQuarter(now)
+	enqueueReportJobs(context.Background(), send, model.ReportPeriodQuarterly, start, end)
+}
 
-	users, err := dao.GetAllUsers()
+// 为所有未禁用的患者创建指定周期的报告任务，并为未完成的任务投递 MQ 消息。
+// 任务按 (用户, 周期, 开始时间) 去重，重复触发时已完成的任务不会再次执行
+func enqueueReportJobs(ctx context.Context, send JobSender, periodType string, start, end time.Time) {
+	emails, err := dao.GetActiveUserEmails(model.RolePatient, nil)
 	if err != nil {
 		slog.Error("Failed to get users for health report", "err", err)
 		return
 	}
 
-	now := time.Now().UTC()
-	thisMonday := now.AddDate(0, 0, -int(now.Weekday())+1).Truncate(24 * time.Hour)
-	end := thisMonday.Add(-time.Nanosecond)
-	start := thisMonday.AddDate(0, 0, -7)
+	jobs := make([]model.HealthReportJob, 0, len(emails))
+	for _, email := range emails {
+		jobs = append(jobs, model.HealthReportJob{
+			UserEmail:  email,
+			PeriodType: periodType,
+			StartAt:    start,
+			EndAt:      end,
+			Status:     model.ReportJobStatusPending,
+		})
+	}
+	if err := dao.CreateHealthReportJobs(jobs); err != nil {
+		slog.Error("Failed to create health report jobs",
+			"period_type", periodType,
+			"err", err,
+		)
+		return
+	}
+
+	unfinished, err := dao.GetUnfinishedHealthReportJobs(periodType, start)
+	if err != nil {
+		slog.Error("Failed to get unfinished health report jobs",
+			"period_type", periodType,
+			"err", err,
+		)
+		return
+	}
+	sendReportJobs(ctx, send, unfinished)
+}
+
+func resumeReportJobs(send JobSender) {
+	jobs, err := dao.GetResumableHealthReportJobs(time.Now().Add(-staleJobTimeout))
+	if err != nil {
+		slog.Error("Failed to get resumable health report jobs", "err", err)
+		return
+	}
+	if len(jobs) == 0 {
+		return
+	}
+	sendReportJobs(context.Background(), send, jobs)
+}
 
-	for _, user := range users {
-		if err := generateWeeklyReport(ctx, user.Email, start, end); err != nil {
-			slog.Error("Failed to generate health report",
-				"email", user.Email,
-				"start", start,
-				"end", end,
+// 投递失败的任务保持原状态，由定时续跑重新投递
+func sendReportJobs(ctx context.Context, send JobSender, jobs []model.HealthReportJob) {
+	for _, job := range jobs {
+		if err := send(ctx, ReportJobMessage{JobID: job.ID}); err != nil {
+			slog.Error("Failed to send health report job message",
+				"job_id", job.ID,
+				"email", job.UserEmail,
 				"err", err,
 			)
 		}
 	}
+	slog.Info("health report jobs sent", "count", len(jobs))
 }
 
-func generateWeeklyReport(ctx context.Context, email string, start, end time.Time) error {
-	userHealthData, err := getUserHealthData(ctx, email, start, end)
+// HandleReportJobMessage 执行定时报告任务，失败时记录原因并返回错误由 MQ 重试。
+// 任务通过条件更新领取，重复投递的消息不会重复生成报告和发送通知
+func HandleReportJobMessage(ctx context.Context, msg *primitive.MessageExt) error {
+	var jobMessage ReportJobMessage
+	if err := json.Unmarshal(msg.Body, &jobMessage); err != nil {
+		return fmt.Errorf("failed to unmarshal message body: %v", err)
+	}
+
+	job, err := dao.GetHealthReportJobByID(jobMessage.JobID)
 	if err != nil {
-		return fmt.Errorf("failed to get user health data: %v", err)
+		return fmt.Errorf("failed to get health report job: %v", err)
+	}
+	if job == nil {
+		return nil
+	}
+
+	claimed, err := dao.ClaimHealthReportJob(job.ID, time.Now().Add(-staleJobTimeout))
+	if err != nil {
+		return fmt.Errorf("failed to claim health report job: %v", err)
+	}
+	if !claimed {
+		slog.Info("health report job already claimed", "job_id", job.ID, "status", job.Status)
+		return nil
 	}
 
-	healthAnalysis, err := generateHealthAnalysis(ctx, userHealthData)
+	decision, reportID, err := runReportJob(ctx, job)
 	if err != nil {
-		return fmt.Errorf("failed to generate health analysis: %v", err)
+		if err := dao.UpdateHealthReportJobStatus(job.ID, model.ReportJobStatusFailed, err.Error()); err != nil {
+			slog.Error("Failed to update health report job status", "err", err)
+		}
+		return fmt.Errorf("failed to run health report job %d: %v", job.ID, err)
+	}
+
+	if err := dao.CompleteHealthReportJob(job.ID, decision, reportID); err != nil {
+		return fmt.Errorf("failed to complete health report job: %v", err)
+	}
+	return nil
+}
+
+// 生成任务对应的报告，返回数据充足性判断结果。
+// 数据不足且用户选择跳过时不创建报告，重新执行时复用已有的报告记录，报告已生成时不再重新生成，仅补发未完成的通知
+func runReportJob(ctx context.Context, job *model.HealthReportJob) (string, *uint, error) {
+	userHealthData, err := getUserHealthData(ctx, job.UserEmail, job.StartAt, job.EndAt)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get user health data: %v", err)
+	}
+
+	if !hasSufficientData(userHealthData) {
+		user, err := dao.GetUserByEmail(job.UserEmail)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to get user: %v", err)
+		}
+		if user != nil && user.InsufficientDataPolicy == model.InsufficientDataPolicySkip {
+			slog.Info("insufficient data, skip health report",
+				"job_id", job.ID,
+				"email", job.UserEmail,
+				"period_type", job.PeriodType,
+			)
+			return model.ReportDecisionSkipped, nil, nil
+		}
+	}
+
+	report, err := dao.GetOrCreateHealthReport(job.UserEmail, job.PeriodType, job.StartAt, job.EndAt)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get health report: %v", err)
 	}
 
-	formattedStart := start.Format("2006-01-02")
-	formattedEnd := end.Format("2006-01-02")
-	fileName := fmt.Sprintf("%s_%s.html", formattedStart, formattedEnd)
+	decision := reportDecision(userHealthData)
+	if report.Status != model.ReportStatusCompleted {
+		if err := dao.UpdateHealthReportStatus(report.ID, model.ReportStatusGenerating, ""); err != nil {
+			return "", nil, fmt.Errorf("failed to update health report status: %v", err)
+		}
+
+		if err := generateReport(ctx, report, userHealthData); err != nil {
+			if err := dao.UpdateHealthReportStatus(report.ID, model.ReportStatusFailed, err.Error()); err != nil {
+				slog.Error("Failed to update health report status", "err", err)
+			}
+			return "", nil, err
+		}
+	}
 
-	htmlContent, err := renderReport(ctx, &ReportData{
+	if report.NotifiedAt == nil {
+		if err := notifyReport(ctx, report, decision); err != nil {
+			return "", nil, err
+		}
+	}
+
+	return decision, &report.ID, nil
+}
+
+// GenerateReportMessage 按需生成健康报告的消息，报告记录由接口预先创建
+type GenerateReportMessage struct {
+	ReportID uint `json:"report_id"`
+}
+
+// HandleGenerateReportMessage 生成用户按需创建的健康报告，失败时返回错误由 MQ 重试
+func HandleGenerateReportMessage(ctx context.Context, msg *primitive.MessageExt) error {
+	var reportMessage GenerateReportMessage
+	if err := json.Unmarshal(msg.Body, &reportMessage); err != nil {
+		return fmt.Errorf("failed to unmarshal message body: %v", err)
+	}
+
+	report, err := dao.GetHealthReportByID(reportMessage.ReportID)
+	if err != nil {
+		return fmt.Errorf("failed to get health report: %v", err)
+	}
+	if report == nil || report.NotifiedAt != nil {
+		return nil
+	}
+
+	userHealthData, err := getUserHealthData(ctx, report.UserEmail, report.StartAt, report.EndAt)
+	if err != nil {
+		return fmt.Errorf("failed to get user health data: %v", err)
+	}
+
+	// 用户主动请求的报告不跳过，数据不足时生成提示报告。报告已生成但未通知时仅补发通知
+	if report.Status != model.ReportStatusCompleted {
+		// 重复投递的消息或重新提交的请求不重复生成正在生成的报告
+		claimed, err := dao.ClaimHealthReport(report.ID, time.Now().Add(-StaleReportTimeout))
+		if err != nil {
+			return fmt.Errorf("failed to claim health report: %v", err)
+		}
+		if !claimed {
+			slog.Info("health report is being generated by another consumer", "report_id", report.ID)
+			return nil
+		}
+
+		if err := generateReport(ctx, report, userHealthData); err != nil {
+			if err := dao.UpdateHealthReportStatus(report.ID, model.ReportStatusFailed, err.Error()); err != nil {
+				slog.Error("Failed to update health report status", "err", err)
+			}
+			return fmt.Errorf("failed to generate health report %d: %v", report.ID, err)
+		}
+	}
+
+	return notifyReport(ctx, report, reportDecision(userHealthData))
+}
+
+// 生成健康报告并上传到 OSS，记录报告文件并标记为已生成。
+// 数据不足时不调用 LLM，生成模板化的提示报告
+func generateReport(ctx context.Context, report *model.HealthWeeklyReport, userHealthData *UserHealthData) error {
+	email := report.UserEmail
+	title := reportTitle(report.PeriodType)
+
+	decision := reportDecision(userHealthData)
+	var healthAnalysis *HealthAnalysis
+	if decision == model.ReportDecisionGenerated {
+		var err error
+		analysisCtx := llmprovider.WithUsageScope(ctx, email, "")
+		healthAnalysis, err = generateHealthAnalysis(analysisCtx, title, userHealthData)
+		if err != nil {
+			return fmt.Errorf("failed to generate health analysis: %v", err)
+		}
+	} else {
+		healthAnalysis = placeholderAnalysis()
+	}
+
+	formattedStart := report.StartAt.Format("2006-01-02")
+	formattedEnd := report.EndAt.Format("2006-01-02")
+	baseName := fmt.Sprintf("%s_%s", formattedStart, formattedEnd)
+	if report.PeriodType != model.ReportPeriodWeekly {
+		baseName = fmt.Sprintf("%s_%s", report.PeriodType, baseName)
+	}
+	fileName := baseName + ".html"
+
+	reportData := &ReportData{
+		ReportTitle:         title,
 		ReportPeriod:        formattedStart + " 至 " + formattedEnd,
 		BloodGlucoseRecords: userHealthData.BloodGlucoseRecords,
 		BloodGlucoseStats:   userHealthData.BloodGlucoseStats,
+		GlucoseAnalytics:    userHealthData.GlucoseAnalytics,
 		ExerciseRecords:     userHealthData.ExerciseRecords,
 		ExerciseStats:       userHealthData.ExerciseStats,
+		MealRecords:         userHealthData.MealRecords,
+		MealStats:           userHealthData.MealStats,
+		MedicationAdherence: userHealthData.MedicationAdherence,
+		VitalStats:          userHealthData.VitalStats,
 		HealthAnalysis:      healthAnalysis,
-	})
+		InsufficientData:    decision == model.ReportDecisionPlaceholder,
+	}
+
+	htmlContent, err := renderReport(ctx, reportData)
 	if err != nil {
 		return fmt.Errorf("failed to render report: %v", err)
 	}
@@ -152,45 +433,73 @@ func generateWeeklyReport(ctx context.Context, email string, start, end time.Tim
 		return fmt.Errorf("failed to generate oss key: %v", err)
 	}
 
-	// 存储健康周报元数据
-	if err := dao.DB.Create(&model.HealthWeeklyReport{
-		UserEmail:  email,
-		StartAt:    start,
-		EndAt:      end,
-		FileName:   fileName,
-		ObjectName: objectName,
-	}).Error; err != nil {
-		return fmt.Errorf("failed to save health weekly report: %v", err)
+	// 上传健康报告到 OSS
+	if err := uploadReport(ctx, strings.NewReader(htmlContent), objectName, "text/html; charset=utf-8"); err != nil {
+		return fmt.Errorf("failed to upload health report: %v", err)
 	}
 
-	// 上传健康周报到 OSS
-	if err := uploadReport(ctx, htmlContent, objectName); err != nil {
-		return fmt.Errorf("failed to upload health weekly report: %v", err)
+	// 生成 PDF 版本并存放在 HTML 同一目录，未配置字体时跳过
+	var pdfFileName, pdfObjectName string
+	if config.Cfg.Report.PDFFontPath != "" {
+		pdfContent, err := renderPDF(reportData)
+		if err != nil {
+			return fmt.Errorf("failed to render pdf report: %v", err)
+		}
+
+		pdfFileName = baseName + ".pdf"
+		pdfObjectName, err = ossauth.GenerateKey(request.OSSAuthRequest{
+			Namespace: ossauth.OSSKeyPrefixHealthWeeklyReport,
+			Email:     email,
+			FileName:  pdfFileName,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to generate oss key: %v", err)
+		}
+
+		if err := uploadReport(ctx, bytes.NewReader(pdfContent), pdfObjectName, "application/pdf"); err != nil {
+			return fmt.Errorf("failed to upload pdf report: %v", err)
+		}
+	} else {
+		slog.Debug("pdf font path is not configured, skip pdf report", "report_id", report.ID)
 	}
 
-	// 存储系统消息
-	msg := model.SystemMessage{
-		UserEmail: email,
-		Title:     "健康周报",
-		Content:   fmt.Sprintf("您的健康周报(%s 至 %s)已生成，请查收。", formattedStart, formattedEnd),
-		IsRead:    false,
+	// 记录报告文件，标记为已生成
+	if err := dao.CompleteHealthReport(report.ID, fileName, objectName, pdfFileName, pdfObjectName); err != nil {
+		return fmt.Errorf("failed to save health report: %v", err)
 	}
-	if err := dao.DB.Create(&msg).Error; err != nil {
+	report.Status = model.ReportStatusCompleted
+	return nil
+}
+
+// 通过系统消息和邮件通知用户报告已生成，完成后记录通知时间。
+// 单个渠道发送失败仅记录日志，避免重新执行时重复通知
+func notifyReport(ctx context.Context, report *model.HealthWeeklyReport, decision string) error {
+	email := report.UserEmail
+	title := reportTitle(report.PeriodType)
+	formattedStart := report.StartAt.Format("2006-01-02")
+	formattedEnd := report.EndAt.Format("2006-01-02")
+
+	// 存储系统消息，更新未读消息计数
+	content := fmt.Sprintf("您的%s(%s 至 %s)已生成，请查收。", title, formattedStart, formattedEnd)
+	if decision == model.ReportDecisionPlaceholder {
+		content += insufficientDataNudge
+	}
+	if err := dao.CreateSystemMessage(ctx, email, title, content); err != nil {
 		slog.Error("Failed to save system message", "err", err)
 	}
 
-	// 更新未读消息计数
-	key := fmt.Sprintf(constants.KeyUnreadMsgCount, email)
-	dao.RedisClient.Incr(ctx, key)
-
 	// 推送通知邮件
 	if err := sendNotification(email, NotificationData{
+		ReportTitle:  title,
 		ReportPeriod: formattedStart + " 至 " + formattedEnd,
 		ReportURL:    fmt.Sprintf("%s/health-weekly-report", config.Cfg.Client.BaseURL),
 	}); err != nil {
 		slog.Error("failed to send notification", "err", err)
 	}
 
+	if err := dao.MarkHealthReportNotified(report.ID); err != nil {
+		return fmt.Errorf("failed to mark health report notified: %v", err)
+	}
 	return nil
 }
 
@@ -215,6 +524,31 @@ func getUserHealthData(ctx context.Context, email string, start, end time.Time)
 		return nil, err
 	}
 
+	mealRecords, err := dao.GetMealRecords(email, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	medicationAdherence, err := dao.GetMedicationAdherenceStats(email, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	vitalRecords, err := dao.GetVitalRecords(email, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	latestLabResults, err := dao.GetLatestLabResults(email)
+	if err != nil {
+		return nil, err
+	}
+
+	profileChanges, err := dao.GetHealthProfileChangeImpacts(email, end.Add(-profileChangeLookback))
+	if err != nil {
+		return nil, err
+	}
+
 	healthProfile, err := dao.GetHealthProfile(email)
 	if err != nil {
 		return nil, err
@@ -223,52 +557,46 @@ func getUserHealthData(ctx context.Context, email string, start, end time.Time)
 	return &UserHealthData{
 		BloodGlucoseRecords: bloodGlucoseRecords,
 		BloodGlucoseStats:   bloodGlucoseStats,
+		GlucoseAnalytics:    glucoseanalytics.Analyze(bloodGlucoseRecords, glucoseanalytics.BandsFromProfile(healthProfile)),
 		ExerciseRecords:     exerciseRecords,
 		ExerciseStats:       exerciseStats,
+		MealRecords:         mealRecords,
+		MealStats:           dao.GetMealStats(mealRecords),
+		MedicationAdherence: medicationAdherence,
+		VitalStats:          dao.GetVitalStats(vitalRecords),
+		LatestLabResults:    latestLabResults,
+		ProfileChanges:      profileChanges,
 		HealthProfile:       healthProfile,
 	}, nil
 }
 
-// 调用 LLM 对近一周健康数据进行分析
-func generateHealthAnalysis(ctx context.Context, userHealthData *UserHealthData) (*HealthAnalysis, error) {
+// 调用 LLM 对报告周期内的健康数据进行分析
+func generateHealthAnalysis(ctx context.Context, title string, userHealthData *UserHealthData) (*HealthAnalysis, error) {
 	userHealthDataJSON, _ := json.Marshal(userHealthData)
 
-	template := prompts.NewPromptTemplate(reportPrompt, []string{"user_health_data"})
-	prompt, err := template.Format(map[string]any{"user_health_data": userHealthDataJSON})
-	if err != nil {
-		return nil, err
-	}
-
-	llm, err := openai.New(
-		openai.WithModel(modelName),
-		openai.WithToken(config.Cfg.Model.APIKey),
-		openai.WithBaseURL(config.Cfg.Model.BaseURL),
-		openai.WithHTTPClient(utils.GlobalHTTPClient),
-	)
+	template := prompts.NewPromptTemplate(reportPrompt, []string{"report_title", "user_health_data"})
+	prompt, err := template.Format(map[string]any{
+		"report_title":     title,
+		"user_health_data": userHealthDataJSON,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	result, err := llms.GenerateFromSinglePrompt(ctx, llm, prompt)
+	llm, err := llmprovider.Model(llmprovider.TaskReport)
 	if err != nil {
 		return nil, err
 	}
 
-	slog.Debug("generated health analysis", "result", result)
-
-	// 去除 Markdown 格式的 json 字符串前后缀
-	result = strings.TrimPrefix(result, "```json")
-	result = strings.TrimSuffix(result, "```")
-
-	var healthAnalysis HealthAnalysis
-	if err := json.Unmarshal([]byte(result), &healthAnalysis); err != nil {
-		return nil, err
-	}
-
-	return &healthAnalysis, nil
+	// 输出未通过校验时携带错误重新生成，多次失败后使用基于规则的分析
+	return structuredoutput.Generate(ctx, llm, prompt,
+		structuredoutput.WithFallback(func() *HealthAnalysis {
+			return ruleBasedAnalysis(userHealthData)
+		}),
+	)
 }
 
-// 渲染健康周报的 HTML 模板
+// 渲染健康报告的 HTML 模板
 func renderReport(ctx context.Context, data *ReportData) (string, error) {
 	tmpl, err := template.New("report").
 		Funcs(template.FuncMap{
@@ -276,6 +604,12 @@ func renderReport(ctx context.Context, data *ReportData) (string, error) {
 				bytes, _ := json.Marshal(v)
 				return string(bytes)
 			},
+			"deref": func(v *float32) float32 {
+				return *v
+			},
+			"percent": func(v float64) float64 {
+				return v * 100
+			},
 		}).
 		Parse(reportTemplate)
 
@@ -290,7 +624,7 @@ func renderReport(ctx context.Context, data *ReportData) (string, error) {
 	return buf.String(), nil
 }
 
-func uploadReport(ctx context.Context, content string, objectName string) error {
+func uploadReport(ctx context.Context, body io.Reader, objectName, contentType string) error {
 	cfg := oss.NewConfig().
 		WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
 			config.Cfg.OSS.AccessKeyID,
@@ -303,13 +637,13 @@ func uploadReport(ctx context.Context, content string, objectName string) error
 	req := oss.PutObjectRequest{
 		Bucket:      oss.Ptr(config.Cfg.OSS.BucketName),
 		Key:         oss.Ptr(objectName),
-		Body:        strings.NewReader(content),
-		ContentType: oss.Ptr("text/html; charset=utf-8"),
+		Body:        body,
+		ContentType: oss.Ptr(contentType),
 	}
 
 	_, err := client.PutObject(ctx, &req)
 	if err != nil {
-		return fmt.Errorf("failed to upload health weekly report: %v", err)
+		return fmt.Errorf("failed to upload health report: %v", err)
 	}
 	return nil
 }
@@ -324,8 +658,8 @@ func sendNotification(toEmail string, data NotificationData) error {
 		return nil
 	}
 
-	// 若用户未开启健康周报通知，直接返回
-	if !user.EnableWeeklyReportNotification {
+	// 若用户不存在、未开启健康报告通知或未验证邮箱，直接返回
+	if user == nil || !user.EnableWeeklyReportNotification || !user.EmailVerified {
 		return nil
 	}
 
@@ -355,7 +689,7 @@ func buildNotificationMessage(fromEmail string, toEmail string, data Notificatio
 	header := make(map[string]string)
 	header["From"] = fmt.Sprintf("%s <%s>", "Diabetes Agent", fromEmail)
 	header["To"] = toEmail
-	header["Subject"] = "您的健康周报已生成"
+	header["Subject"] = fmt.Sprintf("您的%s已生成", data.ReportTitle)
 	header["MIME-Version"] = "1.0"
 	header["Content-Type"] = "text/html; charset=UTF-8"
 	for k, v := range header {
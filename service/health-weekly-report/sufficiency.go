@@ -0,0 +1,34 @@
+package healthweeklyreport
+
+import "diabetes-agent-server/model"
+
+// 报告周期内血糖、运动、饮食记录合计少于该数量时视为数据不足，不调用 LLM 分析
+const minReportRecords = 3
+
+// 数据不足时提示用户坚持记录的文案，用于提示报告和系统消息
+const insufficientDataNudge = "本期记录的数据较少，暂时无法给出有针对性的分析。" +
+	"建议每天至少记录 1-2 次血糖，并记录运动和饮食情况，下期报告将为您提供更完整的健康分析。"
+
+// 判断报告周期内的数据是否足以生成完整报告
+func hasSufficientData(data *UserHealthData) bool {
+	count := len(data.BloodGlucoseRecords) + len(data.ExerciseRecords) + len(data.MealRecords)
+	return count >= minReportRecords
+}
+
+// 根据数据充足性返回生成完整报告或提示报告
+func reportDecision(data *UserHealthData) string {
+	if hasSufficientData(data) {
+		return model.ReportDecisionGenerated
+	}
+	return model.ReportDecisionPlaceholder
+}
+
+// 数据不足时使用的模板化分析结果
+func placeholderAnalysis() *HealthAnalysis {
+	return &HealthAnalysis{
+		BloodGlucoseAnalysis: "本期血糖记录不足，暂无法分析血糖变化趋势。",
+		ExerciseAnalysis:     "本期运动记录不足，暂无法评估运动效果。",
+		MealAnalysis:         "本期饮食记录不足，暂无法分析饮食对血糖的影响。",
+		Conclusion:           insufficientDataNudge,
+	}
+}
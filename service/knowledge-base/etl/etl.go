@@ -3,7 +3,9 @@ package etl
 import (
 	"context"
 	"diabetes-agent-server/config"
+	"diabetes-agent-server/constants"
 	"diabetes-agent-server/model"
+	knowledgebase "diabetes-agent-server/service/knowledge-base"
 	"diabetes-agent-server/service/knowledge-base/etl/processor"
 	"diabetes-agent-server/utils"
 	"encoding/json"
@@ -29,7 +31,8 @@ type DeleteMessage struct {
 	ObjectName string         `json:"object_name"`
 }
 
-func init() {
+// Init 创建知识文件 ETL 处理器，需在加载配置后调用
+func Init() {
 	pdfProcessor, err := processor.NewPDFETLProcessor()
 	if err != nil {
 		panic(fmt.Sprintf("error creating PDFETLProcessor: %v", err))
@@ -46,13 +49,34 @@ func init() {
 	}
 }
 
+// HandleETLMessage 执行知识文件的向量化流程，失败时返回错误由 MQ 重试，
+// 最后一次重试仍失败时将文件标记为 PROCESSED_FAILED，重试成功后状态更新为 PROCESSED
 func HandleETLMessage(ctx context.Context, msg *primitive.MessageExt) error {
 	var etlMessage ETLMessage
 	if err := json.Unmarshal(msg.Body, &etlMessage); err != nil {
 		return fmt.Errorf("failed to unmarshal message body: %v", err)
 	}
 
-	object, err := getObjectFromOSS(ctx, &etlMessage)
+	err := executeETL(ctx, &etlMessage)
+	if err != nil {
+		if msg.ReconsumeTimes < constants.MQMaxReconsumeTimes {
+			return err
+		}
+		if err := knowledgebase.UpdateKnowledgeMetadataStatus(etlMessage.ObjectName, model.StatusProcessedFailed); err != nil {
+			slog.Error("Failed to mark knowledge metadata as failed",
+				"object_name", etlMessage.ObjectName,
+				"err", err,
+			)
+		}
+		return err
+	}
+
+	slog.Info("ETL pipeline executed successfully", "msg_id", msg.MsgId)
+	return nil
+}
+
+func executeETL(ctx context.Context, etlMessage *ETLMessage) error {
+	object, err := getObjectFromOSS(ctx, etlMessage)
 	if err != nil {
 		return fmt.Errorf("failed to get object from oss: %v", err)
 	}
@@ -63,10 +87,14 @@ func HandleETLMessage(ctx context.Context, msg *primitive.MessageExt) error {
 	for _, processor := range etlProcessors {
 		if processor.CanProcess(etlMessage.FileType) {
 			foundProcessor = true
+
+			// 重试或重新处理时先删除之前写入的向量，避免重复
+			if err := processor.DeleteVectorStore(ctx, etlMessage.ObjectName); err != nil {
+				return fmt.Errorf("failed to delete vector store: %v", err)
+			}
 			if err := processor.ExecuteETLPipeline(ctx, object, etlMessage.ObjectName); err != nil {
 				return fmt.Errorf("failed to execute ETL pipeline: %v", err)
 			}
-			slog.Info("ETL pipeline executed successfully", "msg_id", msg.MsgId)
 			return nil
 		}
 	}
@@ -84,28 +112,41 @@ func HandleDeleteMessage(ctx context.Context, msg *primitive.MessageExt) error {
 		return fmt.Errorf("failed to unmarshal message body: %v", err)
 	}
 
-	if err := deleteObjectFromOSS(ctx, &deleteMessage); err != nil {
+	if err := DeleteKnowledgeFile(ctx, deleteMessage.FileType, deleteMessage.ObjectName); err != nil {
+		return err
+	}
+	slog.Info("vector store deleted successfully", "msg_id", msg.MsgId)
+	return nil
+}
+
+// DeleteKnowledgeFile 删除知识文件在 OSS 中的对象及其向量存储
+func DeleteKnowledgeFile(ctx context.Context, fileType model.FileType, objectName string) error {
+	if err := deleteObjectFromOSS(ctx, objectName); err != nil {
 		return fmt.Errorf("failed to delete object from oss: %v", err)
 	}
-	slog.Info("delete object from oss successfully", "object_name", deleteMessage.ObjectName)
+	slog.Info("delete object from oss successfully", "object_name", objectName)
 
-	foundProcessor := false
 	for _, processor := range etlProcessors {
-		if processor.CanProcess(deleteMessage.FileType) {
-			foundProcessor = true
-			if err := processor.DeleteVectorStore(ctx, deleteMessage.ObjectName); err != nil {
+		if processor.CanProcess(fileType) {
+			if err := processor.DeleteVectorStore(ctx, objectName); err != nil {
 				return fmt.Errorf("failed to delete vector store: %v", err)
 			}
-			slog.Info("vector store deleted successfully", "msg_id", msg.MsgId)
 			return nil
 		}
 	}
 
-	if !foundProcessor {
-		return fmt.Errorf("no processor found for file type: %s", deleteMessage.FileType)
-	}
+	return fmt.Errorf("no processor found for file type: %s", fileType)
+}
 
-	return nil
+// DeleteUserVectorStore 按 user_email 删除用户的所有向量，用于清理未关联知识文件元数据的残留向量。
+// 所有处理器写入同一集合，因此使用任一处理器执行
+func DeleteUserVectorStore(ctx context.Context, email string) (int64, error) {
+	return etlProcessors[0].DeleteUserVectorStore(ctx, email)
+}
+
+// CountUserVectors 统计用户的向量数量
+func CountUserVectors(ctx context.Context, email string) (int64, error) {
+	return etlProcessors[0].CountUserVectors(ctx, email)
 }
 
 func getObjectFromOSS(ctx context.Context, etlMessage *ETLMessage) ([]byte, error) {
@@ -135,7 +176,7 @@ func getObjectFromOSS(ctx context.Context, etlMessage *ETLMessage) ([]byte, erro
 	return data, nil
 }
 
-func deleteObjectFromOSS(ctx context.Context, deleteMessage *DeleteMessage) error {
+func deleteObjectFromOSS(ctx context.Context, objectName string) error {
 	cfg := oss.NewConfig().
 		WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
 			config.Cfg.OSS.AccessKeyID,
@@ -147,7 +188,7 @@ func deleteObjectFromOSS(ctx context.Context, deleteMessage *DeleteMessage) erro
 
 	_, err := client.DeleteObject(ctx, &oss.DeleteObjectRequest{
 		Bucket: oss.Ptr(config.Cfg.OSS.BucketName),
-		Key:    oss.Ptr(deleteMessage.ObjectName),
+		Key:    oss.Ptr(objectName),
 	})
 	if err != nil {
 		return err
@@ -5,18 +5,17 @@ import (
 	"diabetes-agent-server/config"
 	"diabetes-agent-server/model"
 	knowledgebase "diabetes-agent-server/service/knowledge-base"
-	"diabetes-agent-server/utils"
+	llmprovider "diabetes-agent-server/service/llm-provider"
 	"fmt"
 
 	"github.com/milvus-io/milvus/client/v2/column"
+	"github.com/milvus-io/milvus/client/v2/entity"
 	"github.com/milvus-io/milvus/client/v2/milvusclient"
 	"github.com/tmc/langchaingo/embeddings"
-	"github.com/tmc/langchaingo/llms/openai"
 	"github.com/tmc/langchaingo/textsplitter"
 )
 
 const (
-	embeddingModelName = "text-embedding-v4"
 	chunkSize          = 4000
 	chunkOverlap       = 400
 	embeddingBatchSize = 10
@@ -35,6 +34,12 @@ type ETLProcessor interface {
 
 	// 删除向量存储
 	DeleteVectorStore(ctx context.Context, objectName string) error
+
+	// 删除用户的所有向量，返回删除的数量
+	DeleteUserVectorStore(ctx context.Context, email string) (int64, error)
+
+	// 统计用户的向量数量
+	CountUserVectors(ctx context.Context, email string) (int64, error)
 }
 
 // BaseETLProcessor 基础 ETL处理器，提供删除向量存储的默认实现
@@ -47,12 +52,7 @@ type BaseETLProcessor struct {
 var _ ETLProcessor = &BaseETLProcessor{}
 
 func NewBaseETLProcessor(textSplitter textsplitter.TextSplitter) (*BaseETLProcessor, error) {
-	client, err := openai.New(
-		openai.WithEmbeddingModel(embeddingModelName),
-		openai.WithToken(config.Cfg.Model.APIKey),
-		openai.WithBaseURL(config.Cfg.Model.BaseURL),
-		openai.WithHTTPClient(utils.GlobalHTTPClient),
-	)
+	client, err := llmprovider.Embedder(llmprovider.TaskEmbed)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create embedder client: %v", err)
 	}
@@ -95,7 +95,8 @@ func (p *BaseETLProcessor) DeleteVectorStore(ctx context.Context, objectName str
 		return fmt.Errorf("error parsing object name: %v", err)
 	}
 
-	expression := fmt.Sprintf("user_email == '%s' and title == '%s'", userEmail, fileName)
+	expression := fmt.Sprintf("user_email == %s and title == %s",
+		knowledgebase.QuoteFilterValue(userEmail), knowledgebase.QuoteFilterValue(fileName))
 	deleteOption := milvusclient.NewDeleteOption(CollectionName).WithExpr(expression)
 
 	_, err = p.MilvusClient.Delete(ctx, deleteOption)
@@ -106,6 +107,38 @@ func (p *BaseETLProcessor) DeleteVectorStore(ctx context.Context, objectName str
 	return nil
 }
 
+func (p *BaseETLProcessor) DeleteUserVectorStore(ctx context.Context, email string) (int64, error) {
+	expression := "user_email == " + knowledgebase.QuoteFilterValue(email)
+	deleteOption := milvusclient.NewDeleteOption(CollectionName).WithExpr(expression)
+
+	result, err := p.MilvusClient.Delete(ctx, deleteOption)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting user document chunks: %v", err)
+	}
+
+	return result.DeleteCount, nil
+}
+
+func (p *BaseETLProcessor) CountUserVectors(ctx context.Context, email string) (int64, error) {
+	expression := "user_email == " + knowledgebase.QuoteFilterValue(email)
+	queryOption := milvusclient.NewQueryOption(CollectionName).
+		WithFilter(expression).
+		WithOutputFields("count(*)").
+		WithConsistencyLevel(entity.ClStrong)
+
+	result, err := p.MilvusClient.Query(ctx, queryOption)
+	if err != nil {
+		return 0, fmt.Errorf("error counting user document chunks: %v", err)
+	}
+
+	count, err := result.GetColumn("count(*)").GetAsInt64(0)
+	if err != nil {
+		return 0, fmt.Errorf("error reading document chunk count: %v", err)
+	}
+
+	return count, nil
+}
+
 type Metadata struct {
 	objectName string
 }
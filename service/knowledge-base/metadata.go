@@ -49,3 +49,12 @@ func ParseObjectName(objectName string) (string, string, error) {
 	}
 	return pathSegments[1], pathSegments[len(pathSegments)-1], nil
 }
+
+// 转义 Milvus 过滤表达式字符串字面量中的反斜杠和单引号
+var filterLiteralEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+// QuoteFilterValue 将值转义后用单引号包裹，作为 Milvus 过滤表达式中的字符串字面量，
+// 避免用户可控的邮箱或文件名改写表达式
+func QuoteFilterValue(value string) string {
+	return "'" + filterLiteralEscaper.Replace(value) + "'"
+}
@@ -2,54 +2,38 @@ package knowledgebase
 
 import (
 	"context"
-	"diabetes-agent-server/config"
 	"diabetes-agent-server/dao"
-	"diabetes-agent-server/utils"
+	llmprovider "diabetes-agent-server/service/llm-provider"
+	structuredoutput "diabetes-agent-server/service/structured-output"
 	_ "embed"
-	"fmt"
 	"log/slog"
 
 	"github.com/milvus-io/milvus/client/v2/column"
 	"github.com/milvus-io/milvus/client/v2/entity"
 	"github.com/milvus-io/milvus/client/v2/milvusclient"
 	"github.com/tmc/langchaingo/embeddings"
-	"github.com/tmc/langchaingo/llms"
-	"github.com/tmc/langchaingo/llms/openai"
 	"github.com/tmc/langchaingo/prompts"
 )
 
 const (
-	llmName            = "qwen-plus"
-	embeddingModelName = "text-embedding-v4"
-	collectionName     = "knowledge_doc"
-	scoreThreshold     = 0.5
-	limit              = 20
+	collectionName = "knowledge_doc"
+	scoreThreshold = 0.5
+	limit          = 20
 )
 
-var modelClient *openai.LLM
-
 //go:embed prompts/rewrite_query.txt
 var rewriteQueryPrompt string
 
+// RewrittenQuery 改写后的检索查询
+type RewrittenQuery struct {
+	Query string `json:"query" schema:"min=1"`
+}
+
 type VectorDBSearchResult struct {
 	Chunk string  `json:"chunk"`
 	Score float32 `json:"score"`
 }
 
-func init() {
-	var err error
-	modelClient, err = openai.New(
-		openai.WithModel(llmName),
-		openai.WithEmbeddingModel(embeddingModelName),
-		openai.WithToken(config.Cfg.Model.APIKey),
-		openai.WithBaseURL(config.Cfg.Model.BaseURL),
-		openai.WithHTTPClient(utils.GlobalHTTPClient),
-	)
-	if err != nil {
-		panic(fmt.Sprintf("Failed to create model client: %v", err))
-	}
-}
-
 func RetrieveSimilarDocuments(ctx context.Context, query, email string) []VectorDBSearchResult {
 	rewrittenQuery, err := rewriteQuery(ctx, query)
 	if err != nil {
@@ -57,7 +41,13 @@ func RetrieveSimilarDocuments(ctx context.Context, query, email string) []Vector
 		return nil
 	}
 
-	embedder, err := embeddings.NewEmbedder(modelClient)
+	embedderClient, err := llmprovider.Embedder(llmprovider.TaskEmbed)
+	if err != nil {
+		slog.Error("failed to get embedder client", "err", err)
+		return nil
+	}
+
+	embedder, err := embeddings.NewEmbedder(embedderClient)
 	if err != nil {
 		slog.Error("failed to create embedder", "err", err)
 		return nil
@@ -71,7 +61,7 @@ func RetrieveSimilarDocuments(ctx context.Context, query, email string) []Vector
 
 	searchOption := milvusclient.NewSearchOption(collectionName, limit, []entity.Vector{entity.FloatVector(vector)}).
 		WithOutputFields("text").
-		WithFilter("user_email == '" + email + "'")
+		WithFilter("user_email == " + QuoteFilterValue(email))
 
 	resultSets, err := dao.MilvusClient.Search(ctx, searchOption)
 	if err != nil {
@@ -110,9 +100,19 @@ func rewriteQuery(ctx context.Context, query string) (string, error) {
 		return "", err
 	}
 
-	result, err := llms.GenerateFromSinglePrompt(ctx, modelClient, prompt)
+	llm, err := llmprovider.Model(llmprovider.TaskRewrite)
+	if err != nil {
+		return "", err
+	}
+
+	// 改写结果多次未通过校验时使用原始查询检索
+	result, err := structuredoutput.Generate(ctx, llm, prompt,
+		structuredoutput.WithFallback(func() *RewrittenQuery {
+			return &RewrittenQuery{Query: query}
+		}),
+	)
 	if err != nil {
 		return "", err
 	}
-	return result, nil
+	return result.Query, nil
 }
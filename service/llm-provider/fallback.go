@@ -0,0 +1,49 @@
+package llmprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// fallbackModel 按顺序调用模型链，前一个模型调用失败时使用下一个模型。
+// 流式输出中途失败时已推送的内容不会撤回，回退模型将重新输出完整内容
+type fallbackModel struct {
+	targets []target
+	models  []llms.Model
+}
+
+var _ llms.Model = &fallbackModel{}
+
+func (m *fallbackModel) GenerateContent(ctx context.Context, messages []llms.MessageContent,
+	options ...llms.CallOption) (*llms.ContentResponse, error) {
+	var errs []error
+	for i, model := range m.models {
+		resp, err := model.GenerateContent(ctx, messages, options...)
+		if err == nil {
+			return resp, nil
+		}
+
+		// 请求已取消或超时，无需回退
+		if ctx.Err() != nil {
+			return nil, err
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", m.targets[i], err))
+		if i < len(m.models)-1 {
+			slog.Warn("llm call failed, fallback to next model",
+				"model", m.targets[i].String(),
+				"next", m.targets[i+1].String(),
+				"err", err,
+			)
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (m *fallbackModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
@@ -0,0 +1,267 @@
+package llmprovider
+
+import (
+	"diabetes-agent-server/config"
+	"diabetes-agent-server/utils"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/tmc/langchaingo/embeddings"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/openai"
+)
+
+// 任务类型，每类任务对应一条按顺序回退的模型链
+const (
+	TaskChat      = "chat"
+	TaskSummarize = "summarize"
+	TaskRewrite   = "rewrite"
+	TaskVision    = "vision"
+	TaskEmbed     = "embed"
+	TaskReport    = "report"
+)
+
+const (
+	// 未配置 llm.providers 时由 model 配置生成的服务名
+	defaultProviderName = "default"
+
+	// 服务的默认请求超时时间，需覆盖 Agent 对话的流式输出
+	defaultProviderTimeout = 300 * time.Second
+)
+
+// ErrUnknownModel 用户选择的模型未在配置中定义
+var ErrUnknownModel = errors.New("unknown model")
+
+// 未配置的任务使用的默认模型
+var defaultTaskModels = map[string]string{
+	TaskChat:      "deepseek-v3.1",
+	TaskSummarize: "deepseek-v3.1",
+	TaskRewrite:   "qwen-plus",
+	TaskVision:    "qwen3-vl-flash",
+	TaskEmbed:     "text-embedding-v4",
+	TaskReport:    "deepseek-v3.1",
+}
+
+type provider struct {
+	config     config.LLMProviderConfig
+	httpClient *http.Client
+}
+
+// 模型别名解析后的调用目标
+type target struct {
+	provider string
+	model    string
+}
+
+func (t target) String() string {
+	return t.provider + "/" + t.model
+}
+
+type registry struct {
+	providers map[string]*provider
+
+	// 第一个服务，未通过别名引用的模型名使用该服务
+	defaultProvider string
+
+	aliases map[string]config.LLMModelConfig
+	tasks   map[string][]string
+
+	// 用户可选择的模型，包括别名、别名对应的模型名及任务链中的模型
+	allowed map[string]bool
+
+	// 通过别名配置了单价的模型
+	prices map[target]price
+
+	mu sync.Mutex
+
+	// 按服务和模型缓存的客户端，所有调用方共享
+	clients map[target]*openai.LLM
+}
+
+var defaultRegistry *registry
+
+// Init 按配置创建模型服务注册表，需在加载配置后调用
+func Init() {
+	var err error
+	defaultRegistry, err = newRegistry()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to init llm provider registry: %v", err))
+	}
+}
+
+func newRegistry() (*registry, error) {
+	cfg := config.Cfg.LLM
+	providerConfigs := cfg.Providers
+	if len(providerConfigs) == 0 {
+		providerConfigs = []config.LLMProviderConfig{{
+			Name:    defaultProviderName,
+			BaseURL: config.Cfg.Model.BaseURL,
+			APIKey:  config.Cfg.Model.APIKey,
+		}}
+	}
+
+	r := &registry{
+		providers:       make(map[string]*provider),
+		defaultProvider: providerConfigs[0].Name,
+		aliases:         make(map[string]config.LLMModelConfig),
+		tasks:           make(map[string][]string),
+		allowed:         make(map[string]bool),
+		prices:          make(map[target]price),
+		clients:         make(map[target]*openai.LLM),
+	}
+
+	for _, pc := range providerConfigs {
+		if pc.Name == "" {
+			return nil, fmt.Errorf("provider name is required")
+		}
+		if _, ok := r.providers[pc.Name]; ok {
+			return nil, fmt.Errorf("duplicate provider: %s", pc.Name)
+		}
+
+		timeout := defaultProviderTimeout
+		if pc.Timeout > 0 {
+			timeout = time.Duration(pc.Timeout) * time.Second
+		}
+		r.providers[pc.Name] = &provider{
+			config:     pc,
+			httpClient: utils.NewHTTPClient(utils.WithTimeout(timeout)),
+		}
+	}
+
+	for alias, mc := range cfg.Models {
+		if _, ok := r.providers[mc.Provider]; !ok {
+			return nil, fmt.Errorf("model %s references unknown provider: %s", alias, mc.Provider)
+		}
+		if mc.Model == "" {
+			return nil, fmt.Errorf("model name is required for alias: %s", alias)
+		}
+		r.aliases[alias] = mc
+		r.prices[target{provider: mc.Provider, model: mc.Model}] = price{
+			input:  mc.InputPrice,
+			output: mc.OutputPrice,
+		}
+	}
+
+	for task, chain := range cfg.Tasks {
+		if _, ok := defaultTaskModels[task]; !ok {
+			return nil, fmt.Errorf("unknown task: %s", task)
+		}
+		if len(chain) == 0 {
+			continue
+		}
+		r.tasks[task] = chain
+	}
+	for task, model := range defaultTaskModels {
+		if _, ok := r.tasks[task]; !ok {
+			r.tasks[task] = []string{model}
+		}
+	}
+
+	for alias, mc := range r.aliases {
+		r.allowed[alias] = true
+		r.allowed[mc.Model] = true
+	}
+	for _, chain := range r.tasks {
+		for _, name := range chain {
+			r.allowed[name] = true
+		}
+	}
+
+	return r, nil
+}
+
+// 解析模型别名，未定义的别名视为默认服务上的模型名
+func (r *registry) resolve(name string) target {
+	if mc, ok := r.aliases[name]; ok {
+		return target{provider: mc.Provider, model: mc.Model}
+	}
+	return target{provider: r.defaultProvider, model: name}
+}
+
+func (r *registry) client(t target) (*openai.LLM, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if client, ok := r.clients[t]; ok {
+		return client, nil
+	}
+
+	p := r.providers[t.provider]
+	client, err := openai.New(
+		openai.WithModel(t.model),
+		openai.WithEmbeddingModel(t.model),
+		openai.WithToken(p.config.APIKey),
+		openai.WithBaseURL(p.config.BaseURL),
+		openai.WithHTTPClient(p.httpClient),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create llm client for %s: %w", t, err)
+	}
+
+	r.clients[t] = client
+	return client, nil
+}
+
+func (r *registry) model(task string, names []string) (llms.Model, error) {
+	var chain fallbackModel
+	for _, name := range names {
+		t := r.resolve(name)
+		if slices.Contains(chain.targets, t) {
+			continue
+		}
+
+		client, err := r.client(t)
+		if err != nil {
+			return nil, err
+		}
+		chain.targets = append(chain.targets, t)
+		chain.models = append(chain.models, &usageModel{
+			task:   task,
+			target: t,
+			price:  r.prices[t],
+			model:  client,
+		})
+	}
+
+	if len(chain.models) == 1 {
+		return chain.models[0], nil
+	}
+	return &chain, nil
+}
+
+// Model 获取任务对应的模型，配置了多个模型时调用失败按顺序回退
+func Model(task string) (llms.Model, error) {
+	chain, ok := defaultRegistry.tasks[task]
+	if !ok {
+		return nil, fmt.Errorf("unknown task: %s", task)
+	}
+	return defaultRegistry.model(task, chain)
+}
+
+// ChatModel 获取 Agent 对话使用的模型，优先使用用户选择的模型，失败时回退到 chat 任务配置的模型。
+// 用户只能选择配置中定义的模型，否则返回 ErrUnknownModel，避免任意模型名创建的客户端无限缓存
+func ChatModel(preferred string) (llms.Model, error) {
+	chain := defaultRegistry.tasks[TaskChat]
+	if preferred != "" {
+		if !defaultRegistry.allowed[preferred] {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownModel, preferred)
+		}
+		chain = append([]string{preferred}, chain...)
+	}
+	return defaultRegistry.model(TaskChat, chain)
+}
+
+// Embedder 获取任务对应的向量化模型。
+// 不同模型的向量空间不一致，因此仅使用链中第一个模型，不做回退；
+// 向量化接口不返回 token 用量，不计入用量统计
+func Embedder(task string) (embeddings.EmbedderClient, error) {
+	chain, ok := defaultRegistry.tasks[task]
+	if !ok {
+		return nil, fmt.Errorf("unknown task: %s", task)
+	}
+	return defaultRegistry.client(defaultRegistry.resolve(chain[0]))
+}
@@ -0,0 +1,107 @@
+package llmprovider
+
+import (
+	"context"
+	"diabetes-agent-server/dao"
+	"diabetes-agent-server/model"
+	"log/slog"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// 模型单价(元/百万 tokens)
+type price struct {
+	input  float64
+	output float64
+}
+
+type usageScopeKey struct{}
+
+// 调用 LLM 的用户和会话
+type usageScope struct {
+	email     string
+	sessionID string
+}
+
+// WithUsageScope 在上下文中记录发起 LLM 调用的用户和会话，用于用量统计
+func WithUsageScope(ctx context.Context, email, sessionID string) context.Context {
+	return context.WithValue(ctx, usageScopeKey{}, usageScope{
+		email:     email,
+		sessionID: sessionID,
+	})
+}
+
+// usageModel 记录模型调用成功后返回的 token 用量
+type usageModel struct {
+	task   string
+	target target
+	price  price
+	model  llms.Model
+}
+
+var _ llms.Model = &usageModel{}
+
+func (m *usageModel) GenerateContent(ctx context.Context, messages []llms.MessageContent,
+	options ...llms.CallOption) (*llms.ContentResponse, error) {
+	resp, err := m.model.GenerateContent(ctx, messages, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	m.recordUsage(ctx, resp)
+	return resp, nil
+}
+
+func (m *usageModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+// 用量记录失败不影响调用结果
+func (m *usageModel) recordUsage(ctx context.Context, resp *llms.ContentResponse) {
+	if len(resp.Choices) == 0 {
+		return
+	}
+
+	// 多个候选结果的 GenerationInfo 均为整个请求的用量，取第一个即可
+	info := resp.Choices[0].GenerationInfo
+	promptTokens := intFromInfo(info, "PromptTokens")
+	completionTokens := intFromInfo(info, "CompletionTokens")
+	totalTokens := intFromInfo(info, "TotalTokens")
+	if totalTokens == 0 {
+		totalTokens = promptTokens + completionTokens
+	}
+
+	scope, _ := ctx.Value(usageScopeKey{}).(usageScope)
+	usage := &model.LLMUsage{
+		UserEmail:        scope.email,
+		SessionID:        scope.sessionID,
+		Task:             m.task,
+		Provider:         m.target.provider,
+		Model:            m.target.model,
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      totalTokens,
+		Cost: (float64(promptTokens)*m.price.input +
+			float64(completionTokens)*m.price.output) / 1e6,
+	}
+	if err := dao.CreateLLMUsage(usage); err != nil {
+		slog.Error("Failed to record llm usage",
+			"task", m.task,
+			"model", m.target.String(),
+			"err", err,
+		)
+	}
+}
+
+func intFromInfo(info map[string]any, key string) int {
+	switch v := info[key].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
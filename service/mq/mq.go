@@ -3,7 +3,13 @@ package mq
 import (
 	"context"
 	"diabetes-agent-server/config"
+	"diabetes-agent-server/constants"
+	accountdeletion "diabetes-agent-server/service/account-deletion"
+	cgmimport "diabetes-agent-server/service/cgm-import"
 	"diabetes-agent-server/service/chat"
+	dataexport "diabetes-agent-server/service/data-export"
+	glucosealert "diabetes-agent-server/service/glucose-alert"
+	healthreport "diabetes-agent-server/service/health-weekly-report"
 	"diabetes-agent-server/service/knowledge-base/etl"
 	"diabetes-agent-server/service/summarization"
 	"encoding/json"
@@ -27,9 +33,23 @@ const (
 	TagCompressContext     = "tag_compress_context"
 	TagDeleteUploadedFiles = "tag_delete_uploaded_files"
 
+	TopicBloodGlucose = "topic_blood_glucose"
+	TagCGMImport      = "tag_cgm_import"
+	TagGlucoseAlert   = "tag_glucose_alert"
+
+	TopicHealthReport = "topic_health_report"
+	TagGenerateReport = "tag_generate_report"
+	TagReportJob      = "tag_report_job"
+
+	TopicAccount     = "topic_account"
+	TagDataExport    = "tag_data_export"
+	TagDeleteAccount = "tag_delete_account"
+
 	consumerGroupKnowledgeBase = "cg_knowledge_base"
 	consumerGroupAgentChat     = "cg_agent_chat"
-	maxReconsumeTimes          = 5
+	consumerGroupBloodGlucose  = "cg_blood_glucose"
+	consumerGroupHealthReport  = "cg_health_report"
+	consumerGroupAccount       = "cg_account"
 	consumeGoroutineNums       = 10
 
 	sendMessageAttempts = 3
@@ -44,9 +64,19 @@ var (
 
 	// Agent 聊天业务消费者
 	consumerAgentChat rocketmq.PushConsumer
+
+	// 血糖业务消费者
+	consumerBloodGlucose rocketmq.PushConsumer
+
+	// 健康报告业务消费者
+	consumerHealthReport rocketmq.PushConsumer
+
+	// 账号业务消费者
+	consumerAccount rocketmq.PushConsumer
 )
 
-func init() {
+// 创建生产者和消费者，并为各消费者绑定消息处理函数
+func setupClients() {
 	// 设置 RocketMQ 客户端（使用 rlog）的日志级别
 	rlog.SetLogLevel("error")
 
@@ -63,7 +93,7 @@ func init() {
 		c.WithGroupName(consumerGroupKnowledgeBase),
 		c.WithConsumerModel(c.Clustering),
 		c.WithConsumeFromWhere(c.ConsumeFromLastOffset),
-		c.WithMaxReconsumeTimes(maxReconsumeTimes),
+		c.WithMaxReconsumeTimes(constants.MQMaxReconsumeTimes),
 		c.WithConsumeGoroutineNums(consumeGoroutineNums),
 	)
 	if err != nil {
@@ -75,13 +105,49 @@ func init() {
 		c.WithGroupName(consumerGroupAgentChat),
 		c.WithConsumerModel(c.Clustering),
 		c.WithConsumeFromWhere(c.ConsumeFromLastOffset),
-		c.WithMaxReconsumeTimes(maxReconsumeTimes),
+		c.WithMaxReconsumeTimes(constants.MQMaxReconsumeTimes),
 		c.WithConsumeGoroutineNums(consumeGoroutineNums),
 	)
 	if err != nil {
 		panic(fmt.Sprintf("Failed to create agent context consumer: %v", err))
 	}
 
+	consumerBloodGlucose, err = rocketmq.NewPushConsumer(
+		c.WithNameServer(config.Cfg.MQ.NameServer),
+		c.WithGroupName(consumerGroupBloodGlucose),
+		c.WithConsumerModel(c.Clustering),
+		c.WithConsumeFromWhere(c.ConsumeFromLastOffset),
+		c.WithMaxReconsumeTimes(constants.MQMaxReconsumeTimes),
+		c.WithConsumeGoroutineNums(consumeGoroutineNums),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create blood glucose consumer: %v", err))
+	}
+
+	consumerHealthReport, err = rocketmq.NewPushConsumer(
+		c.WithNameServer(config.Cfg.MQ.NameServer),
+		c.WithGroupName(consumerGroupHealthReport),
+		c.WithConsumerModel(c.Clustering),
+		c.WithConsumeFromWhere(c.ConsumeFromLastOffset),
+		c.WithMaxReconsumeTimes(constants.MQMaxReconsumeTimes),
+		c.WithConsumeGoroutineNums(consumeGoroutineNums),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create health report consumer: %v", err))
+	}
+
+	consumerAccount, err = rocketmq.NewPushConsumer(
+		c.WithNameServer(config.Cfg.MQ.NameServer),
+		c.WithGroupName(consumerGroupAccount),
+		c.WithConsumerModel(c.Clustering),
+		c.WithConsumeFromWhere(c.ConsumeFromLastOffset),
+		c.WithMaxReconsumeTimes(constants.MQMaxReconsumeTimes),
+		c.WithConsumeGoroutineNums(consumeGoroutineNums),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create account consumer: %v", err))
+	}
+
 	knowledgeBaseDispatcher := NewMessageDispatcher()
 	knowledgeBaseDispatcher.Register(TopicKnowledgeBase, TagETL, etl.HandleETLMessage)
 	knowledgeBaseDispatcher.Register(TopicKnowledgeBase, TagDelete, etl.HandleDeleteMessage)
@@ -97,9 +163,36 @@ func init() {
 	if err := agentChatDispatcher.Bind(consumerAgentChat); err != nil {
 		panic(fmt.Sprintf("Failed to bind dispatcher to agent chat consumer: %v", err))
 	}
+
+	bloodGlucoseDispatcher := NewMessageDispatcher()
+	bloodGlucoseDispatcher.Register(TopicBloodGlucose, TagCGMImport, cgmimport.NewImportHandler(SendGlucoseAlertMessage))
+	bloodGlucoseDispatcher.Register(TopicBloodGlucose, TagGlucoseAlert, glucosealert.HandleAlertMessage)
+
+	if err := bloodGlucoseDispatcher.Bind(consumerBloodGlucose); err != nil {
+		panic(fmt.Sprintf("Failed to bind dispatcher to blood glucose consumer: %v", err))
+	}
+
+	healthReportDispatcher := NewMessageDispatcher()
+	healthReportDispatcher.Register(TopicHealthReport, TagGenerateReport, healthreport.HandleGenerateReportMessage)
+	healthReportDispatcher.Register(TopicHealthReport, TagReportJob, healthreport.HandleReportJobMessage)
+
+	if err := healthReportDispatcher.Bind(consumerHealthReport); err != nil {
+		panic(fmt.Sprintf("Failed to bind dispatcher to health report consumer: %v", err))
+	}
+
+	accountDispatcher := NewMessageDispatcher()
+	accountDispatcher.Register(TopicAccount, TagDataExport, dataexport.HandleExportMessage)
+	accountDispatcher.Register(TopicAccount, TagDeleteAccount, accountdeletion.HandleDeletionMessage)
+
+	if err := accountDispatcher.Bind(consumerAccount); err != nil {
+		panic(fmt.Sprintf("Failed to bind dispatcher to account consumer: %v", err))
+	}
 }
 
+// Run 创建并启动生产者和消费者，需在加载配置后调用
 func Run() error {
+	setupClients()
+
 	if err := producerInstance.Start(); err != nil {
 		return fmt.Errorf("failed to start producer: %v", err)
 	}
@@ -109,6 +202,15 @@ func Run() error {
 	if err := consumerAgentChat.Start(); err != nil {
 		return fmt.Errorf("failed to start agent chat consumer: %v", err)
 	}
+	if err := consumerBloodGlucose.Start(); err != nil {
+		return fmt.Errorf("failed to start blood glucose consumer: %v", err)
+	}
+	if err := consumerHealthReport.Start(); err != nil {
+		return fmt.Errorf("failed to start health report consumer: %v", err)
+	}
+	if err := consumerAccount.Start(); err != nil {
+		return fmt.Errorf("failed to start account consumer: %v", err)
+	}
 	return nil
 }
 
@@ -155,4 +257,22 @@ func Shutdown() {
 	if consumerAgentChat != nil {
 		consumerAgentChat.Shutdown()
 	}
+	if consumerBloodGlucose != nil {
+		consumerBloodGlucose.Shutdown()
+	}
+	if consumerHealthReport != nil {
+		consumerHealthReport.Shutdown()
+	}
+	if consumerAccount != nil {
+		consumerAccount.Shutdown()
+	}
+}
+
+// SendGlucoseAlertMessage 为新增的血糖记录投递提醒评估任务
+func SendGlucoseAlertMessage(ctx context.Context, msg glucosealert.AlertMessage) error {
+	return SendMessage(ctx, &Message{
+		Topic:   TopicBloodGlucose,
+		Tag:     TagGlucoseAlert,
+		Payload: msg,
+	})
 }
@@ -25,6 +25,8 @@ const (
 	OSSKeyPrefixKnowledgeBase      = "knowledge-base"
 	OSSKeyPrefixUpload             = "upload"
 	OSSKeyPrefixHealthWeeklyReport = "health-weekly-report"
+	OSSKeyPrefixCGMImport          = "cgm-import"
+	OSSKeyPrefixDataExport         = "data-export"
 
 	// STS 临时凭证的会话有效期（单位为秒）
 	roleSessionExpiration = 3600
@@ -33,11 +35,6 @@ const (
 	preSignedExpires = 15 * time.Minute
 )
 
-var (
-	bucketName = config.Cfg.OSS.BucketName
-	region     = config.Cfg.OSS.Region
-)
-
 // GeneratePolicyToken 应用以 RAM 用户身份扮演 RAM 角色获取 STS 临时凭证，前端使用该凭证访问 OSS
 func GeneratePolicyToken(req request.OSSAuthRequest) (*response.GetPolicyTokenResponse, error) {
 	cfg := new(credentials.Config).
@@ -67,9 +64,9 @@ func GeneratePolicyToken(req request.OSSAuthRequest) (*response.GetPolicyTokenRe
 	policyMap := map[string]any{
 		"expiration": expiration.Format("2006-01-02T15:04:05.000Z"),
 		"conditions": []any{
-			map[string]string{"bucket": bucketName},
+			map[string]string{"bucket": config.Cfg.OSS.BucketName},
 			map[string]string{"x-oss-signature-version": "OSS4-HMAC-SHA256"},
-			map[string]string{"x-oss-credential": fmt.Sprintf("%v/%v/%v/%v/aliyun_v4_request", *cred.AccessKeyId, date, region, "oss")},
+			map[string]string{"x-oss-credential": fmt.Sprintf("%v/%v/%v/%v/aliyun_v4_request", *cred.AccessKeyId, date, config.Cfg.OSS.Region, "oss")},
 			map[string]string{"x-oss-date": utcTime.Format("20060102T150405Z")},
 			map[string]string{"x-oss-security-token": *cred.SecurityToken},
 		},
@@ -93,10 +90,10 @@ func GeneratePolicyToken(req request.OSSAuthRequest) (*response.GetPolicyTokenRe
 		Policy:           stringToSign,
 		SecurityToken:    *cred.SecurityToken,
 		SignatureVersion: "OSS4-HMAC-SHA256",
-		Credential:       fmt.Sprintf("%v/%v/%v/%v/aliyun_v4_request", *cred.AccessKeyId, date, region, "oss"),
+		Credential:       fmt.Sprintf("%v/%v/%v/%v/aliyun_v4_request", *cred.AccessKeyId, date, config.Cfg.OSS.Region, "oss"),
 		Date:             utcTime.UTC().Format("20060102T150405Z"),
 		Signature:        generatePolicyTokenSignature(stringToSign, cred, date),
-		Host:             fmt.Sprintf("https://%s.oss-%s.aliyuncs.com", bucketName, region),
+		Host:             fmt.Sprintf("https://%s.oss-%s.aliyuncs.com", config.Cfg.OSS.BucketName, config.Cfg.OSS.Region),
 		Key:              key,
 	}
 
@@ -115,7 +112,7 @@ func generatePolicyTokenSignature(stringToSign string, cred *credentials.Credent
 	h1Key := h1.Sum(nil)
 
 	h2 := hmac.New(hmacHash, h1Key)
-	io.WriteString(h2, region)
+	io.WriteString(h2, config.Cfg.OSS.Region)
 	h2Key := h2.Sum(nil)
 
 	h3 := hmac.New(hmacHash, h2Key)
@@ -148,6 +145,14 @@ func GenerateKey(req request.OSSAuthRequest) (string, error) {
 	case OSSKeyPrefixHealthWeeklyReport:
 		return fmt.Sprintf("%s/%s/%s", OSSKeyPrefixHealthWeeklyReport, req.Email, req.FileName), nil
 
+	// CGM 导出文件对象路径格式：cgm-import/{email}/{fileName}
+	case OSSKeyPrefixCGMImport:
+		return fmt.Sprintf("%s/%s/%s", OSSKeyPrefixCGMImport, req.Email, req.FileName), nil
+
+	// 账号数据导出文件对象路径格式：data-export/{email}/{fileName}
+	case OSSKeyPrefixDataExport:
+		return fmt.Sprintf("%s/%s/%s", OSSKeyPrefixDataExport, req.Email, req.FileName), nil
+
 	default:
 		return "", fmt.Errorf("invalid namespace: %v", req.Namespace)
 	}
@@ -178,15 +183,87 @@ func GeneratePresignedURL(req request.OSSAuthRequest) (string, error) {
 	}
 
 	getObjectRequest := &oss.GetObjectRequest{
-		Bucket: oss.Ptr(bucketName),
+		Bucket: oss.Ptr(config.Cfg.OSS.BucketName),
 		Key:    oss.Ptr(key),
 	}
 
 	ctx := context.Background()
-	result, err := client.Presign(ctx, getObjectRequest, oss.PresignExpires(preSignedExpires))
+	expires := preSignedExpires
+	if req.Expires > 0 {
+		expires = req.Expires
+	}
+
+	result, err := client.Presign(ctx, getObjectRequest, oss.PresignExpires(expires))
 	if err != nil {
 		return "", fmt.Errorf("failed to get object presign %v", err)
 	}
 
 	return result.URL, nil
 }
+
+// DeleteObjectsByPrefix 分页列出并删除前缀下的所有对象，返回删除的对象数
+func DeleteObjectsByPrefix(ctx context.Context, prefix string) (int, error) {
+	client := newClient()
+	paginator := client.NewListObjectsV2Paginator(&oss.ListObjectsV2Request{
+		Bucket: oss.Ptr(config.Cfg.OSS.BucketName),
+		Prefix: oss.Ptr(prefix),
+	})
+
+	deleted := 0
+	for paginator.HasNext() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to list objects: %v", err)
+		}
+		if len(page.Contents) == 0 {
+			continue
+		}
+
+		objects := make([]oss.DeleteObject, 0, len(page.Contents))
+		for _, object := range page.Contents {
+			objects = append(objects, oss.DeleteObject{
+				Key: object.Key,
+			})
+		}
+
+		_, err = client.DeleteMultipleObjects(ctx, &oss.DeleteMultipleObjectsRequest{
+			Bucket:  oss.Ptr(config.Cfg.OSS.BucketName),
+			Objects: objects,
+		})
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete objects: %v", err)
+		}
+		deleted += len(objects)
+	}
+
+	return deleted, nil
+}
+
+// CountObjectsByPrefix 统计前缀下的对象数
+func CountObjectsByPrefix(ctx context.Context, prefix string) (int, error) {
+	paginator := newClient().NewListObjectsV2Paginator(&oss.ListObjectsV2Request{
+		Bucket: oss.Ptr(config.Cfg.OSS.BucketName),
+		Prefix: oss.Ptr(prefix),
+	})
+
+	count := 0
+	for paginator.HasNext() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return count, fmt.Errorf("failed to list objects: %v", err)
+		}
+		count += len(page.Contents)
+	}
+	return count, nil
+}
+
+func newClient() *oss.Client {
+	cfg := oss.NewConfig().
+		WithCredentialsProvider(osscredentials.NewStaticCredentialsProvider(
+			config.Cfg.OSS.AccessKeyID,
+			config.Cfg.OSS.AccessKeySecret,
+		)).
+		WithRegion(config.Cfg.OSS.Region).
+		WithHttpClient(utils.GlobalHTTPClient)
+	return oss.NewClient(cfg)
+}
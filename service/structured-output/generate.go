@@ -0,0 +1,159 @@
+package structuredoutput
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/prompts"
+)
+
+// 默认的修复重试次数，不含首次调用
+const defaultMaxRetries = 2
+
+//go:embed prompts/repair.txt
+var repairPrompt string
+
+// ErrValidation LLM 输出在重试后仍未通过校验
+var ErrValidation = errors.New("llm output failed schema validation")
+
+type options[T any] struct {
+	maxRetries  int
+	fallback    func() *T
+	callOptions []llms.CallOption
+}
+
+type Option[T any] func(*options[T])
+
+// WithMaxRetries 设置校验失败后的最大修复重试次数
+func WithMaxRetries[T any](n int) Option[T] {
+	return func(o *options[T]) {
+		o.maxRetries = n
+	}
+}
+
+// WithFallback 设置重试后仍未通过校验时的兜底结果，通常为基于规则生成的确定性结果
+func WithFallback[T any](fallback func() *T) Option[T] {
+	return func(o *options[T]) {
+		o.fallback = fallback
+	}
+}
+
+// WithCallOptions 设置调用 LLM 时的参数
+func WithCallOptions[T any](callOptions ...llms.CallOption) Option[T] {
+	return func(o *options[T]) {
+		o.callOptions = callOptions
+	}
+}
+
+// Generate 调用 LLM 生成结构化输出并按 T 推导的 Schema 校验。
+// 校验失败时将错误反馈给 LLM 重新生成，超过重试次数后返回兜底结果，未设置兜底时返回 ErrValidation。
+// LLM 调用失败时直接返回错误
+func Generate[T any](ctx context.Context, llm llms.Model, prompt string, opts ...Option[T]) (*T, error) {
+	o := &options[T]{maxRetries: defaultMaxRetries}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var zero T
+	schema := SchemaOf(zero)
+	messages := []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeHuman, prompt),
+	}
+
+	var lastErrs []string
+	for attempt := 0; attempt <= o.maxRetries; attempt++ {
+		// 调用失败不使用兜底结果，由调用方决定是否重试
+		resp, err := llm.GenerateContent(ctx, messages, o.callOptions...)
+		if err != nil {
+			return nil, fmt.Errorf("error calling llm: %w", err)
+		}
+		if len(resp.Choices) == 0 {
+			return nil, errors.New("empty response from model")
+		}
+
+		content := resp.Choices[0].Content
+		result, errs := parse[T](schema, content)
+		if len(errs) == 0 {
+			return result, nil
+		}
+
+		slog.Warn("llm output failed schema validation",
+			"attempt", attempt+1,
+			"errors", errs,
+		)
+		lastErrs = errs
+
+		repair, err := buildRepairPrompt(schema, errs)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages,
+			llms.TextParts(llms.ChatMessageTypeAI, content),
+			llms.TextParts(llms.ChatMessageTypeHuman, repair),
+		)
+	}
+
+	return fallbackOrError(o, fmt.Errorf("%w: %s", ErrValidation, strings.Join(lastErrs, "; ")))
+}
+
+func parse[T any](schema *Schema, content string) (*T, []string) {
+	data := []byte(ExtractJSON(content))
+	if errs := schema.Validate(data); len(errs) > 0 {
+		return nil, errs
+	}
+
+	var result T
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, []string{err.Error()}
+	}
+	return &result, nil
+}
+
+// ExtractJSON 从 LLM 输出中提取 JSON，去除 Markdown 代码块及前后的说明文字
+func ExtractJSON(content string) string {
+	content = strings.TrimSpace(content)
+	if i := strings.Index(content, "```"); i >= 0 {
+		content = content[i+3:]
+		content = strings.TrimPrefix(content, "json")
+		if j := strings.LastIndex(content, "```"); j >= 0 {
+			content = content[:j]
+		}
+	}
+
+	start := strings.IndexAny(content, "{[")
+	if start < 0 {
+		return strings.TrimSpace(content)
+	}
+	closing := "}"
+	if content[start] == '[' {
+		closing = "]"
+	}
+	end := strings.LastIndex(content, closing)
+	if end < start {
+		return strings.TrimSpace(content[start:])
+	}
+	return content[start : end+1]
+}
+
+func buildRepairPrompt(schema *Schema, errs []string) (string, error) {
+	template := prompts.NewPromptTemplate(repairPrompt, []string{"errors", "schema"})
+	return template.Format(map[string]any{
+		"errors": "- " + strings.Join(errs, "\n- "),
+		"schema": schema.String(),
+	})
+}
+
+func fallbackOrError[T any](o *options[T], err error) (*T, error) {
+	if o.fallback == nil {
+		return nil, err
+	}
+
+	slog.Warn("use fallback result for llm structured output", "err", err)
+	return o.fallback(), nil
+}
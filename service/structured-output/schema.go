@@ -0,0 +1,220 @@
+package structuredoutput
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema 由 Go 结构体推导的 JSON Schema 子集，用于提示 LLM 输出格式和校验输出
+type Schema struct {
+	Type       string             `json:"type"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	Enum       []string           `json:"enum,omitempty"`
+
+	// 字符串的最小/最大字符数，数组的最小/最大元素数
+	MinLength *int `json:"minLength,omitempty"`
+	MaxLength *int `json:"maxLength,omitempty"`
+	MinItems  *int `json:"minItems,omitempty"`
+	MaxItems  *int `json:"maxItems,omitempty"`
+}
+
+// SchemaOf 由结构体类型推导 Schema。
+// 字段名取自 json 标签，未标注 omitempty 的字段为必填；
+// 可通过 schema 标签声明约束，如 `schema:"min=3,max=5"`、`schema:"enum=low|high"`
+func SchemaOf(v any) *Schema {
+	return schemaOfType(reflect.TypeOf(v))
+}
+
+func (s *Schema) String() string {
+	bytes, _ := json.Marshal(s)
+	return string(bytes)
+}
+
+func schemaOfType(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		s := &Schema{
+			Type:       "object",
+			Properties: make(map[string]*Schema),
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			name, omitempty := parseJSONTag(field)
+			if name == "-" {
+				continue
+			}
+
+			fieldSchema := schemaOfType(field.Type)
+			applyConstraints(fieldSchema, field.Tag.Get("schema"))
+			s.Properties[name] = fieldSchema
+			if !omitempty {
+				s.Required = append(s.Required, name)
+			}
+		}
+		return s
+	case reflect.Slice, reflect.Array:
+		return &Schema{
+			Type:  "array",
+			Items: schemaOfType(t.Elem()),
+		}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	default:
+		return &Schema{}
+	}
+}
+
+func parseJSONTag(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "" {
+		return field.Name, false
+	}
+
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			return name, true
+		}
+	}
+	return name, false
+}
+
+func applyConstraints(s *Schema, tag string) {
+	if tag == "" {
+		return
+	}
+
+	for _, item := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(item, "=")
+		switch key {
+		case "enum":
+			s.Enum = strings.Split(value, "|")
+		case "min", "max":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				continue
+			}
+			switch {
+			case s.Type == "array" && key == "min":
+				s.MinItems = &n
+			case s.Type == "array" && key == "max":
+				s.MaxItems = &n
+			case key == "min":
+				s.MinLength = &n
+			default:
+				s.MaxLength = &n
+			}
+		}
+	}
+}
+
+// Validate 校验 JSON 数据，返回带字段路径的校验错误，通过校验时返回 nil
+func (s *Schema) Validate(data []byte) []string {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return []string{fmt.Sprintf("无效的 JSON: %v", err)}
+	}
+
+	var errs []string
+	s.validate("$", value, &errs)
+	return errs
+}
+
+func (s *Schema) validate(path string, value any, errs *[]string) {
+	switch s.Type {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			*errs = append(*errs, fmt.Sprintf("%s: 应为对象", path))
+			return
+		}
+		for _, name := range s.Required {
+			if v, ok := obj[name]; !ok || v == nil {
+				*errs = append(*errs, fmt.Sprintf("%s.%s: 缺少必填字段", path, name))
+			}
+		}
+
+		// 按字段名排序，保证错误信息顺序稳定
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if v, ok := obj[name]; ok && v != nil {
+				s.Properties[name].validate(path+"."+name, v, errs)
+			}
+		}
+	case "array":
+		arr, ok := value.([]any)
+		if !ok {
+			*errs = append(*errs, fmt.Sprintf("%s: 应为数组", path))
+			return
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			*errs = append(*errs, fmt.Sprintf("%s: 至少需要 %d 项，实际 %d 项", path, *s.MinItems, len(arr)))
+		}
+		if s.MaxItems != nil && len(arr) > *s.MaxItems {
+			*errs = append(*errs, fmt.Sprintf("%s: 最多 %d 项，实际 %d 项", path, *s.MaxItems, len(arr)))
+		}
+		for i, item := range arr {
+			s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, errs)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			*errs = append(*errs, fmt.Sprintf("%s: 应为字符串", path))
+			return
+		}
+		length := utf8.RuneCountInString(strings.TrimSpace(str))
+		if s.MinLength != nil && length < *s.MinLength {
+			*errs = append(*errs, fmt.Sprintf("%s: 长度至少为 %d", path, *s.MinLength))
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			*errs = append(*errs, fmt.Sprintf("%s: 长度不能超过 %d", path, *s.MaxLength))
+		}
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+			*errs = append(*errs, fmt.Sprintf("%s: 取值应为 %s 之一", path, strings.Join(s.Enum, "/")))
+		}
+	case "integer", "number":
+		num, ok := value.(float64)
+		if !ok {
+			*errs = append(*errs, fmt.Sprintf("%s: 应为数字", path))
+			return
+		}
+		if s.Type == "integer" && num != float64(int64(num)) {
+			*errs = append(*errs, fmt.Sprintf("%s: 应为整数", path))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			*errs = append(*errs, fmt.Sprintf("%s: 应为布尔值", path))
+		}
+	}
+}
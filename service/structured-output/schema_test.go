@@ -0,0 +1,192 @@
+package structuredoutput
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testItem struct {
+	Name string `json:"name" schema:"min=2,max=4"`
+}
+
+type testOutput struct {
+	Summary  string     `json:"summary" schema:"min=2,max=10"`
+	Level    string     `json:"level" schema:"enum=low|high"`
+	Score    int        `json:"score"`
+	Ratio    float64    `json:"ratio,omitempty"`
+	Done     bool       `json:"done,omitempty"`
+	Items    []testItem `json:"items" schema:"min=1,max=2"`
+	Note     *string    `json:"note,omitempty"`
+	Ignored  string     `json:"-"`
+	internal string
+}
+
+func TestSchemaOf(t *testing.T) {
+	schema := SchemaOf(testOutput{})
+
+	if schema.Type != "object" {
+		t.Fatalf("type = %q, want object", schema.Type)
+	}
+	wantRequired := []string{"summary", "level", "score", "items"}
+	if !reflect.DeepEqual(schema.Required, wantRequired) {
+		t.Errorf("required = %v, want %v", schema.Required, wantRequired)
+	}
+	for _, name := range []string{"Ignored", "-", "internal"} {
+		if _, ok := schema.Properties[name]; ok {
+			t.Errorf("property %q should be skipped", name)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		field     string
+		wantType  string
+		wantEnum  []string
+		wantMin   *int
+		wantMax   *int
+		wantItems string
+	}{
+		{name: "string with length", field: "summary", wantType: "string", wantMin: intPtr(2), wantMax: intPtr(10)},
+		{name: "string enum", field: "level", wantType: "string", wantEnum: []string{"low", "high"}},
+		{name: "integer", field: "score", wantType: "integer"},
+		{name: "number", field: "ratio", wantType: "number"},
+		{name: "boolean", field: "done", wantType: "boolean"},
+		{name: "array with items", field: "items", wantType: "array", wantMin: intPtr(1), wantMax: intPtr(2), wantItems: "object"},
+		{name: "pointer", field: "note", wantType: "string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, ok := schema.Properties[tt.field]
+			if !ok {
+				t.Fatalf("missing property %q", tt.field)
+			}
+			if s.Type != tt.wantType {
+				t.Errorf("type = %q, want %q", s.Type, tt.wantType)
+			}
+			if !reflect.DeepEqual(s.Enum, tt.wantEnum) {
+				t.Errorf("enum = %v, want %v", s.Enum, tt.wantEnum)
+			}
+
+			gotMin, gotMax := s.MinLength, s.MaxLength
+			if s.Type == "array" {
+				gotMin, gotMax = s.MinItems, s.MaxItems
+				if s.Items == nil || s.Items.Type != tt.wantItems {
+					t.Errorf("items = %v, want type %q", s.Items, tt.wantItems)
+				}
+			}
+			if !reflect.DeepEqual(gotMin, tt.wantMin) {
+				t.Errorf("min = %v, want %v", deref(gotMin), deref(tt.wantMin))
+			}
+			if !reflect.DeepEqual(gotMax, tt.wantMax) {
+				t.Errorf("max = %v, want %v", deref(gotMax), deref(tt.wantMax))
+			}
+		})
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	schema := SchemaOf(testOutput{})
+
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "valid",
+			data: `{"summary":"血糖平稳","level":"low","score":3,"items":[{"name":"早餐"}]}`,
+		},
+		{
+			name: "invalid json",
+			data: `{"summary":`,
+			want: []string{"无效的 JSON: unexpected end of JSON input"},
+		},
+		{
+			name: "not an object",
+			data: `[]`,
+			want: []string{"$: 应为对象"},
+		},
+		{
+			name: "missing and null required fields",
+			data: `{"summary":null,"level":"low","items":[{"name":"早餐"}]}`,
+			want: []string{"$.summary: 缺少必填字段", "$.score: 缺少必填字段"},
+		},
+		{
+			name: "string length counts runes after trimming",
+			data: `{"summary":" 好 ","level":"low","score":1,"items":[{"name":"早餐午餐晚餐"}]}`,
+			want: []string{"$.items[0].name: 长度不能超过 4", "$.summary: 长度至少为 2"},
+		},
+		{
+			name: "enum",
+			data: `{"summary":"血糖平稳","level":"medium","score":1,"items":[{"name":"早餐"}]}`,
+			want: []string{"$.level: 取值应为 low/high 之一"},
+		},
+		{
+			name: "integer and number types",
+			data: `{"summary":"血糖平稳","level":"low","score":1.5,"ratio":"0.5","items":[{"name":"早餐"}]}`,
+			want: []string{"$.ratio: 应为数字", "$.score: 应为整数"},
+		},
+		{
+			name: "boolean type",
+			data: `{"summary":"血糖平稳","level":"low","score":1,"done":"yes","items":[{"name":"早餐"}]}`,
+			want: []string{"$.done: 应为布尔值"},
+		},
+		{
+			name: "too few items",
+			data: `{"summary":"血糖平稳","level":"low","score":1,"items":[]}`,
+			want: []string{"$.items: 至少需要 1 项，实际 0 项"},
+		},
+		{
+			name: "too many items",
+			data: `{"summary":"血糖平稳","level":"low","score":1,"items":[{"name":"早餐"},{"name":"午餐"},{"name":"晚餐"}]}`,
+			want: []string{"$.items: 最多 2 项，实际 3 项"},
+		},
+		{
+			name: "wrong array and string types",
+			data: `{"summary":1,"level":"low","score":1,"items":{}}`,
+			want: []string{"$.items: 应为数组", "$.summary: 应为字符串"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := schema.Validate([]byte(tt.data))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "plain object", content: `{"a":1}`, want: `{"a":1}`},
+		{name: "surrounding text", content: "结果如下：{\"a\":{\"b\":2}} 以上。", want: `{"a":{"b":2}}`},
+		{name: "json code block", content: "```json\n{\"a\":1}\n```", want: `{"a":1}`},
+		{name: "code block without language", content: "说明\n```\n[1,2]\n```\n", want: `[1,2]`},
+		{name: "array", content: `输出：[{"a":1},{"a":2}]`, want: `[{"a":1},{"a":2}]`},
+		{name: "unclosed object", content: `{"a":1`, want: `{"a":1`},
+		{name: "no json", content: "  无法生成  ", want: "无法生成"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractJSON(tt.content); got != tt.want {
+				t.Errorf("ExtractJSON() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func deref(n *int) any {
+	if n == nil {
+		return nil
+	}
+	return *n
+}
@@ -2,10 +2,9 @@ package summarization
 
 import (
 	"context"
-	"diabetes-agent-server/config"
 	"diabetes-agent-server/dao"
 	"diabetes-agent-server/model"
-	"diabetes-agent-server/utils"
+	llmprovider "diabetes-agent-server/service/llm-provider"
 	_ "embed"
 	"encoding/json"
 	"fmt"
@@ -14,13 +13,11 @@ import (
 
 	"github.com/apache/rocketmq-client-go/v2/primitive"
 	"github.com/tmc/langchaingo/llms"
-	"github.com/tmc/langchaingo/llms/openai"
 	"github.com/tmc/langchaingo/prompts"
 	"gorm.io/gorm"
 )
 
 const (
-	modelName       = "deepseek-v3.1"
 	updateBatchSize = 1
 
 	// 生成消息摘要的最小消息长度
@@ -59,7 +56,16 @@ func HandleSummarizationMessage(ctx context.Context, msg *primitive.MessageExt)
 			continue
 		}
 
-		summary, err := generateSummary(ctx, msg.Role, msg.Content)
+		email, err := dao.GetSessionUserEmail(msg.SessionID)
+		if err != nil {
+			slog.Error("Failed to get session user email",
+				"session_id", msg.SessionID,
+				"err", err,
+			)
+		}
+
+		summaryCtx := llmprovider.WithUsageScope(ctx, email, msg.SessionID)
+		summary, err := generateSummary(summaryCtx, msg.Role, msg.Content)
 		if err != nil {
 			slog.Error("Failed to summarize message",
 				"msg_id", msgID,
@@ -102,12 +108,7 @@ func generateSummary(ctx context.Context, role, content string) (string, error)
 		return "", fmt.Errorf("failed to format prompt: %v", err)
 	}
 
-	llm, err := openai.New(
-		openai.WithModel(modelName),
-		openai.WithToken(config.Cfg.Model.APIKey),
-		openai.WithBaseURL(config.Cfg.Model.BaseURL),
-		openai.WithHTTPClient(utils.GlobalHTTPClient),
-	)
+	llm, err := llmprovider.Model(llmprovider.TaskSummarize)
 	if err != nil {
 		return "", fmt.Errorf("failed to create llm client: %v", err)
 	}
@@ -36,7 +36,8 @@ type WSConnectionPool struct {
 
 var wsConnectionPool *WSConnectionPool
 
-func init() {
+// Init 创建语音识别服务的 WebSocket 连接池，需在加载配置后调用
+func Init() {
 	header := make(http.Header)
 	header.Add("Authorization", fmt.Sprintf("bearer %s", config.Cfg.Model.APIKey))
 	header.Add("X-DashScope-DataInspection", "enable")